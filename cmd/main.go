@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -14,7 +17,10 @@ import (
 	"github.com/perfect1337/forum-service/internal/config"
 	grpcDelivery "github.com/perfect1337/forum-service/internal/delivery/grpcserver"
 	delivery "github.com/perfect1337/forum-service/internal/delivery/http"
+	forumChatProto "github.com/perfect1337/forum-service/internal/proto/chat"
+	forumCommentProto "github.com/perfect1337/forum-service/internal/proto/comment"
 	forumPostProto "github.com/perfect1337/forum-service/internal/proto/post"
+	forumUserProto "github.com/perfect1337/forum-service/internal/proto/user"
 	"github.com/perfect1337/forum-service/internal/repository"
 	"github.com/perfect1337/forum-service/internal/usecase"
 	logg "github.com/perfect1337/logger"
@@ -22,7 +28,9 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
 )
 
 // @title Forum Service API
@@ -68,37 +76,123 @@ func main() {
 		log.Fatalf("failed to initialize repository: %v", err)
 	}
 
-	// Initialize use cases
-	postUC := usecase.NewPostUseCase(repo, repo)
-	commentUC := usecase.NewCommentUseCase(repo)
-	authUC := usecase.NewAuthUseCase(*repo, cfg)
-	chatUC := usecase.NewChatUseCase(repo, authUC)
-	userUC := usecase.NewUserUseCase(repo)
-
 	// Initialize gRPC connection to auth-service
 	authAddr := os.Getenv("AUTH_SERVICE_GRPC_ADDR")
 	if authAddr == "" {
 		authAddr = "localhost:50051"
 	}
 
-	authConn, err := grpc.DialContext(
-		ctx,
+	// The connection is made in the background and retried with backoff, so
+	// the forum starts and serves reads while auth-service is down
+	authBackoff := backoff.DefaultConfig
+	authBackoff.MaxDelay = cfg.AuthService.MaxBackoff
+	authConn, err := grpc.NewClient(
 		authAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-		grpc.WithTimeout(5*time.Second),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           authBackoff,
+			MinConnectTimeout: cfg.AuthService.ConnectTimeout,
+		}),
 	)
 	if err != nil {
-		log.Fatalf("failed to connect to auth service: %v", err)
+		log.Fatalf("invalid auth service address %q: %v", authAddr, err)
 	}
 	defer authConn.Close()
+	authConn.Connect()
+	userClient := repository.NewUserClientBreaker(forumUserProto.NewUserServiceClient(authConn), repository.BreakerOptions{
+		Failures: cfg.AuthService.BreakerFailures,
+		Cooldown: cfg.AuthService.BreakerCooldown,
+	})
+	expvar.Publish("auth_service_breaker", expvar.Func(func() interface{} { return userClient.State() }))
+
+	// Users come from the local users table or from auth-service
+	var users repository.UserRepository = repo
+	var remoteUsers *repository.RemoteUsers
+	if cfg.Users.Source == "auth" {
+		remoteUsers = repository.NewRemoteUsers(userClient, repository.RemoteUserOptions{
+			TTL:       cfg.Users.CacheTTL,
+			BatchSize: cfg.Users.BatchSize,
+		})
+		users = remoteUsers
+	}
+
+	// Initialize use cases
+	authUC := usecase.NewAuthUseCase(*repo, cfg)
+	var chatBroadcaster usecase.ChatBroadcaster = usecase.NewMemoryChatBroadcaster()
+	if cfg.Chat.Broadcaster == "postgres" {
+		pgBroadcaster, err := repository.NewPostgresChatBroadcaster(repo)
+		if err != nil {
+			log.Fatalf("failed to initialize chat broadcaster: %v", err)
+		}
+		pgBroadcaster.Start(ctx)
+		chatBroadcaster = pgBroadcaster
+	}
+	// New posts and comments go out through the chat broadcaster to /chat/events
+	postUC := usecase.NewPostUseCaseWithEvents(repo, users, chatBroadcaster)
+	commentUC := usecase.NewCommentUseCaseWithEvents(repo, chatBroadcaster)
+	chatUC := usecase.NewChatUseCaseWithOptions(repo, authUC, usecase.ChatOptions{
+		WebSocket: usecase.WebSocketSettings{
+			ReadLimit:           cfg.Chat.WebSocket.ReadLimit,
+			PongWait:            cfg.Chat.WebSocket.PongWait,
+			PingPeriod:          cfg.Chat.WebSocket.PingPeriod,
+			WriteWait:           cfg.Chat.WebSocket.WriteWait,
+			SendBuffer:          cfg.Chat.WebSocket.SendBuffer,
+			MaxConnections:      cfg.Chat.WebSocket.MaxConnections,
+			MaxAnonymousStreams: cfg.Chat.WebSocket.MaxAnonymousStreams,
+		},
+		Broadcaster: chatBroadcaster,
+		EditWindow:  cfg.Chat.EditWindow,
+		Users:       users,
+		Moderation:  repo,
+		Flood: usecase.FloodLimit{
+			Messages: cfg.Chat.Flood.Messages,
+			Interval: cfg.Chat.Flood.Interval,
+		},
+	})
+	chatJanitor := usecase.NewChatJanitor(repo, usecase.ChatRetention{
+		Default: cfg.Chat.Retention,
+		Rooms:   cfg.Chat.RoomRetention,
+	})
+	chatJanitor.Start(ctx, cfg.Chat.CleanupInterval)
+	expvar.Publish("chat_janitor", expvar.Func(func() interface{} { return chatJanitor.Stats() }))
+	userUC := usecase.NewUserUseCase(users)
+	searchUC := usecase.NewSearchUseCase(repo)
+	trashUC := usecase.NewTrashUseCase(repo, users, cfg.Trash.Retention)
+	trashUC.StartPurgeRoutine(ctx, cfg.Trash.PurgeInterval)
+	categoryUC := usecase.NewCategoryUseCase(repo, users)
 
 	// Initialize gRPC server
-	grpcSrv := grpc.NewServer()
-	forumPostProto.RegisterPostServiceServer(
-		grpcSrv,
-		grpcDelivery.NewPostServer(postUC, authConn),
-	)
+	grpcSrv := grpc.NewServer(grpcDelivery.ServerOptions(grpcDelivery.InterceptorOptions{
+		Auth:           authUC,
+		Logger:         log,
+		DefaultTimeout: cfg.GRPC.Timeout,
+		MaxTimeout:     cfg.GRPC.MaxTimeout,
+	})...)
+	postServer := grpcDelivery.NewPostServerWithAuth(postUC, authUC, authConn)
+	postServer.UserClient = userClient
+	if remoteUsers != nil {
+		postServer = grpcDelivery.NewPostServerWithUsers(postUC, authUC, remoteUsers)
+	}
+	forumPostProto.RegisterPostServiceServer(grpcSrv, postServer)
+	forumCommentProto.RegisterCommentServiceServer(grpcSrv, grpcDelivery.NewCommentServer(commentUC, authUC))
+	forumChatProto.RegisterChatServiceServer(grpcSrv, grpcDelivery.NewChatServer(chatUC, authUC))
+
+	// Health checks and reflection for orchestrators and grpcurl
+	// auth-service is optional: while it is down the service runs degraded
+	authConnCheck := grpcDelivery.ConnHealthCheck(authConn)
+	healthSrv := grpcDelivery.NewHealthWithOptional(map[string]grpcDelivery.HealthCheck{
+		"postgres": repo.Ping,
+	}, map[string]grpcDelivery.HealthCheck{
+		"auth-service": func(ctx context.Context) error {
+			if err := userClient.Check(ctx); err != nil {
+				return err
+			}
+			return authConnCheck(ctx)
+		},
+	}, cfg.GRPC.HealthTimeout, log)
+	healthSrv.Register(grpcSrv)
+	reflection.Register(grpcSrv)
+	healthSrv.Start(ctx, cfg.GRPC.HealthInterval)
 
 	// Start gRPC server in goroutine
 	go func() {
@@ -115,7 +209,7 @@ func main() {
 	// Initialize HTTP server
 	router := gin.New()
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"},
+		AllowOrigins:     cfg.Server.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
@@ -126,12 +220,18 @@ func main() {
 	router.Use(gin.Recovery())
 	// Add Swagger route
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Same dependency state as grpc.health.v1
+	router.GET("/health", delivery.NewHealthHandler(healthSrv).Health)
 
 	// Initialize handlers
 	postHandler := delivery.NewPostHandler(postUC, commentUC, userUC)
-	commentHandler := delivery.NewCommentHandler(commentUC)
+	commentHandler := delivery.NewCommentHandlerWithUsers(commentUC, userUC)
 	authHandler := delivery.NewAuthHandler(authUC)
-	chatHandler := delivery.NewChatHandler(chatUC)
+	chatHandler := delivery.NewChatHandlerWithOrigins(chatUC, cfg.Server.AllowedOrigins)
+	searchHandler := delivery.NewSearchHandler(searchUC)
+	trashHandler := delivery.NewTrashHandler(trashUC)
+	categoryHandler := delivery.NewCategoryHandler(categoryUC)
+	chatModerationHandler := delivery.NewChatModerationHandler(chatUC)
 
 	// Setup routes
 
@@ -141,17 +241,52 @@ func main() {
 		authGroup.GET("/validate", authHandler.ValidateToken)
 	}
 
+	// Search routes
+	router.GET("/search", searchHandler.Search)
+
+	// Admin routes
+	admin := router.Group("/admin")
+	admin.Use(delivery.AuthMiddleware(cfg))
+	{
+		admin.GET("/trash", trashHandler.ListTrash)
+		admin.POST("/trash/:type/:id/restore", trashHandler.RestoreItem)
+		admin.DELETE("/trash/:type/:id", trashHandler.PurgeItem)
+		admin.POST("/categories", categoryHandler.CreateCategory)
+		admin.PUT("/categories/:id", categoryHandler.UpdateCategory)
+		admin.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+		admin.GET("/chat/sanctions", chatModerationHandler.ListSanctions)
+		admin.POST("/chat/sanctions", chatModerationHandler.Sanction)
+		admin.DELETE("/chat/sanctions/:user_id/:kind", chatModerationHandler.LiftSanction)
+		admin.PUT("/chat/rooms/:id/slow-mode", chatModerationHandler.SetSlowMode)
+	}
+
+	// Category and tag routes
+	router.GET("/categories", categoryHandler.GetCategories)
+	router.GET("/tags/:name/posts", postHandler.GetPostsByTag)
+
 	// Chat routes
 	chat := router.Group("/chat")
 	{
 		chat.GET("/messages", chatHandler.GetMessages)
 		chat.GET("/ws", chatHandler.HandleWebSocket)
+		chat.GET("/events", chatHandler.StreamEvents)
+		chat.GET("/rooms", chatHandler.GetRooms)
+		chat.GET("/rooms/:id/messages", chatHandler.GetRoomMessages)
 
 		// Protected chat routes
 		protected := chat.Group("")
 		protected.Use(delivery.AuthMiddleware(cfg))
 		{
 			protected.POST("/messages", chatHandler.SendMessage)
+			protected.PATCH("/messages/:id", chatHandler.EditMessage)
+			protected.DELETE("/messages/:id", chatHandler.DeleteMessage)
+			protected.POST("/ws/ticket", chatHandler.IssueWebSocketTicket)
+			protected.POST("/rooms", chatHandler.CreateRoom)
+			protected.POST("/rooms/:id/join", chatHandler.JoinRoom)
+			protected.GET("/dm", chatHandler.GetConversations)
+			protected.GET("/dm/:user_id/messages", chatHandler.GetDirectMessages)
+			protected.POST("/dm/:user_id/messages", chatHandler.SendDirectMessage)
+			protected.POST("/dm/:user_id/read", chatHandler.MarkConversationRead)
 		}
 	}
 
@@ -160,6 +295,9 @@ func main() {
 	{
 		posts.GET("", postHandler.GetAllPosts)
 		posts.GET("/:id", postHandler.GetPostByID)
+		posts.GET("/:id/revisions", postHandler.GetPostRevisions)
+		posts.GET("/:id/revisions/diff", postHandler.DiffPostRevisions)
+		posts.GET("/:id/revisions/:rev", postHandler.GetPostRevision)
 
 		// Protected routes
 		protected := posts.Group("")
@@ -168,6 +306,8 @@ func main() {
 			protected.POST("", postHandler.CreatePost)
 			protected.DELETE("/:id", postHandler.DeletePost)
 			protected.PUT("/:id", postHandler.UpdatePost)
+			protected.POST("/:id/vote", postHandler.VotePost)
+			protected.POST("/:id/revisions/:rev/restore", postHandler.RestorePostRevision)
 		}
 
 		// Comments routes
@@ -180,22 +320,61 @@ func main() {
 			protectedComments.Use(delivery.AuthMiddleware(cfg))
 			{
 				protectedComments.POST("", commentHandler.CreateComment)
+				protectedComments.PATCH("/:comment_id", commentHandler.EditComment)
 				protectedComments.DELETE("/:comment_id", commentHandler.DeleteComment)
+				protectedComments.POST("/:comment_id/vote", commentHandler.VoteComment)
 			}
 		}
 	}
 
 	// Start HTTP server in goroutine
+	httpSrv := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: router,
+	}
 	go func() {
-		if err := router.Run(":" + cfg.Server.Port); err != nil {
+		if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("failed to start HTTP server: %v", err)
 		}
 	}()
 
+	// Runtime metrics, including the chat janitor counters, stay off the
+	// public port
+	var debugSrv *http.Server
+	if cfg.Server.DebugAddr != "" {
+		debugMux := http.NewServeMux()
+		debugMux.Handle("/debug/vars", expvar.Handler())
+		debugSrv = &http.Server{Addr: cfg.Server.DebugAddr, Handler: debugMux}
+		go func() {
+			if err := debugSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("failed to start debug server: %v", err)
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+	log.Info("Shutting down...")
+	healthSrv.Shutdown()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer shutdownCancel()
+
+	// http.Server.Shutdown не ждёт захваченные WebSocket-соединения,
+	// поэтому чат закрываем отдельно.
+	if err := chatUC.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("chat connections did not close in time: %v", err)
+	}
+	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("failed to shut down HTTP server: %v", err)
+	}
+	if debugSrv != nil {
+		if err := debugSrv.Shutdown(shutdownCtx); err != nil {
+			log.Errorf("failed to shut down debug server: %v", err)
+		}
+	}
 
 	// Gracefully stop gRPC server
 	grpcSrv.GracefulStop()
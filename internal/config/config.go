@@ -19,6 +19,15 @@ type Config struct {
 	Postgres PostgresConfig // Теперь используем явный тип
 	Server   struct {
 		Port string
+		// ShutdownTimeout — сколько ждать активные запросы и WebSocket-соединения
+		// при остановке.
+		ShutdownTimeout time.Duration
+		// AllowedOrigins — CORS allow-list; по нему же проверяется Origin
+		// при открытии /chat/ws.
+		AllowedOrigins []string
+		// DebugAddr — внутренний адрес для /debug/vars, отдельный от
+		// публичного порта; пустой отключает метрики.
+		DebugAddr string
 	}
 	Auth struct {
 		AccessTokenDuration  time.Duration
@@ -37,7 +46,74 @@ type Config struct {
 	} `yaml:"logger"`
 	GRPC struct {
 		Port string `yaml:"port"`
+		// Timeout — срок unary-вызова, если клиент не задал свой; MaxTimeout
+		// ограничивает срок, который клиент может попросить.
+		Timeout    time.Duration `yaml:"timeout"`
+		MaxTimeout time.Duration `yaml:"max_timeout"`
+		// HealthInterval — как часто проверяются Postgres и auth-service для
+		// grpc.health.v1, HealthTimeout — срок одной проверки.
+		HealthInterval time.Duration `yaml:"health_interval"`
+		HealthTimeout  time.Duration `yaml:"health_timeout"`
 	} `yaml:"grpc"`
+	Trash struct {
+		// Retention — сколько удалённые посты и комментарии лежат в корзине
+		// до окончательного удаления; 0 отключает автоочистку.
+		Retention     time.Duration `yaml:"retention"`
+		PurgeInterval time.Duration `yaml:"purge_interval"`
+	} `yaml:"trash"`
+	AuthService struct {
+		// ConnectTimeout — срок одной попытки соединения; между попытками
+		// пауза растёт до MaxBackoff. Сервис стартует и без auth-service.
+		ConnectTimeout time.Duration `yaml:"connect_timeout"`
+		MaxBackoff     time.Duration `yaml:"max_backoff"`
+		// BreakerFailures — сколько вызовов подряд должны упасть, чтобы
+		// перестать звать auth-service на BreakerCooldown.
+		BreakerFailures int           `yaml:"breaker_failures"`
+		BreakerCooldown time.Duration `yaml:"breaker_cooldown"`
+	} `yaml:"auth_service"`
+	Users struct {
+		// Source — откуда берутся пользователи: "postgres" (копия таблицы
+		// users) или "auth" (auth-service по gRPC с кэшем в памяти).
+		Source string `yaml:"source"`
+		// CacheTTL — сколько ответ auth-service считается свежим; пока
+		// auth-service недоступен, отдаются и устаревшие записи.
+		CacheTTL time.Duration `yaml:"cache_ttl"`
+		// BatchSize — сколько ID уходит в один вызов GetUsers.
+		BatchSize int `yaml:"batch_size"`
+	} `yaml:"users"`
+	Chat struct {
+		// Retention — сколько хранятся сообщения комнат; 0 — хранить вечно.
+		Retention time.Duration `yaml:"retention"`
+		// RoomRetention переопределяет Retention для комнат по их ID.
+		RoomRetention   map[int]time.Duration `yaml:"room_retention"`
+		CleanupInterval time.Duration         `yaml:"cleanup_interval"`
+		// Broadcaster — как сообщения доходят до клиентов: "memory" в пределах
+		// одного экземпляра или "postgres" (LISTEN/NOTIFY) для нескольких реплик.
+		Broadcaster string `yaml:"broadcaster"`
+		// EditWindow — сколько после отправки автор может править и удалять
+		// своё сообщение; администраторы удаляют любые в любое время.
+		EditWindow time.Duration `yaml:"edit_window"`
+		// Flood — сколько сообщений (в комнаты и личных) пользователь может
+		// отправить за Interval; считается на каждом экземпляре отдельно.
+		Flood struct {
+			Messages int           `yaml:"messages"`
+			Interval time.Duration `yaml:"interval"`
+		} `yaml:"flood"`
+		WebSocket struct {
+			// ReadLimit — максимальный размер входящего сообщения в байтах.
+			ReadLimit int64 `yaml:"read_limit"`
+			// PongWait — сколько ждать pong, прежде чем считать соединение
+			// мёртвым; ping отправляется каждые PingPeriod.
+			PongWait       time.Duration `yaml:"pong_wait"`
+			PingPeriod     time.Duration `yaml:"ping_period"`
+			WriteWait      time.Duration `yaml:"write_wait"`
+			SendBuffer     int           `yaml:"send_buffer"`
+			MaxConnections int           `yaml:"max_connections"`
+			// MaxAnonymousStreams — лимит анонимных /chat/events, отдельный
+			// от MaxConnections.
+			MaxAnonymousStreams int `yaml:"max_anonymous_streams"`
+		} `yaml:"websocket"`
+	} `yaml:"chat"`
 }
 
 func Load() *Config {
@@ -54,6 +130,9 @@ func Load() *Config {
 
 	// Server configuration
 	cfg.Server.Port = "8081"
+	cfg.Server.ShutdownTimeout = 10 * time.Second
+	cfg.Server.AllowedOrigins = []string{"http://localhost:3000"}
+	cfg.Server.DebugAddr = "127.0.0.1:8082"
 
 	// Auth configuration
 	cfg.Auth.AccessTokenDuration = 15 * time.Minute
@@ -74,6 +153,40 @@ func Load() *Config {
 
 	// GRPC configuration
 	cfg.GRPC.Port = "50051"
+	cfg.GRPC.Timeout = 10 * time.Second
+	cfg.GRPC.MaxTimeout = time.Minute
+	cfg.GRPC.HealthInterval = 5 * time.Second
+	cfg.GRPC.HealthTimeout = 2 * time.Second
+
+	// Trash configuration
+	cfg.Trash.Retention = 30 * 24 * time.Hour
+	cfg.Trash.PurgeInterval = time.Hour
+
+	// Auth service configuration
+	cfg.AuthService.ConnectTimeout = 5 * time.Second
+	cfg.AuthService.MaxBackoff = 30 * time.Second
+	cfg.AuthService.BreakerFailures = 5
+	cfg.AuthService.BreakerCooldown = 10 * time.Second
+
+	// Users configuration
+	cfg.Users.Source = "postgres"
+	cfg.Users.CacheTTL = 5 * time.Minute
+	cfg.Users.BatchSize = 100
+
+	// Chat configuration
+	cfg.Chat.Retention = 30 * time.Minute
+	cfg.Chat.CleanupInterval = 5 * time.Minute
+	cfg.Chat.Broadcaster = "memory"
+	cfg.Chat.EditWindow = 15 * time.Minute
+	cfg.Chat.Flood.Messages = 10
+	cfg.Chat.Flood.Interval = 10 * time.Second
+	cfg.Chat.WebSocket.ReadLimit = 4096
+	cfg.Chat.WebSocket.PongWait = 60 * time.Second
+	cfg.Chat.WebSocket.PingPeriod = 54 * time.Second
+	cfg.Chat.WebSocket.WriteWait = 10 * time.Second
+	cfg.Chat.WebSocket.SendBuffer = 256
+	cfg.Chat.WebSocket.MaxConnections = 100
+	cfg.Chat.WebSocket.MaxAnonymousStreams = 100
 
 	cfg.Migrations.Enable = false
 	return cfg
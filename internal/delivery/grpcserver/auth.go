@@ -0,0 +1,73 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TokenParser validates access tokens. usecase.AuthUseCase implements it with
+// the same rules as the HTTP AuthMiddleware.
+type TokenParser interface {
+	ParseTokenClaims(tokenString string) (int64, string, time.Time, error)
+}
+
+// Caller is the authenticated user making a request. ExpiresAt is zero for
+// tokens without an expiry.
+type Caller struct {
+	UserID    int
+	Username  string
+	ExpiresAt time.Time
+}
+
+type callerKey struct{}
+
+// CallerFromContext returns the caller the auth interceptor authenticated.
+func CallerFromContext(ctx context.Context) (*Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(*Caller)
+	return caller, ok
+}
+
+func withCaller(ctx context.Context, caller *Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// authenticate returns the caller of a method that needs one: the one the
+// interceptor put into ctx or, for servers used without interceptors, the
+// one from the request metadata.
+func authenticate(ctx context.Context, auth TokenParser) (*Caller, error) {
+	if caller, ok := CallerFromContext(ctx); ok {
+		return caller, nil
+	}
+	return callerFromMetadata(ctx, auth)
+}
+
+// callerFromMetadata reads the "authorization: Bearer <token>" metadata of ctx.
+func callerFromMetadata(ctx context.Context, auth TokenParser) (*Caller, error) {
+	if auth == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication is not configured")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := bearerToken(md)
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization token required")
+	}
+
+	userID, username, expiresAt, err := auth.ParseTokenClaims(token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	return &Caller{UserID: int(userID), Username: username, ExpiresAt: expiresAt}, nil
+}
+
+func bearerToken(md metadata.MD) string {
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+}
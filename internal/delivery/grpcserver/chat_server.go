@@ -0,0 +1,161 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	chatProto "github.com/perfect1337/forum-service/internal/proto/chat"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type ChatServer struct {
+	chatProto.UnimplementedChatServiceServer
+	chatUsecase usecase.ChatUseCaseInterface
+	auth        TokenParser
+}
+
+// NewChatServer serves the chat; auth authenticates the callers of
+// SendMessage and Connect, which answer Unauthenticated without it.
+func NewChatServer(chatUC usecase.ChatUseCaseInterface, auth TokenParser) *ChatServer {
+	return &ChatServer{chatUsecase: chatUC, auth: auth}
+}
+
+func (s *ChatServer) GetMessages(ctx context.Context, req *chatProto.GetMessagesRequest) (*chatProto.GetMessagesResponse, error) {
+	messages, err := s.chatUsecase.GetMessages(ctx, entity.ChatHistoryFilter{
+		RoomID:   int(req.GetRoomId()),
+		BeforeID: int(req.GetBeforeId()),
+		AfterID:  int(req.GetAfterId()),
+		Limit:    int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, chatError(err, "failed to get messages")
+	}
+
+	resp := &chatProto.GetMessagesResponse{Messages: make([]*chatProto.ChatMessage, 0, len(messages))}
+	for i := range messages {
+		resp.Messages = append(resp.Messages, toProtoChatMessage(&messages[i]))
+	}
+	return resp, nil
+}
+
+// SendMessage posts a message to a room, like POST /chat/messages; WebSocket
+// and Connect clients of the room receive it.
+func (s *ChatServer) SendMessage(ctx context.Context, req *chatProto.SendMessageRequest) (*chatProto.ChatMessage, error) {
+	caller, err := authenticate(ctx, s.auth)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetText()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "message cannot be empty")
+	}
+
+	message := &entity.ChatMessage{
+		RoomID: int(req.GetRoomId()),
+		UserID: caller.UserID,
+		Author: caller.Username,
+		Text:   req.GetText(),
+	}
+	if err := s.chatUsecase.SendMessage(ctx, message); err != nil {
+		return nil, chatError(err, "failed to send message")
+	}
+	return toProtoChatMessage(message), nil
+}
+
+// Connect is /chat/ws over gRPC: the client sends the same envelopes as over
+// the socket and receives everything a socket in the same hub would. The
+// stream ends when the client closes its side or the hub drops the session.
+func (s *ChatServer) Connect(stream chatProto.ChatService_ConnectServer) error {
+	caller, err := authenticate(stream.Context(), s.auth)
+	if err != nil {
+		return err
+	}
+	lastSeenID := 0
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if values := md.Get("last-seen-id"); len(values) > 0 {
+		lastSeenID, err = strconv.Atoi(values[0])
+		if err != nil || lastSeenID < 0 {
+			return status.Error(codes.InvalidArgument, "invalid last-seen-id")
+		}
+	}
+
+	session, err := s.chatUsecase.OpenChatSession(usecase.ChatIdentity{
+		UserID:    caller.UserID,
+		Username:  caller.Username,
+		ExpiresAt: caller.ExpiresAt,
+	}, lastSeenID)
+	if err != nil {
+		return chatError(err, "failed to connect")
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	// Кадры клиента разбираются по одному, как в readPump; закрытие его
+	// стороны потока завершает и сессию.
+	go func() {
+		defer cancel()
+		for {
+			env, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			session.Handle(ctx, usecase.Envelope{
+				V:    int(env.GetV()),
+				Type: env.GetType(),
+				ID:   env.GetId(),
+				Data: env.GetData(),
+			})
+		}
+	}()
+
+	return session.Serve(ctx, func(env usecase.Envelope) error {
+		return stream.Send(&chatProto.Envelope{
+			V:    int32(env.V),
+			Type: env.Type,
+			Id:   env.ID,
+			Data: env.Data,
+		})
+	})
+}
+
+// chatError converts a chat use case error into a gRPC status, with the
+// same split as the HTTP handlers.
+func chatError(err error, action string) error {
+	switch {
+	case errors.Is(err, usecase.ErrChatMuted), errors.Is(err, usecase.ErrChatBanned),
+		errors.Is(err, usecase.ErrNotRoomMember):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrSlowMode), errors.Is(err, usecase.ErrFloodLimit):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, usecase.ErrChatUnavailable), errors.Is(err, usecase.ErrUsersUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, usecase.ErrNotFound):
+		return status.Error(codes.NotFound, "room not found")
+	case errors.Is(err, usecase.ErrInvalidChatRoom), errors.Is(err, usecase.ErrInvalidCursor),
+		errors.Is(err, usecase.ErrEmptyChatMessage):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", action, err)
+	}
+}
+
+func toProtoChatMessage(message *entity.ChatMessage) *chatProto.ChatMessage {
+	resp := &chatProto.ChatMessage{
+		Id:        int32(message.ID),
+		RoomId:    int32(message.RoomID),
+		UserId:    int32(message.UserID),
+		Author:    message.Author,
+		Text:      message.Text,
+		CreatedAt: timestamppb.New(message.CreatedAt),
+	}
+	if message.EditedAt != nil {
+		resp.EditedAt = timestamppb.New(*message.EditedAt)
+	}
+	return resp
+}
@@ -0,0 +1,165 @@
+package grpcserver_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/perfect1337/forum-service/internal/delivery/grpcserver"
+	"github.com/perfect1337/forum-service/internal/entity"
+	chatProto "github.com/perfect1337/forum-service/internal/proto/chat"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// mockChatUsecase implements only what the gRPC server calls; everything
+// else panics on the nil embedded interface.
+type mockChatUsecase struct {
+	usecase.ChatUseCaseInterface
+	sendMessage     func(ctx context.Context, message *entity.ChatMessage) error
+	openChatSession func(identity usecase.ChatIdentity, lastSeenID int) (usecase.ChatSession, error)
+}
+
+func (m *mockChatUsecase) SendMessage(ctx context.Context, message *entity.ChatMessage) error {
+	return m.sendMessage(ctx, message)
+}
+
+func (m *mockChatUsecase) OpenChatSession(identity usecase.ChatIdentity, lastSeenID int) (usecase.ChatSession, error) {
+	return m.openChatSession(identity, lastSeenID)
+}
+
+// echoSession acks every frame it is given.
+type echoSession struct {
+	frames chan usecase.Envelope
+}
+
+func (s *echoSession) Handle(ctx context.Context, env usecase.Envelope) {
+	s.frames <- usecase.Envelope{V: usecase.ChatProtocolVersion, Type: usecase.EventAck, ID: env.ID, Data: env.Data}
+}
+
+func (s *echoSession) Serve(ctx context.Context, send func(usecase.Envelope) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case env := <-s.frames:
+			if err := send(env); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newChatClient(t *testing.T, chatUC usecase.ChatUseCaseInterface) chatProto.ChatServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	chatProto.RegisterChatServiceServer(s, grpcserver.NewChatServer(chatUC, fakeTokenParser{"alice": 1}))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return chatProto.NewChatServiceClient(conn)
+}
+
+func TestChatServer_Connect(t *testing.T) {
+	var gotIdentity usecase.ChatIdentity
+	var gotLastSeen int
+	client := newChatClient(t, &mockChatUsecase{
+		openChatSession: func(identity usecase.ChatIdentity, lastSeenID int) (usecase.ChatSession, error) {
+			gotIdentity, gotLastSeen = identity, lastSeenID
+			return &echoSession{frames: make(chan usecase.Envelope, 1)}, nil
+		},
+	})
+
+	t.Run("Frames both ways", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer alice", "last-seen-id", "42")
+		stream, err := client.Connect(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&chatProto.Envelope{V: 1, Type: usecase.EventMessage, Id: "c1", Data: []byte(`{"text":"hi"}`)}))
+		env, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, usecase.EventAck, env.GetType())
+		assert.Equal(t, "c1", env.GetId())
+		assert.JSONEq(t, `{"text":"hi"}`, string(env.GetData()))
+		assert.Equal(t, usecase.ChatIdentity{UserID: 1, Username: "user1"}, gotIdentity)
+		assert.Equal(t, 42, gotLastSeen)
+
+		// Клиент закрыл свою сторону — сервер завершает поток.
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		stream, err := client.Connect(context.Background())
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Invalid last-seen-id", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer alice", "last-seen-id", "x")
+		stream, err := client.Connect(ctx)
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestChatServer_ConnectRefused(t *testing.T) {
+	client := newChatClient(t, &mockChatUsecase{
+		openChatSession: func(identity usecase.ChatIdentity, lastSeenID int) (usecase.ChatSession, error) {
+			return nil, usecase.ErrChatBanned
+		},
+	})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer alice")
+	stream, err := client.Connect(ctx)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestChatServer_SendMessage(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{nil, codes.OK},
+		{usecase.ErrChatMuted, codes.PermissionDenied},
+		{usecase.ErrFloodLimit, codes.ResourceExhausted},
+		{usecase.ErrNotRoomMember, codes.PermissionDenied},
+		{usecase.ErrNotFound, codes.NotFound},
+	} {
+		server := grpcserver.NewChatServer(&mockChatUsecase{
+			sendMessage: func(ctx context.Context, message *entity.ChatMessage) error {
+				message.ID = 9
+				return tc.err
+			},
+		}, fakeTokenParser{"alice": 1})
+
+		resp, err := server.SendMessage(withToken("alice"), &chatProto.SendMessageRequest{RoomId: 2, Text: "hi"})
+		assert.Equal(t, tc.code, status.Code(err), "%v", tc.err)
+		if tc.err == nil {
+			assert.Equal(t, int32(9), resp.GetId())
+			assert.Equal(t, "user1", resp.GetAuthor())
+		}
+	}
+}
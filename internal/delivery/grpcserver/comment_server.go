@@ -0,0 +1,130 @@
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	commentProto "github.com/perfect1337/forum-service/internal/proto/comment"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type CommentServer struct {
+	commentProto.UnimplementedCommentServiceServer
+	commentUsecase usecase.CommentUseCaseInterface
+	auth           TokenParser
+}
+
+// NewCommentServer serves comments; auth authenticates the callers of
+// CreateComment and DeleteComment, which answer Unauthenticated without it.
+func NewCommentServer(commentUC usecase.CommentUseCaseInterface, auth TokenParser) *CommentServer {
+	return &CommentServer{commentUsecase: commentUC, auth: auth}
+}
+
+// ListComments returns the comment tree of a post, like
+// GET /posts/{id}/comments.
+func (s *CommentServer) ListComments(ctx context.Context, req *commentProto.ListCommentsRequest) (*commentProto.ListCommentsResponse, error) {
+	if req.GetPostId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid post ID")
+	}
+	if req.GetDepth() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid depth")
+	}
+	switch req.GetSort() {
+	case "", entity.SortOld, entity.SortNew, entity.SortTop, entity.SortHot:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "invalid sort: must be old, new, top or hot")
+	}
+
+	comments, err := s.commentUsecase.GetCommentTree(ctx, int(req.GetPostId()), int(req.GetDepth()), req.GetSort())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list comments: %v", err)
+	}
+
+	resp := &commentProto.ListCommentsResponse{Comments: make([]*commentProto.Comment, 0, len(comments))}
+	for _, comment := range comments {
+		resp.Comments = append(resp.Comments, toProtoComment(comment))
+	}
+	return resp, nil
+}
+
+func (s *CommentServer) CreateComment(ctx context.Context, req *commentProto.CreateCommentRequest) (*commentProto.Comment, error) {
+	caller, err := authenticate(ctx, s.auth)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetPostId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid post ID")
+	}
+	if strings.TrimSpace(req.GetContent()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+
+	comment := &entity.Comment{
+		PostID:  int(req.GetPostId()),
+		UserID:  caller.UserID,
+		Author:  caller.Username,
+		Content: req.GetContent(),
+	}
+	if req.GetParentId() != 0 {
+		parentID := int(req.GetParentId())
+		comment.ParentID = &parentID
+	}
+	if err := s.commentUsecase.CreateComment(ctx, comment); err != nil {
+		if errors.Is(err, usecase.ErrInvalidParent) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, usecase.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "post not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create comment: %v", err)
+	}
+	return toProtoComment(comment), nil
+}
+
+// DeleteComment moves the caller's own comment to the trash.
+func (s *CommentServer) DeleteComment(ctx context.Context, req *commentProto.DeleteCommentRequest) (*commentProto.DeleteCommentResponse, error) {
+	caller, err := authenticate(ctx, s.auth)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetCommentId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid comment ID")
+	}
+
+	if err := s.commentUsecase.DeleteComment(ctx, int(req.GetCommentId()), caller.UserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "comment not found or not authorized")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete comment: %v", err)
+	}
+	return &commentProto.DeleteCommentResponse{}, nil
+}
+
+func toProtoComment(comment *entity.Comment) *commentProto.Comment {
+	resp := &commentProto.Comment{
+		Id:             int32(comment.ID),
+		PostId:         int32(comment.PostID),
+		UserId:         int32(comment.UserID),
+		Author:         comment.Author,
+		Content:        comment.Content,
+		Score:          int32(comment.Score),
+		CreatedAt:      timestamppb.New(comment.CreatedAt),
+		CollapsedCount: int32(comment.CollapsedCount),
+	}
+	if comment.ParentID != nil {
+		resp.ParentId = int32(*comment.ParentID)
+	}
+	if comment.EditedAt != nil {
+		resp.EditedAt = timestamppb.New(*comment.EditedAt)
+	}
+	for _, reply := range comment.Replies {
+		resp.Replies = append(resp.Replies, toProtoComment(reply))
+	}
+	return resp
+}
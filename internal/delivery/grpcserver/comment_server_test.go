@@ -0,0 +1,152 @@
+package grpcserver_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/delivery/grpcserver"
+	"github.com/perfect1337/forum-service/internal/entity"
+	commentProto "github.com/perfect1337/forum-service/internal/proto/comment"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MockCommentUsecase struct {
+	mock.Mock
+}
+
+func (m *MockCommentUsecase) CreateComment(ctx context.Context, comment *entity.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentUsecase) GetCommentsByPostID(ctx context.Context, postID int) ([]entity.Comment, error) {
+	args := m.Called(ctx, postID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Comment), args.Error(1)
+}
+
+func (m *MockCommentUsecase) GetCommentTree(ctx context.Context, postID, depth int, sort string) ([]*entity.Comment, error) {
+	args := m.Called(ctx, postID, depth, sort)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Comment), args.Error(1)
+}
+
+func (m *MockCommentUsecase) EditComment(ctx context.Context, postID, commentID, userID int, content string) (*entity.Comment, error) {
+	args := m.Called(ctx, postID, commentID, userID, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Comment), args.Error(1)
+}
+
+func (m *MockCommentUsecase) DeleteComment(ctx context.Context, commentID, userID int) error {
+	args := m.Called(ctx, commentID, userID)
+	return args.Error(0)
+}
+
+func (m *MockCommentUsecase) VoteComment(ctx context.Context, postID, commentID, userID, value int) (*entity.VoteResult, error) {
+	args := m.Called(ctx, postID, commentID, userID, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.VoteResult), args.Error(1)
+}
+
+var _ usecase.CommentUseCaseInterface = (*MockCommentUsecase)(nil)
+
+func TestCommentServer_ListComments(t *testing.T) {
+	t.Run("Tree", func(t *testing.T) {
+		commentUsecase := new(MockCommentUsecase)
+		parentID := 1
+		editedAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+		commentUsecase.On("GetCommentTree", mock.Anything, 5, 2, "top").Return([]*entity.Comment{{
+			ID: 1, PostID: 5, Content: "Root", EditedAt: &editedAt,
+			Replies: []*entity.Comment{{ID: 2, PostID: 5, ParentID: &parentID, Content: "Reply", CollapsedCount: 3}},
+		}}, nil)
+
+		resp, err := grpcserver.NewCommentServer(commentUsecase, nil).ListComments(context.Background(),
+			&commentProto.ListCommentsRequest{PostId: 5, Depth: 2, Sort: "top"})
+
+		assert.NoError(t, err)
+		root := resp.GetComments()[0]
+		assert.Equal(t, editedAt, root.GetEditedAt().AsTime())
+		assert.Equal(t, int32(1), root.GetReplies()[0].GetParentId())
+		assert.Equal(t, int32(3), root.GetReplies()[0].GetCollapsedCount())
+		commentUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidArgument", func(t *testing.T) {
+		server := grpcserver.NewCommentServer(new(MockCommentUsecase), nil)
+		_, err := server.ListComments(context.Background(), &commentProto.ListCommentsRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = server.ListComments(context.Background(), &commentProto.ListCommentsRequest{PostId: 5, Sort: "random"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestCommentServer_CreateComment(t *testing.T) {
+	t.Run("Reply", func(t *testing.T) {
+		commentUsecase := new(MockCommentUsecase)
+		commentUsecase.On("CreateComment", mock.Anything, mock.MatchedBy(func(c *entity.Comment) bool {
+			return c.PostID == 5 && c.UserID == 1 && c.ParentID != nil && *c.ParentID == 3
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.Comment).ID = 7
+		}).Return(nil)
+
+		server := grpcserver.NewCommentServer(commentUsecase, fakeTokenParser{"alice": 1})
+		resp, err := server.CreateComment(withToken("alice"), &commentProto.CreateCommentRequest{PostId: 5, ParentId: 3, Content: "Hi"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(7), resp.GetId())
+		assert.Equal(t, "user1", resp.GetAuthor())
+		commentUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		commentUsecase := new(MockCommentUsecase)
+		commentUsecase.On("CreateComment", mock.Anything, mock.Anything).
+			Return(fmt.Errorf("%w: parent belongs to a different post", usecase.ErrInvalidParent))
+		server := grpcserver.NewCommentServer(commentUsecase, fakeTokenParser{"alice": 1})
+
+		_, err := server.CreateComment(context.Background(), &commentProto.CreateCommentRequest{PostId: 5, Content: "Hi"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		_, err = server.CreateComment(withToken("alice"), &commentProto.CreateCommentRequest{PostId: 5, Content: " "})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = server.CreateComment(withToken("alice"), &commentProto.CreateCommentRequest{PostId: 5, ParentId: 9, Content: "Hi"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("PostInTrash", func(t *testing.T) {
+		commentUsecase := new(MockCommentUsecase)
+		commentUsecase.On("CreateComment", mock.Anything, mock.Anything).
+			Return(fmt.Errorf("%w: post 5", usecase.ErrNotFound))
+		server := grpcserver.NewCommentServer(commentUsecase, fakeTokenParser{"alice": 1})
+
+		_, err := server.CreateComment(withToken("alice"), &commentProto.CreateCommentRequest{PostId: 5, Content: "Hi"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestCommentServer_DeleteComment(t *testing.T) {
+	commentUsecase := new(MockCommentUsecase)
+	commentUsecase.On("DeleteComment", mock.Anything, 7, 1).Return(nil)
+	commentUsecase.On("DeleteComment", mock.Anything, 8, 1).Return(sql.ErrNoRows)
+	server := grpcserver.NewCommentServer(commentUsecase, fakeTokenParser{"alice": 1})
+
+	_, err := server.DeleteComment(withToken("alice"), &commentProto.DeleteCommentRequest{CommentId: 7})
+	assert.NoError(t, err)
+	_, err = server.DeleteComment(withToken("alice"), &commentProto.DeleteCommentRequest{CommentId: 8})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	commentUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,170 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheck reports whether a dependency works; nil means it does.
+type HealthCheck func(ctx context.Context) error
+
+// ConnHealthCheck reports a client connection as down while it fails to
+// connect or is closed. An idle connection is nudged to connect and counts as
+// up, since the next call would do the same.
+func ConnHealthCheck(conn *grpc.ClientConn) HealthCheck {
+	return func(ctx context.Context) error {
+		switch state := conn.GetState(); state {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("connection is %s", state)
+		case connectivity.Idle:
+			conn.Connect()
+		}
+		return nil
+	}
+}
+
+// Health serves grpc.health.v1 from a set of dependency checks. Each check is
+// reported as a service of its own name, and the server as a whole ("") is
+// SERVING only while all of the required ones pass. Optional dependencies
+// only degrade the service, so their failures show under their own name.
+type Health struct {
+	server   *health.Server
+	checks   map[string]HealthCheck
+	optional map[string]bool
+	timeout  time.Duration
+	log      Logger
+
+	mu       sync.Mutex
+	failed   map[string]bool
+	checked  bool
+	serving  bool
+	shutdown bool
+}
+
+// NewHealth creates a Health that runs each check with the given timeout.
+// Until the first Check every status is NOT_SERVING.
+func NewHealth(checks map[string]HealthCheck, timeout time.Duration, log Logger) *Health {
+	return NewHealthWithOptional(checks, nil, timeout, log)
+}
+
+// NewHealthWithOptional is NewHealth with dependencies the server can run
+// without, such as auth-service.
+func NewHealthWithOptional(required, optional map[string]HealthCheck, timeout time.Duration, log Logger) *Health {
+	h := &Health{
+		server:   health.NewServer(),
+		checks:   make(map[string]HealthCheck, len(required)+len(optional)),
+		optional: make(map[string]bool, len(optional)),
+		timeout:  timeout,
+		log:      log,
+		failed:   make(map[string]bool),
+	}
+	for name, check := range required {
+		h.checks[name] = check
+	}
+	for name, check := range optional {
+		h.checks[name] = check
+		h.optional[name] = true
+	}
+	h.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	for name := range h.checks {
+		h.failed[name] = true
+		h.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return h
+}
+
+// Register adds the health service to s.
+func (h *Health) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, h.server)
+}
+
+// Check runs every check once and updates the statuses. It does nothing
+// after Shutdown.
+func (h *Health) Check(ctx context.Context) {
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(h.checks))
+	for name, check := range h.checks {
+		go func(name string, check HealthCheck) {
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+			results <- result{name: name, err: check(checkCtx)}
+		}(name, check)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	serving := true
+	for range h.checks {
+		r := <-results
+		status := healthpb.HealthCheckResponse_SERVING
+		if r.err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			serving = serving && h.optional[r.name]
+		}
+		// Пишем в лог только смену состояния, а не каждую проверку; при
+		// первой проверке — только упавшие зависимости.
+		if h.log != nil && (r.err != nil) != (h.checked && h.failed[r.name]) && !h.shutdown {
+			if r.err != nil {
+				h.log.Errorw("dependency is down", "dependency", r.name, "error", r.err.Error())
+			} else {
+				h.log.Infow("dependency is back up", "dependency", r.name)
+			}
+		}
+		h.failed[r.name] = r.err != nil
+		h.server.SetServingStatus(r.name, status)
+	}
+	h.checked, h.serving = true, serving
+	if serving {
+		h.server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	} else {
+		h.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// Status returns whether the server is SERVING and which dependencies are
+// up, as of the last Check. It is what the HTTP /health endpoint reports.
+func (h *Health) Status() (serving bool, dependencies map[string]bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	dependencies = make(map[string]bool, len(h.failed))
+	for name, failed := range h.failed {
+		dependencies[name] = !failed
+	}
+	return h.serving && !h.shutdown, dependencies
+}
+
+// Start checks right away and then every interval until ctx is done.
+func (h *Health) Start(ctx context.Context, interval time.Duration) {
+	h.Check(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Shutdown reports every service as NOT_SERVING for good, so load balancers
+// stop sending calls while the server drains.
+func (h *Health) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shutdown = true
+	h.server.Shutdown()
+}
@@ -0,0 +1,243 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/delivery/grpcserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// switchCheck is a HealthCheck the test can break and repair.
+type switchCheck struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (c *switchCheck) set(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *switchCheck) check(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func dialBufconn(t *testing.T, register func(s *grpc.Server), opts ...grpc.ServerOption) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(opts...)
+	register(s)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestHealth(t *testing.T) {
+	postgres, auth := &switchCheck{}, &switchCheck{}
+	logger := &recordingLogger{}
+	health := grpcserver.NewHealth(map[string]grpcserver.HealthCheck{
+		"postgres":     postgres.check,
+		"auth-service": auth.check,
+	}, time.Second, logger)
+	client := healthpb.NewHealthClient(dialBufconn(t, health.Register))
+
+	statusOf := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.GetStatus()
+	}
+
+	t.Run("До первой проверки сервер не готов", func(t *testing.T) {
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(""))
+	})
+
+	t.Run("Все зависимости работают", func(t *testing.T) {
+		health.Check(context.Background())
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(""))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf("postgres"))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf("auth-service"))
+	})
+
+	t.Run("Упал auth-service", func(t *testing.T) {
+		auth.set(errors.New("connection is TRANSIENT_FAILURE"))
+		health.Check(context.Background())
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(""))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf("postgres"))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf("auth-service"))
+
+		logger.mu.Lock()
+		defer logger.mu.Unlock()
+		require.NotEmpty(t, logger.entries)
+		last := logger.entries[len(logger.entries)-1]
+		assert.Equal(t, "error", last.level)
+		assert.Equal(t, "auth-service", last.fields["dependency"])
+	})
+
+	t.Run("Восстановление", func(t *testing.T) {
+		auth.set(nil)
+		health.Check(context.Background())
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(""))
+	})
+
+	t.Run("При остановке NOT_SERVING навсегда", func(t *testing.T) {
+		health.Shutdown()
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(""))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf("postgres"))
+
+		health.Check(context.Background())
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(""))
+	})
+}
+
+func TestHealth_Watch(t *testing.T) {
+	postgres := &switchCheck{}
+	health := grpcserver.NewHealth(map[string]grpcserver.HealthCheck{"postgres": postgres.check}, time.Second, nil)
+	client := healthpb.NewHealthClient(dialBufconn(t, health.Register))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+
+	health.Check(ctx)
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	health.Shutdown()
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+}
+
+func TestReflection(t *testing.T) {
+	health := grpcserver.NewHealth(nil, time.Second, nil)
+	conn := dialBufconn(t, func(s *grpc.Server) {
+		health.Register(s)
+		reflection.Register(s)
+	})
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
+
+func TestHealth_PublicBehindInterceptors(t *testing.T) {
+	health := grpcserver.NewHealth(nil, time.Second, nil)
+	health.Check(context.Background())
+	conn := dialBufconn(t, func(s *grpc.Server) {
+		health.Register(s)
+		reflection.Register(s)
+	}, grpcserver.ServerOptions(grpcserver.InterceptorOptions{Auth: fakeTokenParser{"alice": 1}})...)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Пробы и grpcurl ходят без токена
+	t.Run("Check", func(t *testing.T) {
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	})
+
+	t.Run("Reflection", func(t *testing.T) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+		_, err = stream.Recv()
+		require.NoError(t, err)
+	})
+}
+
+func TestHealth_OptionalDependency(t *testing.T) {
+	postgres, auth := &switchCheck{}, &switchCheck{}
+	health := grpcserver.NewHealthWithOptional(
+		map[string]grpcserver.HealthCheck{"postgres": postgres.check},
+		map[string]grpcserver.HealthCheck{"auth-service": auth.check},
+		time.Second, nil,
+	)
+	client := healthpb.NewHealthClient(dialBufconn(t, health.Register))
+	statusOf := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.GetStatus()
+	}
+
+	t.Run("Без auth-service сервер работает в деградированном режиме", func(t *testing.T) {
+		auth.set(errors.New("connection is TRANSIENT_FAILURE"))
+		health.Check(context.Background())
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(""))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf("auth-service"))
+
+		serving, deps := health.Status()
+		assert.True(t, serving)
+		assert.Equal(t, map[string]bool{"postgres": true, "auth-service": false}, deps)
+	})
+
+	t.Run("Без Postgres сервер не готов", func(t *testing.T) {
+		postgres.set(errors.New("failed to ping db"))
+		health.Check(context.Background())
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(""))
+		serving, _ := health.Status()
+		assert.False(t, serving)
+	})
+
+	t.Run("Остановка", func(t *testing.T) {
+		postgres.set(nil)
+		health.Check(context.Background())
+		serving, _ := health.Status()
+		require.True(t, serving)
+
+		health.Shutdown()
+		serving, _ = health.Status()
+		assert.False(t, serving)
+	})
+}
@@ -0,0 +1,291 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	chatProto "github.com/perfect1337/forum-service/internal/proto/chat"
+	commentProto "github.com/perfect1337/forum-service/internal/proto/comment"
+	postProto "github.com/perfect1337/forum-service/internal/proto/post"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionpbalpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader is the metadata key of the request ID. A client may set it
+// to tie its own logs to ours; otherwise one is generated. It is always sent
+// back in the response header.
+const RequestIDHeader = "x-request-id"
+
+// PublicMethods can be called without a token. A token that is sent anyway
+// must still be valid. Health checks and reflection are public so that
+// probes and grpcurl work without credentials.
+var PublicMethods = map[string]bool{
+	postProto.PostService_GetPostWithAuthor_FullMethodName:  true,
+	postProto.PostService_ListPosts_FullMethodName:          true,
+	postProto.PostService_GetPost_FullMethodName:            true,
+	postProto.PostService_GetPosts_FullMethodName:           true,
+	commentProto.CommentService_ListComments_FullMethodName: true,
+	chatProto.ChatService_GetMessages_FullMethodName:        true,
+
+	healthpb.Health_Check_FullMethodName:                                   true,
+	healthpb.Health_Watch_FullMethodName:                                   true,
+	reflectionpb.ServerReflection_ServerReflectionInfo_FullMethodName:      true,
+	reflectionpbalpha.ServerReflection_ServerReflectionInfo_FullMethodName: true,
+}
+
+// Logger is what the interceptors log through; the project logger is one.
+type Logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// InterceptorOptions configure the interceptor chain.
+type InterceptorOptions struct {
+	// Auth validates tokens; without it only PublicMethods can be called.
+	Auth TokenParser
+	// Public overrides PublicMethods when set.
+	Public map[string]bool
+	Logger Logger
+	// DefaultTimeout is the deadline of unary calls that come without one,
+	// and MaxTimeout caps the deadline a client may ask for. Zero disables
+	// either. Streams are long-lived and keep the client's deadline.
+	DefaultTimeout time.Duration
+	MaxTimeout     time.Duration
+}
+
+// ServerOptions installs the unary and stream interceptors. Calls go through
+// request ID, logging, panic recovery, deadline and authentication, in that
+// order, so the log line has the request ID and the final status code.
+func ServerOptions(opts InterceptorOptions) []grpc.ServerOption {
+	if opts.Public == nil {
+		opts.Public = PublicMethods
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			unaryRequestID,
+			unaryLogging(opts.Logger),
+			unaryRecovery(opts.Logger),
+			unaryDeadline(opts.DefaultTimeout, opts.MaxTimeout),
+			unaryAuth(opts.Auth, opts.Public),
+		),
+		grpc.ChainStreamInterceptor(
+			streamRequestID,
+			streamLogging(opts.Logger),
+			streamRecovery(opts.Logger),
+			streamAuth(opts.Auth, opts.Public),
+		),
+	}
+}
+
+// serverStream is a grpc.ServerStream with a context changed by an
+// interceptor.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID of the request being served.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID takes the request ID from the metadata, or makes one up, and
+// puts it into the context and the response header.
+func withRequestID(ctx context.Context, setHeader func(metadata.MD) error) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var id string
+	if values := md.Get(RequestIDHeader); len(values) > 0 && len(values[0]) <= 128 {
+		id = values[0]
+	}
+	if id == "" {
+		id = newRequestID()
+	}
+	// Заголовок не уходит только если ответ уже начат; запрос это не ломает.
+	_ = setHeader(metadata.Pairs(RequestIDHeader, id))
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+func unaryRequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx = withRequestID(ctx, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+	return handler(ctx, req)
+}
+
+func streamRequestID(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := withRequestID(ss.Context(), ss.SetHeader)
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// callLog collects what inner interceptors learn about a call for the log
+// line, such as the authenticated user.
+type callLog struct {
+	userID int
+}
+
+type callLogKey struct{}
+
+// logCall writes one line per finished call. Codes that mean the server
+// failed are logged as errors, everything else, client mistakes included, as
+// info.
+func logCall(log Logger, ctx context.Context, method string, started time.Time, entry *callLog, err error) {
+	if log == nil {
+		return
+	}
+	code := status.Code(err)
+	fields := []interface{}{
+		"method", method,
+		"code", code.String(),
+		"duration", time.Since(started),
+		"request_id", RequestIDFromContext(ctx),
+	}
+	if entry.userID != 0 {
+		fields = append(fields, "user_id", entry.userID)
+	}
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		log.Errorw("gRPC call failed", append(fields, "error", err.Error())...)
+	default:
+		log.Infow("gRPC call", fields...)
+	}
+}
+
+func unaryLogging(log Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		started, entry := time.Now(), &callLog{}
+		resp, err := handler(context.WithValue(ctx, callLogKey{}, entry), req)
+		logCall(log, ctx, info.FullMethod, started, entry, err)
+		return resp, err
+	}
+}
+
+func streamLogging(log Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		started, entry := time.Now(), &callLog{}
+		ctx := ss.Context()
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: context.WithValue(ctx, callLogKey{}, entry)})
+		logCall(log, ctx, info.FullMethod, started, entry, err)
+		return err
+	}
+}
+
+// recovered turns a panic of a handler into an Internal error, so one bad
+// request can't take the whole process down.
+func recovered(log Logger, ctx context.Context, method string, p interface{}) error {
+	if log != nil {
+		log.Errorw("gRPC handler panicked",
+			"method", method,
+			"request_id", RequestIDFromContext(ctx),
+			"panic", fmt.Sprint(p),
+			"stack", string(debug.Stack()),
+		)
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+func unaryRecovery(log Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				resp, err = nil, recovered(log, ctx, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func streamRecovery(log Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(log, ss.Context(), info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// unaryDeadline gives calls without a deadline defaultTimeout and shortens
+// longer ones to maxTimeout. A handler that runs out of time answers
+// DeadlineExceeded whatever error it returned.
+func unaryDeadline(defaultTimeout, maxTimeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		timeout := time.Duration(0)
+		if deadline, ok := ctx.Deadline(); !ok {
+			timeout = defaultTimeout
+		} else if maxTimeout > 0 && time.Until(deadline) > maxTimeout {
+			timeout = maxTimeout
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, status.Error(codes.DeadlineExceeded, "deadline exceeded")
+		}
+		return resp, err
+	}
+}
+
+// authorize authenticates the caller of method. Only public methods may be
+// called without a token; a token sent to one must still be valid.
+func authorize(ctx context.Context, auth TokenParser, public map[string]bool, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if bearerToken(md) == "" && public[method] {
+		return ctx, nil
+	}
+	caller, err := callerFromMetadata(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := ctx.Value(callLogKey{}).(*callLog); ok {
+		entry.userID = caller.UserID
+	}
+	return withCaller(ctx, caller), nil
+}
+
+func unaryAuth(auth TokenParser, public map[string]bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, auth, public, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuth(auth TokenParser, public map[string]bool) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), auth, public, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -0,0 +1,264 @@
+package grpcserver_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/delivery/grpcserver"
+	"github.com/perfect1337/forum-service/internal/entity"
+	chatProto "github.com/perfect1337/forum-service/internal/proto/chat"
+	postProto "github.com/perfect1337/forum-service/internal/proto/post"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) record(level, msg string, keysAndValues []interface{}) {
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, fields: fields})
+}
+
+func (l *recordingLogger) Infow(msg string, keysAndValues ...interface{}) {
+	l.record("info", msg, keysAndValues)
+}
+
+func (l *recordingLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.record("error", msg, keysAndValues)
+}
+
+// last returns the latest entry for method. The log line is written after
+// the response is sent, so it may take a moment to show up.
+func (l *recordingLogger) last(t *testing.T, method string) logEntry {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		for i := len(l.entries) - 1; i >= 0; i-- {
+			if l.entries[i].fields["method"] == method && l.entries[i].fields["code"] != nil {
+				entry := l.entries[i]
+				l.mu.Unlock()
+				return entry
+			}
+		}
+		l.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no log entry for %s", method)
+	return logEntry{}
+}
+
+// newInterceptedServer serves the post and chat services behind the
+// interceptor chain. The servers themselves get no token parser, so any
+// caller they see came from the auth interceptor.
+func newInterceptedServer(t *testing.T, postUC usecase.PostUseCase, chatUC usecase.ChatUseCaseInterface, opts grpcserver.InterceptorOptions) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(grpcserver.ServerOptions(opts)...)
+	postProto.RegisterPostServiceServer(s, grpcserver.NewPostServer(postUC, nil))
+	chatProto.RegisterChatServiceServer(s, grpcserver.NewChatServer(chatUC, nil))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func bearer(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestInterceptors_Auth(t *testing.T) {
+	postUsecase := new(MockPostUsecase)
+	postUsecase.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, Title: "Public"}, nil)
+	postUsecase.On("DeletePost", mock.Anything, 1, 1).Return(nil)
+	conn := newInterceptedServer(t, postUsecase, nil, grpcserver.InterceptorOptions{Auth: fakeTokenParser{"alice": 1}})
+	client := postProto.NewPostServiceClient(conn)
+
+	t.Run("Публичный метод без токена", func(t *testing.T) {
+		resp, err := client.GetPost(context.Background(), &postProto.PostRequest{PostId: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "Public", resp.GetTitle())
+	})
+
+	t.Run("Неверный токен отклоняется и на публичном методе", func(t *testing.T) {
+		_, err := client.GetPost(bearer("mallory"), &postProto.PostRequest{PostId: 1})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Закрытый метод без токена", func(t *testing.T) {
+		_, err := client.DeletePost(context.Background(), &postProto.PostRequest{PostId: 1})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		postUsecase.AssertNotCalled(t, "DeletePost", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Пользователь из перехватчика доходит до обработчика", func(t *testing.T) {
+		_, err := client.DeletePost(bearer("alice"), &postProto.PostRequest{PostId: 1})
+		require.NoError(t, err)
+		postUsecase.AssertCalled(t, "DeletePost", mock.Anything, 1, 1)
+	})
+}
+
+func TestInterceptors_RequestIDAndLogging(t *testing.T) {
+	postUsecase := new(MockPostUsecase)
+	postUsecase.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1}, nil)
+	postUsecase.On("DeletePost", mock.Anything, 1, 1).Return(nil)
+	logger := &recordingLogger{}
+	conn := newInterceptedServer(t, postUsecase, nil, grpcserver.InterceptorOptions{
+		Auth:   fakeTokenParser{"alice": 1},
+		Logger: logger,
+	})
+	client := postProto.NewPostServiceClient(conn)
+
+	t.Run("ID клиента возвращается и пишется в лог", func(t *testing.T) {
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(bearer("alice"), grpcserver.RequestIDHeader, "req-1")
+		_, err := client.DeletePost(ctx, &postProto.PostRequest{PostId: 1}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"req-1"}, header.Get(grpcserver.RequestIDHeader))
+
+		entry := logger.last(t, postProto.PostService_DeletePost_FullMethodName)
+		assert.Equal(t, "info", entry.level)
+		assert.Equal(t, "req-1", entry.fields["request_id"])
+		assert.Equal(t, "OK", entry.fields["code"])
+		assert.Equal(t, 1, entry.fields["user_id"])
+	})
+
+	t.Run("Без ID клиента сервер создаёт свой", func(t *testing.T) {
+		var header metadata.MD
+		_, err := client.GetPost(context.Background(), &postProto.PostRequest{PostId: 1}, grpc.Header(&header))
+		require.NoError(t, err)
+		require.Len(t, header.Get(grpcserver.RequestIDHeader), 1)
+		assert.NotEmpty(t, header.Get(grpcserver.RequestIDHeader)[0])
+	})
+
+	t.Run("Отказ в доступе пишется как info", func(t *testing.T) {
+		_, err := client.DeletePost(context.Background(), &postProto.PostRequest{PostId: 1})
+		require.Error(t, err)
+		entry := logger.last(t, postProto.PostService_DeletePost_FullMethodName)
+		assert.Equal(t, "info", entry.level)
+		assert.Equal(t, "Unauthenticated", entry.fields["code"])
+	})
+}
+
+func TestInterceptors_Recovery(t *testing.T) {
+	postUsecase := new(MockPostUsecase)
+	postUsecase.On("GetPostByID", mock.Anything, 1).Run(func(args mock.Arguments) {
+		panic("boom")
+	}).Return(nil, nil)
+	postUsecase.On("GetPostByID", mock.Anything, 2).Return(&entity.Post{ID: 2}, nil)
+	logger := &recordingLogger{}
+	client := postProto.NewPostServiceClient(newInterceptedServer(t, postUsecase, nil, grpcserver.InterceptorOptions{Logger: logger}))
+
+	_, err := client.GetPost(context.Background(), &postProto.PostRequest{PostId: 1})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	entry := logger.last(t, postProto.PostService_GetPost_FullMethodName)
+	assert.Equal(t, "error", entry.level)
+
+	// Сервер пережил панику и отвечает дальше.
+	resp, err := client.GetPost(context.Background(), &postProto.PostRequest{PostId: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.GetId())
+}
+
+func TestInterceptors_Deadline(t *testing.T) {
+	postUsecase := new(MockPostUsecase)
+	var gotDeadline time.Duration
+	postUsecase.On("GetPostByID", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		deadline, _ := ctx.Deadline()
+		gotDeadline = time.Until(deadline)
+		<-ctx.Done()
+	}).Return(nil, context.DeadlineExceeded)
+	client := postProto.NewPostServiceClient(newInterceptedServer(t, postUsecase, nil, grpcserver.InterceptorOptions{
+		DefaultTimeout: 50 * time.Millisecond,
+		MaxTimeout:     100 * time.Millisecond,
+	}))
+
+	t.Run("Срок по умолчанию", func(t *testing.T) {
+		_, err := client.GetPost(context.Background(), &postProto.PostRequest{PostId: 1})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.LessOrEqual(t, gotDeadline, 50*time.Millisecond)
+	})
+
+	t.Run("Слишком долгий срок клиента урезается", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		_, err := client.GetPost(ctx, &postProto.PostRequest{PostId: 1})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.LessOrEqual(t, gotDeadline, 100*time.Millisecond)
+	})
+}
+
+func TestInterceptors_Stream(t *testing.T) {
+	var gotIdentity usecase.ChatIdentity
+	chatUsecase := &mockChatUsecase{
+		openChatSession: func(identity usecase.ChatIdentity, lastSeenID int) (usecase.ChatSession, error) {
+			gotIdentity = identity
+			return &echoSession{frames: make(chan usecase.Envelope, 1)}, nil
+		},
+	}
+	logger := &recordingLogger{}
+	client := chatProto.NewChatServiceClient(newInterceptedServer(t, nil, chatUsecase, grpcserver.InterceptorOptions{
+		Auth:   fakeTokenParser{"alice": 1},
+		Logger: logger,
+	}))
+
+	t.Run("Без токена", func(t *testing.T) {
+		stream, err := client.Connect(context.Background())
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("С токеном и ID запроса", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(bearer("alice"), grpcserver.RequestIDHeader, "stream-1")
+		stream, err := client.Connect(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&chatProto.Envelope{Type: usecase.EventPresence, Id: "p1"}))
+		_, err = stream.Recv()
+		require.NoError(t, err)
+		header, err := stream.Header()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"stream-1"}, header.Get(grpcserver.RequestIDHeader))
+		assert.Equal(t, 1, gotIdentity.UserID)
+
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		require.Error(t, err)
+		entry := logger.last(t, chatProto.ChatService_Connect_FullMethodName)
+		assert.Equal(t, "stream-1", entry.fields["request_id"])
+	})
+}
@@ -3,51 +3,276 @@ package grpcserver
 
 import (
 	"context"
-	"strconv"
+	"database/sql"
+	"errors"
 
+	"github.com/perfect1337/forum-service/internal/entity"
 	postProto "github.com/perfect1337/forum-service/internal/proto/post"
 	userProto "github.com/perfect1337/forum-service/internal/proto/user"
 	"github.com/perfect1337/forum-service/internal/usecase"
 	googlegrpc "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type PostServer struct {
 	postProto.UnimplementedPostServiceServer
 	postUsecase usecase.PostUseCase
+	auth        TokenParser
 	UserClient  userProto.UserServiceClient // Публичное поле
+	users       UserDirectory
+}
+
+// UserDirectory looks users up by ID, e.g. repository.RemoteUsers.
+type UserDirectory interface {
+	GetUserByID(ctx context.Context, id int) (*entity.User, error)
 }
 
 func NewPostServer(postUC usecase.PostUseCase, userConn *googlegrpc.ClientConn) *PostServer {
+	return NewPostServerWithAuth(postUC, nil, userConn)
+}
+
+// NewPostServerWithAuth is NewPostServer that also serves the methods that
+// change posts, authenticating callers with auth. Without auth they answer
+// Unauthenticated.
+func NewPostServerWithAuth(postUC usecase.PostUseCase, auth TokenParser, userConn *googlegrpc.ClientConn) *PostServer {
 	return &PostServer{
 		postUsecase: postUC,
+		auth:        auth,
 		UserClient:  userProto.NewUserServiceClient(userConn), // Исправлено имя поля
 	}
 }
 
+// NewPostServerWithUsers is NewPostServerWithAuth that takes author names
+// from users, which caches them, instead of calling GetUsername every time.
+func NewPostServerWithUsers(postUC usecase.PostUseCase, auth TokenParser, users UserDirectory) *PostServer {
+	return &PostServer{
+		postUsecase: postUC,
+		auth:        auth,
+		users:       users,
+	}
+}
+
+// GetPostWithAuthor is GetPost with the author's name taken from the auth
+// service rather than the local users table. While auth-service is down the
+// post is still returned, with the local name or usecase.PlaceholderAuthorName.
 func (s *PostServer) GetPostWithAuthor(ctx context.Context, req *postProto.PostRequest) (*postProto.PostResponse, error) {
 	post, err := s.postUsecase.GetPostByID(ctx, int(req.GetPostId()))
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "post not found: %v", err)
+		return nil, postError(err, "failed to get post")
 	}
+	resp := toProtoPost(post)
 
-	authorID, err := strconv.Atoi(post.Author)
-	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid author ID format: %v", err)
+	if s.users != nil {
+		user, err := s.users.GetUserByID(ctx, post.UserID)
+		switch {
+		case errors.Is(err, usecase.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "author not found")
+		case errors.Is(err, usecase.ErrUsersUnavailable):
+			resp.AuthorName = degradedAuthorName(post)
+		case err != nil:
+			return nil, status.Errorf(codes.Internal, "failed to get username: %v", err)
+		default:
+			resp.AuthorName = user.Username
+		}
+		return resp, nil
 	}
 
 	usernameResp, err := s.UserClient.GetUsername(ctx, &userProto.UserRequest{
-		UserId: int32(authorID),
+		UserId: int32(post.UserID),
 	})
-	if err != nil {
+	switch code := status.Code(err); {
+	case code == codes.Unavailable, code == codes.DeadlineExceeded:
+		resp.AuthorName = degradedAuthorName(post)
+	case err != nil:
 		return nil, status.Errorf(codes.Internal, "failed to get username: %v", err)
+	default:
+		resp.AuthorName = usernameResp.GetUsername()
+	}
+	return resp, nil
+}
+
+func degradedAuthorName(post *entity.Post) string {
+	if post.Author != "" {
+		return post.Author
+	}
+	return usecase.PlaceholderAuthorName
+}
+
+func (s *PostServer) GetPost(ctx context.Context, req *postProto.PostRequest) (*postProto.PostResponse, error) {
+	post, err := s.postUsecase.GetPostByID(ctx, int(req.GetPostId()))
+	if err != nil {
+		return nil, postError(err, "failed to get post")
+	}
+	return toProtoPost(post), nil
+}
+
+// GetPosts returns the posts in the order of post_ids and lists the IDs it
+// couldn't find instead of failing the whole batch.
+func (s *PostServer) GetPosts(ctx context.Context, req *postProto.GetPostsRequest) (*postProto.GetPostsResponse, error) {
+	ids := make([]int, 0, len(req.GetPostIds()))
+	for _, id := range req.GetPostIds() {
+		ids = append(ids, int(id))
+	}
+
+	posts, err := s.postUsecase.GetPostsByIDs(ctx, ids)
+	if err != nil {
+		return nil, postError(err, "failed to get posts")
+	}
+
+	resp := &postProto.GetPostsResponse{Posts: make([]*postProto.PostResponse, 0, len(posts))}
+	found := make(map[int32]bool, len(posts))
+	for _, post := range posts {
+		found[int32(post.ID)] = true
+		resp.Posts = append(resp.Posts, toProtoPost(post))
+	}
+	for _, id := range req.GetPostIds() {
+		if !found[id] {
+			found[id] = true // дубликаты не повторяем
+			resp.MissingIds = append(resp.MissingIds, id)
+		}
+	}
+	return resp, nil
+}
+
+func (s *PostServer) CreatePost(ctx context.Context, req *postProto.CreatePostRequest) (*postProto.PostResponse, error) {
+	caller, err := authenticate(ctx, s.auth)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetTitle() == "" || req.GetContent() == "" {
+		return nil, status.Error(codes.InvalidArgument, "title and content are required")
+	}
+
+	post := &entity.Post{
+		Title:   req.GetTitle(),
+		Content: req.GetContent(),
+		UserID:  caller.UserID,
+		Author:  caller.Username,
+		Tags:    req.GetTags(),
 	}
+	if req.GetCategoryId() != 0 {
+		categoryID := int(req.GetCategoryId())
+		post.CategoryID = &categoryID
+	}
+	if err := s.postUsecase.CreatePost(ctx, post); err != nil {
+		return nil, postError(err, "failed to create post")
+	}
+	return toProtoPost(post), nil
+}
+
+// UpdatePost changes the text, the taxonomy or both, with the same rules as
+// PUT /posts/{id}, and returns the post as it is afterwards.
+func (s *PostServer) UpdatePost(ctx context.Context, req *postProto.UpdatePostRequest) (*postProto.PostResponse, error) {
+	caller, err := authenticate(ctx, s.auth)
+	if err != nil {
+		return nil, err
+	}
+	postID := int(req.GetPostId())
+	if req.GetTitle() == "" && req.GetContent() == "" && req.GetTaxonomy() == nil {
+		return nil, status.Error(codes.InvalidArgument, "nothing to update")
+	}
+
+	if req.GetTitle() != "" || req.GetContent() != "" {
+		if err := s.postUsecase.UpdatePost(ctx, postID, caller.UserID, req.GetTitle(), req.GetContent()); err != nil {
+			return nil, postError(err, "failed to update post")
+		}
+	}
+	if taxonomy := req.GetTaxonomy(); taxonomy != nil {
+		categoryID := int(taxonomy.GetCategoryId())
+		tags := taxonomy.GetTags()
+		if tags == nil {
+			tags = []string{}
+		}
+		if err := s.postUsecase.UpdatePostTaxonomy(ctx, postID, caller.UserID, &categoryID, tags); err != nil {
+			return nil, postError(err, "failed to update post")
+		}
+	}
+
+	post, err := s.postUsecase.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, postError(err, "failed to get post")
+	}
+	return toProtoPost(post), nil
+}
 
-	return &postProto.PostResponse{
+func (s *PostServer) DeletePost(ctx context.Context, req *postProto.PostRequest) (*postProto.DeletePostResponse, error) {
+	caller, err := authenticate(ctx, s.auth)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.postUsecase.DeletePost(ctx, int(req.GetPostId()), caller.UserID); err != nil {
+		return nil, postError(err, "failed to delete post")
+	}
+	return &postProto.DeletePostResponse{}, nil
+}
+
+func (s *PostServer) ListPosts(ctx context.Context, req *postProto.ListPostsRequest) (*postProto.ListPostsResponse, error) {
+	filter := entity.PostFilter{
+		Limit:    int(req.GetLimit()),
+		Cursor:   req.GetCursor(),
+		AuthorID: int(req.GetAuthorId()),
+		Author:   req.GetAuthor(),
+		Category: req.GetCategory(),
+		Tag:      req.GetTag(),
+		Sort:     req.GetSort(),
+		Order:    req.GetOrder(),
+	}
+	if req.GetFrom() != nil {
+		filter.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		filter.To = req.GetTo().AsTime()
+	}
+
+	page, err := s.postUsecase.GetAllPosts(ctx, filter)
+	if err != nil {
+		return nil, postError(err, "failed to list posts")
+	}
+
+	resp := &postProto.ListPostsResponse{
+		Posts:      make([]*postProto.PostResponse, 0, len(page.Posts)),
+		NextCursor: page.NextCursor,
+	}
+	for _, post := range page.Posts {
+		resp.Posts = append(resp.Posts, toProtoPost(post))
+	}
+	return resp, nil
+}
+
+// postError converts a post use case error into a gRPC status. Errors
+// without a better code become Internal with action as their prefix.
+func postError(err error, action string) error {
+	switch {
+	case errors.Is(err, usecase.ErrUsersUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, usecase.ErrNotPostAuthor):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, usecase.ErrNotFound):
+		return status.Error(codes.NotFound, "post not found")
+	case errors.Is(err, usecase.ErrInvalidCursor), errors.Is(err, usecase.ErrInvalidCategory),
+		errors.Is(err, usecase.ErrInvalidTag), errors.Is(err, usecase.ErrTooManyPostIDs),
+		errors.Is(err, usecase.ErrInvalidPostFilter):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", action, err)
+	}
+}
+
+func toProtoPost(post *entity.Post) *postProto.PostResponse {
+	resp := &postProto.PostResponse{
 		Id:         int32(post.ID),
 		Title:      post.Title,
 		Content:    post.Content,
-		AuthorName: usernameResp.GetUsername(),
-	}, nil
+		AuthorName: post.Author,
+		UserId:     int32(post.UserID),
+		CreatedAt:  timestamppb.New(post.CreatedAt),
+		Score:      int32(post.Score),
+		Tags:       post.Tags,
+	}
+	if post.CategoryID != nil {
+		resp.CategoryId = int32(*post.CategoryID)
+	}
+	return resp
 }
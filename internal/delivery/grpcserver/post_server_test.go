@@ -2,17 +2,24 @@ package grpcserver_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/perfect1337/forum-service/internal/delivery/grpcserver"
 	"github.com/perfect1337/forum-service/internal/entity"
 	postProto "github.com/perfect1337/forum-service/internal/proto/post"
 	userProto "github.com/perfect1337/forum-service/internal/proto/user"
+	"github.com/perfect1337/forum-service/internal/repository"
+	"github.com/perfect1337/forum-service/internal/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -28,17 +35,25 @@ func (m *MockPostUsecase) GetPostByID(ctx context.Context, id int) (*entity.Post
 	return args.Get(0).(*entity.Post), args.Error(1)
 }
 
+func (m *MockPostUsecase) GetPostsByIDs(ctx context.Context, ids []int) ([]*entity.Post, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Post), args.Error(1)
+}
+
 func (m *MockPostUsecase) CreatePost(ctx context.Context, post *entity.Post) error {
 	args := m.Called(ctx, post)
 	return args.Error(0)
 }
 
-func (m *MockPostUsecase) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
-	args := m.Called(ctx)
+func (m *MockPostUsecase) GetAllPosts(ctx context.Context, filter entity.PostFilter) (*entity.PostPage, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*entity.Post), args.Error(1)
+	return args.Get(0).(*entity.PostPage), args.Error(1)
 }
 
 func (m *MockPostUsecase) DeletePost(ctx context.Context, postID, userID int) error {
@@ -46,6 +61,48 @@ func (m *MockPostUsecase) DeletePost(ctx context.Context, postID, userID int) er
 	return args.Error(0)
 }
 
+func (m *MockPostUsecase) VotePost(ctx context.Context, postID, userID, value int) (*entity.VoteResult, error) {
+	args := m.Called(ctx, postID, userID, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.VoteResult), args.Error(1)
+}
+
+func (m *MockPostUsecase) GetPostRevisions(ctx context.Context, postID int) ([]*entity.PostRevision, error) {
+	args := m.Called(ctx, postID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.PostRevision), args.Error(1)
+}
+
+func (m *MockPostUsecase) GetPostRevision(ctx context.Context, postID, revision int) (*entity.PostRevision, error) {
+	args := m.Called(ctx, postID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PostRevision), args.Error(1)
+}
+
+func (m *MockPostUsecase) DiffPostRevisions(ctx context.Context, postID, from, to int) (*entity.RevisionDiff, error) {
+	args := m.Called(ctx, postID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RevisionDiff), args.Error(1)
+}
+
+func (m *MockPostUsecase) RestorePostRevision(ctx context.Context, postID, revision, userID int) error {
+	args := m.Called(ctx, postID, revision, userID)
+	return args.Error(0)
+}
+
+func (m *MockPostUsecase) UpdatePostTaxonomy(ctx context.Context, postID, userID int, categoryID *int, tags []string) error {
+	args := m.Called(ctx, postID, userID, categoryID, tags)
+	return args.Error(0)
+}
+
 type MockUserClient struct {
 	mock.Mock
 }
@@ -62,6 +119,14 @@ func (m *MockUserClient) GetUsername(ctx context.Context, in *userProto.UserRequ
 	return args.Get(0).(*userProto.UserResponse), args.Error(1)
 }
 
+func (m *MockUserClient) GetUsers(ctx context.Context, in *userProto.UsersRequest, opts ...grpc.CallOption) (*userProto.UsersResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userProto.UsersResponse), args.Error(1)
+}
+
 func TestPostServer_GetPostWithAuthor(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -81,7 +146,8 @@ func TestPostServer_GetPostWithAuthor(t *testing.T) {
 						ID:      1,
 						Title:   "Test Post",
 						Content: "Test Content",
-						Author:  "123",
+						UserID:  123,
+						Author:  "olduser",
 					}, nil)
 			},
 			mockUserSetup: func(m *MockUserClient) {
@@ -93,6 +159,7 @@ func TestPostServer_GetPostWithAuthor(t *testing.T) {
 				Title:      "Test Post",
 				Content:    "Test Content",
 				AuthorName: "testuser",
+				UserId:     123,
 			},
 		},
 		{
@@ -100,27 +167,22 @@ func TestPostServer_GetPostWithAuthor(t *testing.T) {
 			req:  &postProto.PostRequest{PostId: 2},
 			mockPostSetup: func(m *MockPostUsecase) {
 				m.On("GetPostByID", mock.Anything, 2).
-					Return(nil, errors.New("post not found"))
+					Return(nil, fmt.Errorf("failed to get post by ID: %w", sql.ErrNoRows))
 			},
 			mockUserSetup:  func(m *MockUserClient) {},
-			expectedErr:    status.Error(codes.NotFound, "post not found: post not found"),
-			expectedErrMsg: "post not found: post not found",
+			expectedErr:    status.Error(codes.NotFound, "post not found"),
+			expectedErrMsg: "post not found",
 		},
 		{
-			name: "InvalidAuthorIDFormat",
+			name: "RepositoryError",
 			req:  &postProto.PostRequest{PostId: 3},
 			mockPostSetup: func(m *MockPostUsecase) {
 				m.On("GetPostByID", mock.Anything, 3).
-					Return(&entity.Post{
-						ID:      3,
-						Title:   "Invalid Author",
-						Content: "Test Content",
-						Author:  "invalid",
-					}, nil)
+					Return(nil, errors.New("connection refused"))
 			},
 			mockUserSetup:  func(m *MockUserClient) {},
-			expectedErr:    status.Error(codes.InvalidArgument, "invalid author ID format: strconv.Atoi: parsing \"invalid\": invalid syntax"),
-			expectedErrMsg: "invalid author ID format",
+			expectedErr:    status.Error(codes.Internal, "failed to get post: connection refused"),
+			expectedErrMsg: "failed to get post",
 		},
 		{
 			name: "UserServiceError",
@@ -131,7 +193,8 @@ func TestPostServer_GetPostWithAuthor(t *testing.T) {
 						ID:      4,
 						Title:   "Test Post",
 						Content: "Test Content",
-						Author:  "456",
+						UserID:  456,
+						Author:  "bob",
 					}, nil)
 			},
 			mockUserSetup: func(m *MockUserClient) {
@@ -141,6 +204,24 @@ func TestPostServer_GetPostWithAuthor(t *testing.T) {
 			expectedErr:    status.Error(codes.Internal, "failed to get username: user service unavailable"),
 			expectedErrMsg: "failed to get username",
 		},
+		{
+			name: "UserServiceDown",
+			req:  &postProto.PostRequest{PostId: 5},
+			mockPostSetup: func(m *MockPostUsecase) {
+				m.On("GetPostByID", mock.Anything, 5).
+					Return(&entity.Post{ID: 5, Title: "Test Post", UserID: 456}, nil)
+			},
+			mockUserSetup: func(m *MockUserClient) {
+				m.On("GetUsername", mock.Anything, &userProto.UserRequest{UserId: 456}).
+					Return(nil, repository.ErrCircuitOpen)
+			},
+			expectedResp: &postProto.PostResponse{
+				Id:         5,
+				Title:      "Test Post",
+				AuthorName: usecase.PlaceholderAuthorName,
+				UserId:     456,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -165,7 +246,11 @@ func TestPostServer_GetPostWithAuthor(t *testing.T) {
 				assert.Equal(t, tt.expectedErr.(interface{ GRPCStatus() *status.Status }).GRPCStatus().Code(), statusErr.Code())
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedResp, resp)
+				assert.Equal(t, tt.expectedResp.GetId(), resp.GetId())
+				assert.Equal(t, tt.expectedResp.GetTitle(), resp.GetTitle())
+				assert.Equal(t, tt.expectedResp.GetContent(), resp.GetContent())
+				assert.Equal(t, tt.expectedResp.GetAuthorName(), resp.GetAuthorName())
+				assert.Equal(t, tt.expectedResp.GetUserId(), resp.GetUserId())
 			}
 
 			postUsecase.AssertExpectations(t)
@@ -173,3 +258,239 @@ func TestPostServer_GetPostWithAuthor(t *testing.T) {
 		})
 	}
 }
+
+func TestPostServer_GetPostWithAuthorFromDirectory(t *testing.T) {
+	postUsecase := new(MockPostUsecase)
+	postUsecase.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, UserID: 123}, nil)
+	postUsecase.On("GetPostByID", mock.Anything, 2).Return(&entity.Post{ID: 2, UserID: 456}, nil)
+	postUsecase.On("GetPostByID", mock.Anything, 3).Return(&entity.Post{ID: 3, UserID: 789}, nil)
+	postUsecase.On("GetPostByID", mock.Anything, 4).Return(&entity.Post{ID: 4, UserID: 789, Author: "local"}, nil)
+	userClient := new(MockUserClient)
+	userClient.On("GetUsers", mock.Anything, &userProto.UsersRequest{UserIds: []int32{123}}).
+		Return(&userProto.UsersResponse{Users: []*userProto.User{{Id: 123, Username: "testuser"}}}, nil).Once()
+	userClient.On("GetUsers", mock.Anything, &userProto.UsersRequest{UserIds: []int32{456}}).
+		Return(&userProto.UsersResponse{}, nil)
+	userClient.On("GetUsers", mock.Anything, &userProto.UsersRequest{UserIds: []int32{789}}).
+		Return(nil, status.Error(codes.Unavailable, "connection refused"))
+	server := grpcserver.NewPostServerWithUsers(postUsecase, nil,
+		repository.NewRemoteUsers(userClient, repository.RemoteUserOptions{TTL: time.Minute}))
+
+	t.Run("Имя берётся из кэша после первого запроса", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			resp, err := server.GetPostWithAuthor(context.Background(), &postProto.PostRequest{PostId: 1})
+			require.NoError(t, err)
+			assert.Equal(t, "testuser", resp.GetAuthorName())
+		}
+		userClient.AssertNumberOfCalls(t, "GetUsers", 1)
+	})
+
+	t.Run("Автор неизвестен auth-service", func(t *testing.T) {
+		_, err := server.GetPostWithAuthor(context.Background(), &postProto.PostRequest{PostId: 2})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("auth-service недоступен — подставляется имя-заглушка", func(t *testing.T) {
+		resp, err := server.GetPostWithAuthor(context.Background(), &postProto.PostRequest{PostId: 3})
+		require.NoError(t, err)
+		assert.Equal(t, usecase.PlaceholderAuthorName, resp.GetAuthorName())
+	})
+
+	t.Run("auth-service недоступен — берётся локальное имя", func(t *testing.T) {
+		resp, err := server.GetPostWithAuthor(context.Background(), &postProto.PostRequest{PostId: 4})
+		require.NoError(t, err)
+		assert.Equal(t, "local", resp.GetAuthorName())
+	})
+}
+
+func TestPostServer_ListPosts(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		postUsecase := new(MockPostUsecase)
+		createdAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+		postUsecase.On("GetAllPosts", mock.Anything, entity.PostFilter{Limit: 2, Cursor: "c1", AuthorID: 5}).
+			Return(&entity.PostPage{
+				Posts: []*entity.Post{
+					{ID: 1, Title: "Post", Content: "Body", UserID: 5, Author: "bob", CreatedAt: createdAt},
+				},
+				NextCursor: "c2",
+			}, nil)
+
+		server := grpcserver.NewPostServer(postUsecase, nil)
+		resp, err := server.ListPosts(context.Background(), &postProto.ListPostsRequest{Limit: 2, Cursor: "c1", AuthorId: 5})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "c2", resp.GetNextCursor())
+		assert.Len(t, resp.GetPosts(), 1)
+		assert.Equal(t, "bob", resp.GetPosts()[0].GetAuthorName())
+		assert.Equal(t, createdAt, resp.GetPosts()[0].GetCreatedAt().AsTime())
+		postUsecase.AssertExpectations(t)
+	})
+
+	t.Run("CategoryAndTag", func(t *testing.T) {
+		postUsecase := new(MockPostUsecase)
+		categoryID := 3
+		postUsecase.On("GetAllPosts", mock.Anything, entity.PostFilter{Category: "golang", Tag: "help"}).
+			Return(&entity.PostPage{
+				Posts: []*entity.Post{{ID: 1, CategoryID: &categoryID, Tags: []string{"go", "help"}}},
+			}, nil)
+
+		server := grpcserver.NewPostServer(postUsecase, nil)
+		resp, err := server.ListPosts(context.Background(), &postProto.ListPostsRequest{Category: "golang", Tag: "help"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), resp.GetPosts()[0].GetCategoryId())
+		assert.Equal(t, []string{"go", "help"}, resp.GetPosts()[0].GetTags())
+		postUsecase.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		postUsecase := new(MockPostUsecase)
+		postUsecase.On("GetAllPosts", mock.Anything, mock.Anything).Return(nil, usecase.ErrInvalidCursor)
+
+		server := grpcserver.NewPostServer(postUsecase, nil)
+		_, err := server.ListPosts(context.Background(), &postProto.ListPostsRequest{Cursor: "bad"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("InvalidSort", func(t *testing.T) {
+		postUsecase := new(MockPostUsecase)
+		postUsecase.On("GetAllPosts", mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("%w: invalid sort \"best\"", usecase.ErrInvalidPostFilter))
+
+		server := grpcserver.NewPostServer(postUsecase, nil)
+		_, err := server.ListPosts(context.Background(), &postProto.ListPostsRequest{Sort: "best"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+type fakeTokenParser map[string]int64
+
+func (f fakeTokenParser) ParseTokenClaims(tokenString string) (int64, string, time.Time, error) {
+	userID, ok := f[tokenString]
+	if !ok {
+		return 0, "", time.Time{}, errors.New("invalid token")
+	}
+	return userID, fmt.Sprintf("user%d", userID), time.Time{}, nil
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func newAuthPostServer(postUsecase *MockPostUsecase) *grpcserver.PostServer {
+	return grpcserver.NewPostServerWithAuth(postUsecase, fakeTokenParser{"alice": 1, "bob": 2}, nil)
+}
+
+func TestPostServer_CreatePost(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		postUsecase := new(MockPostUsecase)
+		postUsecase.On("CreatePost", mock.Anything, mock.MatchedBy(func(p *entity.Post) bool {
+			return p.UserID == 1 && p.Author == "user1" && p.CategoryID != nil && *p.CategoryID == 3
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*entity.Post).ID = 10
+		}).Return(nil)
+
+		resp, err := newAuthPostServer(postUsecase).CreatePost(withToken("alice"), &postProto.CreatePostRequest{
+			Title: "Hello", Content: "World", CategoryId: 3, Tags: []string{"go"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(10), resp.GetId())
+		assert.Equal(t, "user1", resp.GetAuthorName())
+		postUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		server := newAuthPostServer(new(MockPostUsecase))
+		req := &postProto.CreatePostRequest{Title: "Hello", Content: "World"}
+
+		_, err := server.CreatePost(context.Background(), req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		_, err = server.CreatePost(withToken("mallory"), req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		// Без парсера токенов методы записи закрыты
+		_, err = grpcserver.NewPostServer(new(MockPostUsecase), nil).CreatePost(withToken("alice"), req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("InvalidArgument", func(t *testing.T) {
+		postUsecase := new(MockPostUsecase)
+		postUsecase.On("CreatePost", mock.Anything, mock.Anything).
+			Return(fmt.Errorf("%w: at most 5 tags per post", usecase.ErrInvalidTag))
+		server := newAuthPostServer(postUsecase)
+
+		_, err := server.CreatePost(withToken("alice"), &postProto.CreatePostRequest{Title: "Hello"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = server.CreatePost(withToken("alice"), &postProto.CreatePostRequest{Title: "Hello", Content: "World"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestPostServer_UpdatePost(t *testing.T) {
+	t.Run("TextAndTaxonomy", func(t *testing.T) {
+		postUsecase := new(MockPostUsecase)
+		postUsecase.On("UpdatePost", mock.Anything, 5, 1, "New", "").Return(nil)
+		categoryID := 0
+		postUsecase.On("UpdatePostTaxonomy", mock.Anything, 5, 1, &categoryID, []string{}).Return(nil)
+		postUsecase.On("GetPostByID", mock.Anything, 5).Return(&entity.Post{ID: 5, Title: "New", UserID: 1}, nil)
+
+		resp, err := newAuthPostServer(postUsecase).UpdatePost(withToken("alice"), &postProto.UpdatePostRequest{
+			PostId: 5, Title: "New", Taxonomy: &postProto.PostTaxonomy{},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "New", resp.GetTitle())
+		postUsecase.AssertExpectations(t)
+	})
+
+	t.Run("NotTheAuthor", func(t *testing.T) {
+		postUsecase := new(MockPostUsecase)
+		postUsecase.On("UpdatePost", mock.Anything, 5, 2, "New", "").
+			Return(fmt.Errorf("%w: you can only update your own posts", usecase.ErrNotPostAuthor))
+
+		_, err := newAuthPostServer(postUsecase).UpdatePost(withToken("bob"), &postProto.UpdatePostRequest{PostId: 5, Title: "New"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		postUsecase.AssertNotCalled(t, "UpdatePostTaxonomy", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NothingToUpdate", func(t *testing.T) {
+		_, err := newAuthPostServer(new(MockPostUsecase)).UpdatePost(withToken("alice"), &postProto.UpdatePostRequest{PostId: 5})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestPostServer_DeletePost(t *testing.T) {
+	postUsecase := new(MockPostUsecase)
+	postUsecase.On("DeletePost", mock.Anything, 5, 1).Return(nil)
+	postUsecase.On("DeletePost", mock.Anything, 6, 1).Return(fmt.Errorf("failed to get post by ID: %w", sql.ErrNoRows))
+	postUsecase.On("DeletePost", mock.Anything, 7, 1).
+		Return(fmt.Errorf("%w: you can only delete your own posts", usecase.ErrNotPostAuthor))
+	server := newAuthPostServer(postUsecase)
+
+	_, err := server.DeletePost(withToken("alice"), &postProto.PostRequest{PostId: 5})
+	assert.NoError(t, err)
+	_, err = server.DeletePost(withToken("alice"), &postProto.PostRequest{PostId: 6})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.DeletePost(withToken("alice"), &postProto.PostRequest{PostId: 7})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestPostServer_GetPosts(t *testing.T) {
+	postUsecase := new(MockPostUsecase)
+	postUsecase.On("GetPostsByIDs", mock.Anything, []int{3, 1, 2, 3}).
+		Return([]*entity.Post{{ID: 3, Title: "Three"}, {ID: 1, Title: "One"}}, nil)
+
+	resp, err := grpcserver.NewPostServer(postUsecase, nil).GetPosts(context.Background(), &postProto.GetPostsRequest{
+		PostIds: []int32{3, 1, 2, 3},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.GetPosts(), 2)
+	assert.Equal(t, "Three", resp.GetPosts()[0].GetTitle())
+	assert.Equal(t, []int32{2}, resp.GetMissingIds())
+
+	postUsecase.On("GetPostsByIDs", mock.Anything, mock.Anything).Return(nil, usecase.ErrTooManyPostIDs)
+	_, err = grpcserver.NewPostServer(postUsecase, nil).GetPosts(context.Background(), &postProto.GetPostsRequest{PostIds: []int32{1}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
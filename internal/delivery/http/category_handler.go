@@ -0,0 +1,168 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+)
+
+type CategoryHandler struct {
+	categoryUC usecase.CategoryUseCaseInterface
+}
+
+func NewCategoryHandler(categoryUC usecase.CategoryUseCaseInterface) *CategoryHandler {
+	return &CategoryHandler{categoryUC: categoryUC}
+}
+
+// GetCategories godoc
+// @Summary List categories
+// @Description All post categories, ordered by name
+// @Tags categories
+// @Produce json
+// @Success 200 {array} entity.Category
+// @Failure 500 {object} docs.Error
+// @Router /categories [get]
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	categories, err := h.categoryUC.GetCategories(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// CreateCategory godoc
+// @Summary Create a category
+// @Description Add a post category. Admin only.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param category body entity.Category true "Category" SchemaExample({"slug":"golang","name":"Go","description":"Questions about Go"})
+// @Success 201 {object} entity.Category
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 409 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var category entity.Category
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.categoryUC.CreateCategory(c.Request.Context(), userID.(int), &category); err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory godoc
+// @Summary Update a category
+// @Description Rename a category or change its slug or description. Admin only.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category ID"
+// @Param category body entity.Category true "Category"
+// @Success 200 {object} entity.Category
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 409 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var category entity.Category
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	category.ID = id
+
+	if err := h.categoryUC.UpdateCategory(c.Request.Context(), userID.(int), &category); err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Remove a category. Its posts stay and become uncategorized. Admin only.
+// @Tags categories
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	if err := h.categoryUC.DeleteCategory(c.Request.Context(), userID.(int), id); err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category deleted successfully"})
+}
+
+func writeCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+	case errors.Is(err, usecase.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "category slug already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,114 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCategoryUseCase struct {
+	mock.Mock
+}
+
+func (m *MockCategoryUseCase) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Category), args.Error(1)
+}
+
+func (m *MockCategoryUseCase) CreateCategory(ctx context.Context, adminID int, category *entity.Category) error {
+	return m.Called(ctx, adminID, category).Error(0)
+}
+
+func (m *MockCategoryUseCase) UpdateCategory(ctx context.Context, adminID int, category *entity.Category) error {
+	return m.Called(ctx, adminID, category).Error(0)
+}
+
+func (m *MockCategoryUseCase) DeleteCategory(ctx context.Context, adminID, id int) error {
+	return m.Called(ctx, adminID, id).Error(0)
+}
+
+func TestCategoryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		mockSetup      func(*MockCategoryUseCase)
+		expectedStatus int
+	}{
+		{
+			name:   "List",
+			method: "GET",
+			url:    "/categories",
+			mockSetup: func(m *MockCategoryUseCase) {
+				m.On("GetCategories", mock.Anything).Return([]entity.Category{{ID: 1, Slug: "golang", Name: "Go"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Create",
+			method: "POST",
+			url:    "/admin/categories",
+			body:   `{"slug":"golang","name":"Go"}`,
+			mockSetup: func(m *MockCategoryUseCase) {
+				m.On("CreateCategory", mock.Anything, 1, &entity.Category{Slug: "golang", Name: "Go"}).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "CreateDuplicate",
+			method: "POST",
+			url:    "/admin/categories",
+			body:   `{"slug":"golang","name":"Go"}`,
+			mockSetup: func(m *MockCategoryUseCase) {
+				m.On("CreateCategory", mock.Anything, 1, mock.Anything).Return(usecase.ErrConflict)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "DeleteForbidden",
+			method: "DELETE",
+			url:    "/admin/categories/4",
+			mockSetup: func(m *MockCategoryUseCase) {
+				m.On("DeleteCategory", mock.Anything, 1, 4).Return(usecase.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCategoryUC := new(MockCategoryUseCase)
+			tt.mockSetup(mockCategoryUC)
+
+			handler := NewCategoryHandler(mockCategoryUC)
+			router := gin.New()
+			router.GET("/categories", handler.GetCategories)
+			admin := router.Group("/admin", func(c *gin.Context) { c.Set("user_id", 1) })
+			admin.POST("/categories", handler.CreateCategory)
+			admin.PUT("/categories/:id", handler.UpdateCategory)
+			admin.DELETE("/categories/:id", handler.DeleteCategory)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockCategoryUC.AssertExpectations(t)
+		})
+	}
+}
@@ -2,7 +2,11 @@
 package delivery
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -10,38 +14,129 @@ import (
 	"github.com/perfect1337/forum-service/internal/usecase"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-}
-
 type ChatHandler struct {
-	chatUC usecase.ChatUseCaseInterface
+	chatUC   usecase.ChatUseCaseInterface
+	upgrader websocket.Upgrader
 }
 
+// NewChatHandler accepts WebSocket upgrades from the service's own origin
+// only.
 func NewChatHandler(chatUC usecase.ChatUseCaseInterface) *ChatHandler {
-	return &ChatHandler{chatUC: chatUC}
+	return NewChatHandlerWithOrigins(chatUC, nil)
+}
+
+// NewChatHandlerWithOrigins also accepts upgrades from allowedOrigins, which
+// should be the CORS allow-list. The socket can be authenticated by the
+// access_token cookie, so an unchecked Origin would let any site open it on
+// behalf of a logged-in user.
+func NewChatHandlerWithOrigins(chatUC usecase.ChatUseCaseInterface, allowedOrigins []string) *ChatHandler {
+	h := &ChatHandler{
+		chatUC: chatUC,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+	if len(allowedOrigins) > 0 {
+		h.upgrader.CheckOrigin = checkOrigin(allowedOrigins)
+	}
+	return h
+}
+
+// checkOrigin allows requests without Origin (non-browser clients), from the
+// request's own host and from the allow-list.
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || origin == "http://"+r.Host || origin == "https://"+r.Host {
+			return true
+		}
+		for _, allowed := range allowedOrigins {
+			if strings.EqualFold(origin, allowed) {
+				return true
+			}
+		}
+		return false
+	}
 }
 
 // HandleWebSocket godoc
 // @Summary WebSocket connection
-// @Description Establish WebSocket connection for real-time chat
+// @Description Establish WebSocket connection for real-time chat. Authenticate with a Bearer token,
+// @Description the access_token cookie or a one-time ticket from POST /chat/ws/ticket.
+// @Description The token query parameter is not accepted; browsers from other origins are rejected.
 // @Tags chat
 // @Accept json
 // @Produce json
+// @Param ticket query string false "One-time ticket"
+// @Param last_seen_id query int false "Replay default-room messages after this ID before live delivery"
 // @Success 101 "Switching protocols to WebSocket"
 // @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 "Origin not allowed"
 // @Router /chat/ws [get]
 func (h *ChatHandler) HandleWebSocket(c *gin.Context) {
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	// Токен в URL попадает в логи и историю; для браузера есть ticket
+	if c.Query("token") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token query parameter is not supported, use a ticket"})
+		return
+	}
+	identity, err := h.authenticateWebSocket(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": "unauthorized"})
+		return
+	}
+	lastSeenID, err := strconv.Atoi(c.DefaultQuery("last_seen_id", "0"))
+	if err != nil || lastSeenID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last_seen_id"})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to upgrade connection"})
 		return
 	}
-	h.chatUC.HandleWebSocket(conn)
+	h.chatUC.HandleWebSocket(conn, *identity, lastSeenID)
+}
+
+// authenticateWebSocket identifies the user before the upgrade, preferring
+// a one-time ticket over a token from the header or cookie.
+func (h *ChatHandler) authenticateWebSocket(c *gin.Context) (*usecase.ChatIdentity, error) {
+	if ticket := c.Query("ticket"); ticket != "" {
+		return h.chatUC.RedeemTicket(ticket)
+	}
+	return h.chatUC.Authenticate(extractToken(c))
+}
+
+// IssueWebSocketTicket godoc
+// @Summary Issue WebSocket ticket
+// @Description Get a short-lived one-time ticket to open /chat/ws?ticket=... without sending the token in the URL
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} map[string]interface{}
+// @Failure 401 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /chat/ws/ticket [post]
+func (h *ChatHandler) IssueWebSocketTicket(c *gin.Context) {
+	// Токен уже проверен AuthMiddleware; разбираем его ещё раз ради срока действия
+	identity, err := h.chatUC.Authenticate(extractToken(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": "unauthorized"})
+		return
+	}
+
+	ticket, expiresAt, err := h.chatUC.IssueTicket(*identity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"ticket":     ticket,
+		"expires_at": expiresAt,
+	})
 }
 
 // SendMessage godoc
@@ -52,10 +147,13 @@ func (h *ChatHandler) HandleWebSocket(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
-// @Param message body object true "Message object" SchemaExample({"text":"Hello, world!"})
+// @Param message body object true "Message object" SchemaExample({"text":"Hello, world!","room_id":1})
 // @Success 201 {object} entity.ChatMessage
 // @Failure 400 {object} docs.Error "Invalid request format"
 // @Failure 401 {object} docs.Error "Missing or invalid authentication token"
+// @Failure 403 {object} docs.Error "Not a member of the room, muted or banned"
+// @Failure 404 {object} docs.Error "Room not found"
+// @Failure 429 {object} docs.Error "Slow mode or flood limit"
 // @Failure 500 {object} docs.Error "Server error"
 // @Router /chat/messages [post]
 func (h *ChatHandler) SendMessage(c *gin.Context) {
@@ -78,7 +176,8 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	}
 
 	var request struct {
-		Text string `json:"text" binding:"required"`
+		Text   string `json:"text" binding:"required"`
+		RoomID int    `json:"room_id"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -90,12 +189,24 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	}
 
 	message := &entity.ChatMessage{
+		RoomID: request.RoomID,
 		UserID: userID.(int),
 		Author: username.(string),
 		Text:   request.Text,
 	}
 
 	if err := h.chatUC.SendMessage(c.Request.Context(), message); err != nil {
+		if writeChatRestrictionError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrNotRoomMember) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "not_room_member"})
+			return
+		}
+		if errors.Is(err, usecase.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "room not found", "code": "room_not_found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to save message",
 			"details": err.Error(),
@@ -106,6 +217,7 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{
 		"id":         message.ID,
+		"room_id":    message.RoomID,
 		"user_id":    message.UserID,
 		"author":     message.Author,
 		"text":       message.Text,
@@ -113,22 +225,296 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	})
 }
 
+// EditMessage godoc
+// @Summary Edit chat message
+// @Description Change the text of your own room message. Only possible for a limited time after sending.
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Message ID"
+// @Param message body object true "New text" SchemaExample({"text":"Hello, world!"})
+// @Success 200 {object} entity.ChatMessage
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error "Not the author, or the edit window has passed"
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /chat/messages/{id} [patch]
+func (h *ChatHandler) EditMessage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	messageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || messageID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message ID"})
+		return
+	}
+
+	var request struct {
+		Text string `json:"text" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := h.chatUC.EditMessage(c.Request.Context(), messageID, userID.(int), request.Text)
+	if err != nil {
+		writeChatMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// DeleteMessage godoc
+// @Summary Delete chat message
+// @Description Delete your own room message within the edit window. Admins can delete any message.
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Message ID"
+// @Success 204
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /chat/messages/{id} [delete]
+func (h *ChatHandler) DeleteMessage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	messageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || messageID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message ID"})
+		return
+	}
+
+	if err := h.chatUC.DeleteMessage(c.Request.Context(), messageID, userID.(int)); err != nil {
+		writeChatMessageError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeChatMessageError(c *gin.Context, err error) {
+	if writeChatRestrictionError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrEmptyChatMessage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotMessageAuthor), errors.Is(err, usecase.ErrEditWindowClosed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "message not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // GetMessages godoc
 // @Summary Get chat messages
-// @Description Retrieve chat messages
+// @Description Retrieve messages of the default room. Without cursors the newest messages come first;
+// @Description "before" pages back in time, "after" pages forward and returns the oldest first.
 // @Tags chat
 // @Accept json
 // @Produce json
+// @Param before query int false "Only messages with a smaller ID"
+// @Param after query int false "Only messages with a greater ID"
+// @Param limit query int false "Page size (default 100, max 500)"
 // @Success 200 {array} entity.ChatMessage
+// @Failure 400 {object} docs.Error
 // @Failure 500 {object} docs.Error
 // @Router /chat/messages [get]
-
 func (h *ChatHandler) GetMessages(c *gin.Context) {
-	messages, err := h.chatUC.GetMessages(c.Request.Context(), 100)
+	filter, err := parseChatHistoryFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.RoomID = entity.DefaultChatRoomID
+
+	messages, err := h.chatUC.GetMessages(c.Request.Context(), filter)
+	if err != nil {
+		writeChatRoomError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, messages)
 }
+
+func parseChatHistoryFilter(c *gin.Context) (entity.ChatHistoryFilter, error) {
+	var filter entity.ChatHistoryFilter
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"before", &filter.BeforeID},
+		{"after", &filter.AfterID},
+		{"limit", &filter.Limit},
+	} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return filter, fmt.Errorf("invalid %s", p.name)
+		}
+		*p.dst = v
+	}
+	return filter, nil
+}
+
+// GetRoomMessages godoc
+// @Summary Get room messages
+// @Description Retrieve messages of a chat room; paging works as in GET /chat/messages
+// @Tags chat
+// @Produce json
+// @Param id path int true "Room ID"
+// @Param before query int false "Only messages with a smaller ID"
+// @Param after query int false "Only messages with a greater ID"
+// @Param limit query int false "Page size (default 100, max 500)"
+// @Success 200 {array} entity.ChatMessage
+// @Failure 400 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /chat/rooms/{id}/messages [get]
+func (h *ChatHandler) GetRoomMessages(c *gin.Context) {
+	roomID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || roomID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room ID"})
+		return
+	}
+
+	filter, err := parseChatHistoryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.RoomID = roomID
+
+	messages, err := h.chatUC.GetMessages(c.Request.Context(), filter)
+	if err != nil {
+		writeChatRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// GetRooms godoc
+// @Summary List chat rooms
+// @Description List all chat rooms with their member counts
+// @Tags chat
+// @Produce json
+// @Success 200 {array} entity.ChatRoom
+// @Failure 500 {object} docs.Error
+// @Router /chat/rooms [get]
+func (h *ChatHandler) GetRooms(c *gin.Context) {
+	rooms, err := h.chatUC.GetRooms(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rooms)
+}
+
+// CreateRoom godoc
+// @Summary Create chat room
+// @Description Create a named chat room. The creator joins it automatically.
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param room body object true "Room object" SchemaExample({"name":"golang"})
+// @Success 201 {object} entity.ChatRoom
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 409 {object} docs.Error "Room name already taken"
+// @Failure 500 {object} docs.Error
+// @Router /chat/rooms [post]
+func (h *ChatHandler) CreateRoom(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var request struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room := &entity.ChatRoom{
+		Name:      request.Name,
+		CreatedBy: userID.(int),
+	}
+	if err := h.chatUC.CreateRoom(c.Request.Context(), room); err != nil {
+		writeChatRoomError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, room)
+}
+
+// JoinRoom godoc
+// @Summary Join chat room
+// @Description Become a member of a chat room so you can post to it
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Room ID"
+// @Success 204
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /chat/rooms/{id}/join [post]
+func (h *ChatHandler) JoinRoom(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	roomID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || roomID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room ID"})
+		return
+	}
+
+	if err := h.chatUC.JoinRoom(c.Request.Context(), roomID, userID.(int)); err != nil {
+		writeChatRoomError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeChatRoomError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidChatRoom), errors.Is(err, usecase.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+	case errors.Is(err, usecase.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "room name already taken"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
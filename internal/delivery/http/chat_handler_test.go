@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -17,18 +18,69 @@ import (
 	"github.com/perfect1337/forum-service/internal/usecase"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type MockChatUseCase struct {
-	HandleWebSocketFunc func(conn usecase.WebSocketConnection)
+	HandleWebSocketFunc func(conn usecase.WebSocketConnection, identity usecase.ChatIdentity, lastSeenID int)
+	AuthenticateFunc    func(token string) (*usecase.ChatIdentity, error)
+	IssueTicketFunc     func(identity usecase.ChatIdentity) (string, time.Time, error)
+	RedeemTicketFunc    func(ticket string) (*usecase.ChatIdentity, error)
 	SendMessageFunc     func(ctx context.Context, message *entity.ChatMessage) error
-	GetMessagesFunc     func(ctx context.Context, limit int) ([]entity.ChatMessage, error)
+	GetMessagesFunc     func(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error)
+	EditMessageFunc     func(ctx context.Context, messageID, userID int, text string) (*entity.ChatMessage, error)
+	DeleteMessageFunc   func(ctx context.Context, messageID, userID int) error
+	CreateRoomFunc      func(ctx context.Context, room *entity.ChatRoom) error
+	GetRoomsFunc        func(ctx context.Context) ([]entity.ChatRoom, error)
+	JoinRoomFunc        func(ctx context.Context, roomID, userID int) error
+
+	SendDirectMessageFunc    func(ctx context.Context, msg *entity.DirectMessage) error
+	GetConversationsFunc     func(ctx context.Context, userID int) (*entity.ConversationList, error)
+	GetDirectMessagesFunc    func(ctx context.Context, userID, peerID, beforeID, limit int) (*entity.DirectMessagePage, error)
+	MarkConversationReadFunc func(ctx context.Context, userID, peerID int) error
+	OpenEventStreamFunc      func(opts usecase.EventStreamOptions) (usecase.EventStream, error)
+	OpenChatSessionFunc      func(identity usecase.ChatIdentity, lastSeenID int) (usecase.ChatSession, error)
+}
+
+func (m *MockChatUseCase) OpenChatSession(identity usecase.ChatIdentity, lastSeenID int) (usecase.ChatSession, error) {
+	if m.OpenChatSessionFunc != nil {
+		return m.OpenChatSessionFunc(identity, lastSeenID)
+	}
+	return nil, usecase.ErrChatUnavailable
+}
+
+func (m *MockChatUseCase) OpenEventStream(opts usecase.EventStreamOptions) (usecase.EventStream, error) {
+	if m.OpenEventStreamFunc != nil {
+		return m.OpenEventStreamFunc(opts)
+	}
+	return nil, usecase.ErrChatUnavailable
 }
 
-func (m *MockChatUseCase) HandleWebSocket(conn usecase.WebSocketConnection) {
+func (m *MockChatUseCase) HandleWebSocket(conn usecase.WebSocketConnection, identity usecase.ChatIdentity, lastSeenID int) {
 	if m.HandleWebSocketFunc != nil {
-		m.HandleWebSocketFunc(conn)
+		m.HandleWebSocketFunc(conn, identity, lastSeenID)
+	}
+}
+
+func (m *MockChatUseCase) Authenticate(token string) (*usecase.ChatIdentity, error) {
+	if m.AuthenticateFunc != nil {
+		return m.AuthenticateFunc(token)
+	}
+	return nil, errors.New("token not provided")
+}
+
+func (m *MockChatUseCase) IssueTicket(identity usecase.ChatIdentity) (string, time.Time, error) {
+	if m.IssueTicketFunc != nil {
+		return m.IssueTicketFunc(identity)
 	}
+	return "", time.Time{}, nil
+}
+
+func (m *MockChatUseCase) RedeemTicket(ticket string) (*usecase.ChatIdentity, error) {
+	if m.RedeemTicketFunc != nil {
+		return m.RedeemTicketFunc(ticket)
+	}
+	return nil, usecase.ErrInvalidTicket
 }
 
 func (m *MockChatUseCase) SendMessage(ctx context.Context, message *entity.ChatMessage) error {
@@ -38,13 +90,76 @@ func (m *MockChatUseCase) SendMessage(ctx context.Context, message *entity.ChatM
 	return nil
 }
 
-func (m *MockChatUseCase) GetMessages(ctx context.Context, limit int) ([]entity.ChatMessage, error) {
+func (m *MockChatUseCase) GetMessages(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
 	if m.GetMessagesFunc != nil {
-		return m.GetMessagesFunc(ctx, limit)
+		return m.GetMessagesFunc(ctx, filter)
 	}
 	return nil, nil
 }
 
+func (m *MockChatUseCase) EditMessage(ctx context.Context, messageID, userID int, text string) (*entity.ChatMessage, error) {
+	if m.EditMessageFunc != nil {
+		return m.EditMessageFunc(ctx, messageID, userID, text)
+	}
+	return &entity.ChatMessage{ID: messageID, UserID: userID, Text: text}, nil
+}
+
+func (m *MockChatUseCase) DeleteMessage(ctx context.Context, messageID, userID int) error {
+	if m.DeleteMessageFunc != nil {
+		return m.DeleteMessageFunc(ctx, messageID, userID)
+	}
+	return nil
+}
+
+func (m *MockChatUseCase) CreateRoom(ctx context.Context, room *entity.ChatRoom) error {
+	if m.CreateRoomFunc != nil {
+		return m.CreateRoomFunc(ctx, room)
+	}
+	return nil
+}
+
+func (m *MockChatUseCase) GetRooms(ctx context.Context) ([]entity.ChatRoom, error) {
+	if m.GetRoomsFunc != nil {
+		return m.GetRoomsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockChatUseCase) JoinRoom(ctx context.Context, roomID, userID int) error {
+	if m.JoinRoomFunc != nil {
+		return m.JoinRoomFunc(ctx, roomID, userID)
+	}
+	return nil
+}
+
+func (m *MockChatUseCase) SendDirectMessage(ctx context.Context, msg *entity.DirectMessage) error {
+	if m.SendDirectMessageFunc != nil {
+		return m.SendDirectMessageFunc(ctx, msg)
+	}
+	return nil
+}
+
+func (m *MockChatUseCase) GetConversations(ctx context.Context, userID int) (*entity.ConversationList, error) {
+	if m.GetConversationsFunc != nil {
+		return m.GetConversationsFunc(ctx, userID)
+	}
+	return &entity.ConversationList{}, nil
+}
+
+func (m *MockChatUseCase) GetDirectMessages(ctx context.Context, userID, peerID, beforeID, limit int) (*entity.DirectMessagePage, error) {
+	if m.GetDirectMessagesFunc != nil {
+		return m.GetDirectMessagesFunc(ctx, userID, peerID, beforeID, limit)
+	}
+	return &entity.DirectMessagePage{}, nil
+}
+
+func (m *MockChatUseCase) MarkConversationRead(ctx context.Context, userID, peerID int) error {
+	if m.MarkConversationReadFunc != nil {
+		return m.MarkConversationReadFunc(ctx, userID, peerID)
+	}
+	return nil
+}
+
 type nopCloser struct {
 	io.Reader
 }
@@ -53,9 +168,16 @@ func (nopCloser) Close() error { return nil }
 
 func TestChatHandler_HandleWebSocket(t *testing.T) {
 	t.Run("successful connection", func(t *testing.T) {
+		got := make(chan usecase.ChatIdentity, 1)
 		mockUC := &MockChatUseCase{
-			HandleWebSocketFunc: func(conn usecase.WebSocketConnection) {
-				// Тестовая логика
+			AuthenticateFunc: func(token string) (*usecase.ChatIdentity, error) {
+				if token != "valid" {
+					return nil, errors.New("invalid token")
+				}
+				return &usecase.ChatIdentity{UserID: 1, Username: "alice"}, nil
+			},
+			HandleWebSocketFunc: func(conn usecase.WebSocketConnection, identity usecase.ChatIdentity, lastSeenID int) {
+				got <- identity
 			},
 		}
 
@@ -67,23 +189,145 @@ func TestChatHandler_HandleWebSocket(t *testing.T) {
 		}))
 		defer server.Close()
 
-		_, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+		header := http.Header{"Authorization": {"Bearer valid"}}
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], header)
 		assert.NoError(t, err)
+		conn.Close()
+		select {
+		case identity := <-got:
+			assert.Equal(t, 1, identity.UserID)
+		case <-time.After(time.Second):
+			t.Fatal("HandleWebSocket was not called")
+		}
 	})
 
-	t.Run("failed upgrade", func(t *testing.T) {
-		mockUC := &MockChatUseCase{}
+	t.Run("one-time ticket", func(t *testing.T) {
+		mockUC := &MockChatUseCase{
+			RedeemTicketFunc: func(ticket string) (*usecase.ChatIdentity, error) {
+				if ticket != "abc" {
+					return nil, usecase.ErrInvalidTicket
+				}
+				return &usecase.ChatIdentity{UserID: 1, Username: "alice"}, nil
+			},
+		}
+
 		handler := delivery.NewChatHandler(mockUC)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := gin.CreateTestContextOnly(w, &gin.Engine{})
+			ctx.Request = r
+			handler.HandleWebSocket(ctx)
+		}))
+		defer server.Close()
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:]+"?ticket=abc", nil)
+		assert.NoError(t, err)
+		conn.Close()
+
+		_, resp, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:]+"?ticket=used", nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("anonymous connection rejected", func(t *testing.T) {
+		handler := delivery.NewChatHandler(&MockChatUseCase{})
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("GET", "/ws", nil)
+
+		handler.HandleWebSocket(c)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("failed upgrade", func(t *testing.T) {
+		mockUC := &MockChatUseCase{
+			RedeemTicketFunc: func(ticket string) (*usecase.ChatIdentity, error) {
+				return &usecase.ChatIdentity{UserID: 1}, nil
+			},
+		}
+		handler := delivery.NewChatHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/ws?ticket=abc", nil)
 		c.Request.Header = nil // Break the request
 
 		handler.HandleWebSocket(c)
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
+
+func TestChatHandler_HandleWebSocket_Origin(t *testing.T) {
+	mockUC := &MockChatUseCase{
+		AuthenticateFunc: func(token string) (*usecase.ChatIdentity, error) {
+			return &usecase.ChatIdentity{UserID: 1, Username: "alice"}, nil
+		},
+		HandleWebSocketFunc: func(conn usecase.WebSocketConnection, identity usecase.ChatIdentity, lastSeenID int) {},
+	}
+	handler := delivery.NewChatHandlerWithOrigins(mockUC, []string{"http://localhost:3000"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := gin.CreateTestContextOnly(w, &gin.Engine{})
+		ctx.Request = r
+		handler.HandleWebSocket(ctx)
+	}))
+	defer server.Close()
+	url := "ws" + server.URL[4:]
+
+	tests := []struct {
+		name   string
+		origin string
+		ok     bool
+	}{
+		{name: "no origin", ok: true},
+		{name: "allowed origin", origin: "http://localhost:3000", ok: true},
+		{name: "same origin", origin: server.URL, ok: true},
+		{name: "foreign origin", origin: "https://evil.example", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{"Cookie": {"access_token=valid"}}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+			conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+			if tt.ok {
+				require.NoError(t, err)
+				conn.Close()
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+	}
+
+	t.Run("token in query rejected", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(url+"?token=valid", nil)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestChatHandler_IssueWebSocketTicket(t *testing.T) {
+	mockUC := &MockChatUseCase{
+		AuthenticateFunc: func(token string) (*usecase.ChatIdentity, error) {
+			return &usecase.ChatIdentity{UserID: 1, Username: "alice"}, nil
+		},
+		IssueTicketFunc: func(identity usecase.ChatIdentity) (string, time.Time, error) {
+			return "ticket-for-" + identity.Username, time.Now().Add(usecase.WebSocketTicketTTL), nil
+		},
+	}
+	handler := delivery.NewChatHandler(mockUC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/chat/ws/ticket", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid")
+
+	handler.IssueWebSocketTicket(c)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"ticket":"ticket-for-alice"`)
+}
+
 func TestChatHandler_SendMessage(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -133,6 +377,25 @@ func TestChatHandler_SendMessage(t *testing.T) {
 			requestBody:    `{"text": "Hello"}`,
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "not a room member",
+			setupContext: func(c *gin.Context) {
+				c.Set("user_id", 123)
+				c.Set("username", "testuser")
+			},
+			setupMock: func() *MockChatUseCase {
+				return &MockChatUseCase{
+					SendMessageFunc: func(ctx context.Context, message *entity.ChatMessage) error {
+						if message.RoomID != 2 {
+							return errors.New("unexpected room")
+						}
+						return usecase.ErrNotRoomMember
+					},
+				}
+			},
+			requestBody:    `{"text": "Hello", "room_id": 2}`,
+			expectedStatus: http.StatusForbidden,
+		},
 	}
 
 	for _, tt := range tests {
@@ -162,7 +425,7 @@ func TestChatHandler_GetMessages(t *testing.T) {
 		}
 
 		mockUC := &MockChatUseCase{
-			GetMessagesFunc: func(ctx context.Context, limit int) ([]entity.ChatMessage, error) {
+			GetMessagesFunc: func(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
 				return mockMessages, nil
 			},
 		}
@@ -178,7 +441,7 @@ func TestChatHandler_GetMessages(t *testing.T) {
 
 	t.Run("database error", func(t *testing.T) {
 		mockUC := &MockChatUseCase{
-			GetMessagesFunc: func(ctx context.Context, limit int) ([]entity.ChatMessage, error) {
+			GetMessagesFunc: func(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
 				return nil, errors.New("db error")
 			},
 		}
@@ -193,6 +456,195 @@ func TestChatHandler_GetMessages(t *testing.T) {
 	})
 }
 
+func TestChatHandler_Rooms(t *testing.T) {
+	newRouter := func(mockUC *MockChatUseCase) *gin.Engine {
+		handler := delivery.NewChatHandler(mockUC)
+		r := gin.New()
+		r.GET("/chat/rooms/:id/messages", handler.GetRoomMessages)
+		auth := r.Group("", func(c *gin.Context) { c.Set("user_id", 7) })
+		auth.POST("/chat/rooms", handler.CreateRoom)
+		auth.POST("/chat/rooms/:id/join", handler.JoinRoom)
+		return r
+	}
+
+	t.Run("room history", func(t *testing.T) {
+		var gotRoom int
+		r := newRouter(&MockChatUseCase{
+			GetMessagesFunc: func(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
+				gotRoom = filter.RoomID
+				return []entity.ChatMessage{{ID: 1, RoomID: filter.RoomID, Text: "hi"}}, nil
+			},
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/chat/rooms/3/messages", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 3, gotRoom)
+	})
+
+	t.Run("unknown room", func(t *testing.T) {
+		r := newRouter(&MockChatUseCase{
+			GetMessagesFunc: func(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
+				return nil, usecase.ErrNotFound
+			},
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/chat/rooms/3/messages", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("create room conflict", func(t *testing.T) {
+		r := newRouter(&MockChatUseCase{
+			CreateRoomFunc: func(ctx context.Context, room *entity.ChatRoom) error {
+				if room.CreatedBy != 7 {
+					return errors.New("unexpected creator")
+				}
+				return usecase.ErrConflict
+			},
+		})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/chat/rooms", strings.NewReader(`{"name":"golang"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("join room", func(t *testing.T) {
+		var gotRoom, gotUser int
+		r := newRouter(&MockChatUseCase{
+			JoinRoomFunc: func(ctx context.Context, roomID, userID int) error {
+				gotRoom, gotUser = roomID, userID
+				return nil
+			},
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/chat/rooms/3/join", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, 3, gotRoom)
+		assert.Equal(t, 7, gotUser)
+	})
+}
+
+func TestChatHandler_EditAndDeleteMessage(t *testing.T) {
+	newRouter := func(mockUC *MockChatUseCase) *gin.Engine {
+		handler := delivery.NewChatHandler(mockUC)
+		r := gin.New()
+		auth := r.Group("", func(c *gin.Context) { c.Set("user_id", 7) })
+		auth.PATCH("/chat/messages/:id", handler.EditMessage)
+		auth.DELETE("/chat/messages/:id", handler.DeleteMessage)
+		return r
+	}
+
+	t.Run("edit", func(t *testing.T) {
+		var gotID, gotUser int
+		r := newRouter(&MockChatUseCase{
+			EditMessageFunc: func(ctx context.Context, messageID, userID int, text string) (*entity.ChatMessage, error) {
+				gotID, gotUser = messageID, userID
+				return &entity.ChatMessage{ID: messageID, UserID: userID, Text: text}, nil
+			},
+		})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PATCH", "/chat/messages/5", strings.NewReader(`{"text":"fixed"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 5, gotID)
+		assert.Equal(t, 7, gotUser)
+		assert.Contains(t, w.Body.String(), `"text":"fixed"`)
+	})
+
+	t.Run("edit window closed", func(t *testing.T) {
+		r := newRouter(&MockChatUseCase{
+			EditMessageFunc: func(ctx context.Context, messageID, userID int, text string) (*entity.ChatMessage, error) {
+				return nil, usecase.ErrEditWindowClosed
+			},
+		})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PATCH", "/chat/messages/5", strings.NewReader(`{"text":"late"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		r := newRouter(&MockChatUseCase{})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("DELETE", "/chat/messages/5", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("delete someone else's", func(t *testing.T) {
+		r := newRouter(&MockChatUseCase{
+			DeleteMessageFunc: func(ctx context.Context, messageID, userID int) error {
+				return usecase.ErrNotMessageAuthor
+			},
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("DELETE", "/chat/messages/5", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unknown message", func(t *testing.T) {
+		r := newRouter(&MockChatUseCase{
+			DeleteMessageFunc: func(ctx context.Context, messageID, userID int) error {
+				return usecase.ErrNotFound
+			},
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("DELETE", "/chat/messages/5", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		r := newRouter(&MockChatUseCase{})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("DELETE", "/chat/messages/abc", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestChatHandler_GetMessagesCursors(t *testing.T) {
+	t.Run("cursors and limit are passed through", func(t *testing.T) {
+		var got entity.ChatHistoryFilter
+		handler := delivery.NewChatHandler(&MockChatUseCase{
+			GetMessagesFunc: func(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
+				got = filter
+				return []entity.ChatMessage{}, nil
+			},
+		})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/messages?before=50&after=10&limit=20", nil)
+
+		handler.GetMessages(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, entity.ChatHistoryFilter{RoomID: entity.DefaultChatRoomID, BeforeID: 50, AfterID: 10, Limit: 20}, got)
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		handler := delivery.NewChatHandler(&MockChatUseCase{})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/messages?after=abc", nil)
+
+		handler.GetMessages(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("inconsistent cursors", func(t *testing.T) {
+		handler := delivery.NewChatHandler(&MockChatUseCase{
+			GetMessagesFunc: func(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
+				return nil, usecase.ErrInvalidCursor
+			},
+		})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/messages?before=5&after=10", nil)
+
+		handler.GetMessages(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func TestNewChatHandler(t *testing.T) {
 	mockUC := &MockChatUseCase{}
 	handler := delivery.NewChatHandler(mockUC)
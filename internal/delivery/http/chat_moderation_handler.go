@@ -0,0 +1,201 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+)
+
+type ChatModerationHandler struct {
+	moderationUC usecase.ChatModerationUseCaseInterface
+}
+
+func NewChatModerationHandler(moderationUC usecase.ChatModerationUseCaseInterface) *ChatModerationHandler {
+	return &ChatModerationHandler{moderationUC: moderationUC}
+}
+
+// ListSanctions godoc
+// @Summary List chat sanctions
+// @Description Mutes and bans that are currently in force. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} entity.ChatSanction
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/chat/sanctions [get]
+func (h *ChatModerationHandler) ListSanctions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	sanctions, err := h.moderationUC.ListSanctions(c.Request.Context(), userID.(int))
+	if err != nil {
+		writeChatModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sanctions)
+}
+
+// Sanction godoc
+// @Summary Mute or ban a chat user
+// @Description Mute (can read, can't post) or ban (can't connect) a user. duration_seconds of 0 means until lifted.
+// @Description Sanctioning again replaces the previous sanction of the same kind. Admin only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sanction body object true "Sanction" SchemaExample({"user_id":42,"kind":"mute","reason":"spam","duration_seconds":3600})
+// @Success 201 {object} entity.ChatSanction
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/chat/sanctions [post]
+func (h *ChatModerationHandler) Sanction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var request struct {
+		UserID          int    `json:"user_id" binding:"required"`
+		Kind            string `json:"kind" binding:"required"`
+		Reason          string `json:"reason"`
+		DurationSeconds int    `json:"duration_seconds"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sanction := &entity.ChatSanction{
+		UserID: request.UserID,
+		Kind:   request.Kind,
+		Reason: request.Reason,
+	}
+	duration := time.Duration(request.DurationSeconds) * time.Second
+	if err := h.moderationUC.Sanction(c.Request.Context(), userID.(int), sanction, duration); err != nil {
+		writeChatModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sanction)
+}
+
+// LiftSanction godoc
+// @Summary Lift a chat sanction
+// @Description Remove a user's mute or ban before it expires. Admin only.
+// @Tags admin
+// @Security BearerAuth
+// @Param user_id path int true "User ID"
+// @Param kind path string true "mute or ban"
+// @Success 204
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/chat/sanctions/{user_id}/{kind} [delete]
+func (h *ChatModerationHandler) LiftSanction(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	targetID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil || targetID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.moderationUC.LiftSanction(c.Request.Context(), userID.(int), targetID, c.Param("kind")); err != nil {
+		writeChatModerationError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetSlowMode godoc
+// @Summary Set room slow mode
+// @Description Set how many seconds a user has to wait between two messages in a room; 0 turns slow mode off. Admin only.
+// @Tags admin
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "Room ID"
+// @Param slow_mode body object true "Interval" SchemaExample({"seconds":30})
+// @Success 204
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/chat/rooms/{id}/slow-mode [put]
+func (h *ChatModerationHandler) SetSlowMode(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	roomID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || roomID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room ID"})
+		return
+	}
+
+	var request struct {
+		Seconds *int `json:"seconds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interval := time.Duration(*request.Seconds) * time.Second
+	if err := h.moderationUC.SetSlowMode(c.Request.Context(), userID.(int), roomID, interval); err != nil {
+		writeChatModerationError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeChatModerationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidSanction), errors.Is(err, usecase.ErrInvalidChatRoom):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// writeChatRestrictionError answers with 403 or 429 when err says the user
+// may not post right now, and reports whether it did.
+func writeChatRestrictionError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, usecase.ErrChatMuted), errors.Is(err, usecase.ErrChatBanned):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "chat_restricted"})
+	case errors.Is(err, usecase.ErrSlowMode), errors.Is(err, usecase.ErrFloodLimit):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "code": "rate_limited"})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package delivery_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	delivery "github.com/perfect1337/forum-service/internal/delivery/http"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockChatModerationUseCase struct {
+	ListSanctionsFunc func(ctx context.Context, adminID int) ([]entity.ChatSanction, error)
+	SanctionFunc      func(ctx context.Context, adminID int, sanction *entity.ChatSanction, duration time.Duration) error
+	LiftSanctionFunc  func(ctx context.Context, adminID, userID int, kind string) error
+	SetSlowModeFunc   func(ctx context.Context, adminID, roomID int, interval time.Duration) error
+}
+
+func (m *MockChatModerationUseCase) ListSanctions(ctx context.Context, adminID int) ([]entity.ChatSanction, error) {
+	if m.ListSanctionsFunc != nil {
+		return m.ListSanctionsFunc(ctx, adminID)
+	}
+	return []entity.ChatSanction{}, nil
+}
+
+func (m *MockChatModerationUseCase) Sanction(ctx context.Context, adminID int, sanction *entity.ChatSanction, duration time.Duration) error {
+	if m.SanctionFunc != nil {
+		return m.SanctionFunc(ctx, adminID, sanction, duration)
+	}
+	return nil
+}
+
+func (m *MockChatModerationUseCase) LiftSanction(ctx context.Context, adminID, userID int, kind string) error {
+	if m.LiftSanctionFunc != nil {
+		return m.LiftSanctionFunc(ctx, adminID, userID, kind)
+	}
+	return nil
+}
+
+func (m *MockChatModerationUseCase) SetSlowMode(ctx context.Context, adminID, roomID int, interval time.Duration) error {
+	if m.SetSlowModeFunc != nil {
+		return m.SetSlowModeFunc(ctx, adminID, roomID, interval)
+	}
+	return nil
+}
+
+func newChatModerationRouter(mockUC *MockChatModerationUseCase) *gin.Engine {
+	handler := delivery.NewChatModerationHandler(mockUC)
+	r := gin.New()
+	admin := r.Group("/admin/chat", func(c *gin.Context) { c.Set("user_id", 1) })
+	admin.GET("/sanctions", handler.ListSanctions)
+	admin.POST("/sanctions", handler.Sanction)
+	admin.DELETE("/sanctions/:user_id/:kind", handler.LiftSanction)
+	admin.PUT("/rooms/:id/slow-mode", handler.SetSlowMode)
+	return r
+}
+
+func TestChatModerationHandler_Sanction(t *testing.T) {
+	t.Run("mute for an hour", func(t *testing.T) {
+		var gotAdmin int
+		var gotDuration time.Duration
+		var got *entity.ChatSanction
+		r := newChatModerationRouter(&MockChatModerationUseCase{
+			SanctionFunc: func(ctx context.Context, adminID int, sanction *entity.ChatSanction, duration time.Duration) error {
+				gotAdmin, got, gotDuration = adminID, sanction, duration
+				return nil
+			},
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/admin/chat/sanctions",
+			strings.NewReader(`{"user_id":2,"kind":"mute","reason":"spam","duration_seconds":3600}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 1, gotAdmin)
+		assert.Equal(t, 2, got.UserID)
+		assert.Equal(t, "spam", got.Reason)
+		assert.Equal(t, time.Hour, gotDuration)
+	})
+
+	t.Run("not an admin", func(t *testing.T) {
+		r := newChatModerationRouter(&MockChatModerationUseCase{
+			SanctionFunc: func(ctx context.Context, adminID int, sanction *entity.ChatSanction, duration time.Duration) error {
+				return usecase.ErrForbidden
+			},
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/admin/chat/sanctions", strings.NewReader(`{"user_id":2,"kind":"ban"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid kind", func(t *testing.T) {
+		r := newChatModerationRouter(&MockChatModerationUseCase{
+			SanctionFunc: func(ctx context.Context, adminID int, sanction *entity.ChatSanction, duration time.Duration) error {
+				return usecase.ErrInvalidSanction
+			},
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/admin/chat/sanctions", strings.NewReader(`{"user_id":2,"kind":"kick"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestChatModerationHandler_LiftSanction(t *testing.T) {
+	var gotUser int
+	var gotKind string
+	r := newChatModerationRouter(&MockChatModerationUseCase{
+		LiftSanctionFunc: func(ctx context.Context, adminID, userID int, kind string) error {
+			gotUser, gotKind = userID, kind
+			if userID == 3 {
+				return usecase.ErrNotFound
+			}
+			return nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/chat/sanctions/2/ban", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 2, gotUser)
+	assert.Equal(t, "ban", gotKind)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/chat/sanctions/3/ban", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestChatModerationHandler_SetSlowMode(t *testing.T) {
+	var gotInterval time.Duration
+	r := newChatModerationRouter(&MockChatModerationUseCase{
+		SetSlowModeFunc: func(ctx context.Context, adminID, roomID int, interval time.Duration) error {
+			gotInterval = interval
+			return nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/admin/chat/rooms/4/slow-mode", strings.NewReader(`{"seconds":0}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, time.Duration(0), gotInterval)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("PUT", "/admin/chat/rooms/4/slow-mode", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestChatHandler_RestrictedSender(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{usecase.ErrChatMuted, http.StatusForbidden},
+		{usecase.ErrChatBanned, http.StatusForbidden},
+		{usecase.ErrSlowMode, http.StatusTooManyRequests},
+		{usecase.ErrFloodLimit, http.StatusTooManyRequests},
+	} {
+		handler := delivery.NewChatHandler(&MockChatUseCase{
+			SendMessageFunc: func(ctx context.Context, message *entity.ChatMessage) error { return tc.err },
+		})
+		r := gin.New()
+		r.POST("/chat/messages", func(c *gin.Context) {
+			c.Set("user_id", 1)
+			c.Set("username", "alice")
+		}, handler.SendMessage)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/chat/messages", strings.NewReader(`{"text":"hi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.code, w.Code, tc.err.Error())
+	}
+}
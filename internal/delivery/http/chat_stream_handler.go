@@ -0,0 +1,98 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/usecase"
+)
+
+// StreamEvents godoc
+// @Summary Event stream
+// @Description Server-Sent Events alternative to /chat/ws for clients behind proxies that break WebSockets.
+// @Description Events are the WebSocket envelopes: "event" is the envelope type and "data" its payload.
+// @Description Feeds: chat (default room messages, plus direct messages when authenticated), posts and comments.
+// @Description post_ids watches posts for created, edited and deleted comments; without feeds it is the only thing streamed.
+// @Description Authentication is optional and works as for /chat/ws; anonymous streams get the public feeds.
+// @Description Default room messages carry an id, so a reconnecting EventSource resumes via Last-Event-ID.
+// @Tags chat
+// @Produce text/event-stream
+// @Param feeds query string false "Comma-separated feeds: chat, posts, comments" default(chat)
+// @Param post_ids query string false "Comma-separated IDs of posts to watch, at most 20"
+// @Param ticket query string false "One-time ticket"
+// @Param last_event_id query int false "Same as the Last-Event-ID header"
+// @Success 200 "Stream of events"
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 503 {object} docs.Error
+// @Router /chat/events [get]
+func (h *ChatHandler) StreamEvents(c *gin.Context) {
+	opts := usecase.EventStreamOptions{}
+	if c.Query("ticket") != "" || extractToken(c) != "" {
+		identity, err := h.authenticateWebSocket(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": "unauthorized"})
+			return
+		}
+		opts.Identity = identity
+	}
+
+	for _, value := range c.QueryArray("feeds") {
+		for _, feed := range strings.Split(value, ",") {
+			if feed = strings.TrimSpace(feed); feed != "" {
+				opts.Feeds = append(opts.Feeds, feed)
+			}
+		}
+	}
+
+	for _, value := range c.QueryArray("post_ids") {
+		for _, raw := range strings.Split(value, ",") {
+			postID, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil || postID <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+				return
+			}
+			opts.Posts = append(opts.Posts, postID)
+		}
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.DefaultQuery("last_event_id", "0")
+	}
+	id, err := strconv.Atoi(lastEventID)
+	if err != nil || id < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last event ID"})
+		return
+	}
+	opts.LastEventID = id
+
+	stream, err := h.chatUC.OpenEventStream(opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidFeed), errors.Is(err, usecase.ErrInvalidPostID),
+			errors.Is(err, usecase.ErrTooManyWatched):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrChatBanned):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "chat_restricted"})
+		case errors.Is(err, usecase.ErrChatUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Иначе nginx копит ответ в буфере и события приходят пачками.
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	// Ошибка записи значит, что клиент ушёл; ответить ему уже нечем.
+	_ = stream.Serve(c.Request.Context(), c.Writer)
+}
@@ -0,0 +1,122 @@
+package delivery_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	delivery "github.com/perfect1337/forum-service/internal/delivery/http"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamFunc func(ctx context.Context, w usecase.EventWriter) error
+
+func (f streamFunc) Serve(ctx context.Context, w usecase.EventWriter) error { return f(ctx, w) }
+
+func newEventsRouter(mockUC *MockChatUseCase) *gin.Engine {
+	handler := delivery.NewChatHandler(mockUC)
+	r := gin.New()
+	r.GET("/chat/events", handler.StreamEvents)
+	return r
+}
+
+func TestChatHandler_StreamEvents(t *testing.T) {
+	t.Run("Аноним с лентами и Last-Event-ID", func(t *testing.T) {
+		var got usecase.EventStreamOptions
+		r := newEventsRouter(&MockChatUseCase{
+			OpenEventStreamFunc: func(opts usecase.EventStreamOptions) (usecase.EventStream, error) {
+				got = opts
+				return streamFunc(func(ctx context.Context, w usecase.EventWriter) error {
+					io.WriteString(w, "event: system\ndata: {}\n\n")
+					w.Flush()
+					return nil
+				}), nil
+			},
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chat/events?feeds=posts,comments&feeds=chat&post_ids=3,4", nil)
+		req.Header.Set("Last-Event-ID", "42")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, "event: system\ndata: {}\n\n", w.Body.String())
+		assert.Nil(t, got.Identity)
+		assert.Equal(t, []string{"posts", "comments", "chat"}, got.Feeds)
+		assert.Equal(t, []int{3, 4}, got.Posts)
+		assert.Equal(t, 42, got.LastEventID)
+	})
+
+	t.Run("Токен определяет пользователя", func(t *testing.T) {
+		var got usecase.EventStreamOptions
+		r := newEventsRouter(&MockChatUseCase{
+			AuthenticateFunc: func(token string) (*usecase.ChatIdentity, error) {
+				if token != "valid" {
+					return nil, errors.New("invalid token")
+				}
+				return &usecase.ChatIdentity{UserID: 7, Username: "alice"}, nil
+			},
+			OpenEventStreamFunc: func(opts usecase.EventStreamOptions) (usecase.EventStream, error) {
+				got = opts
+				return streamFunc(func(ctx context.Context, w usecase.EventWriter) error { return nil }), nil
+			},
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chat/events?last_event_id=5", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 7, got.Identity.UserID)
+		assert.Equal(t, 5, got.LastEventID)
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/chat/events", nil)
+		req.Header.Set("Authorization", "Bearer expired")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Ошибки открытия", func(t *testing.T) {
+		for _, tc := range []struct {
+			err  error
+			code int
+		}{
+			{usecase.ErrInvalidFeed, http.StatusBadRequest},
+			{usecase.ErrTooManyWatched, http.StatusBadRequest},
+			{usecase.ErrChatBanned, http.StatusForbidden},
+			{usecase.ErrChatUnavailable, http.StatusServiceUnavailable},
+		} {
+			r := newEventsRouter(&MockChatUseCase{
+				OpenEventStreamFunc: func(opts usecase.EventStreamOptions) (usecase.EventStream, error) {
+					return nil, tc.err
+				},
+			})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/chat/events", nil))
+			assert.Equal(t, tc.code, w.Code, tc.err.Error())
+		}
+	})
+
+	t.Run("Неверный Last-Event-ID", func(t *testing.T) {
+		r := newEventsRouter(&MockChatUseCase{})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/chat/events", nil)
+		req.Header.Set("Last-Event-ID", "abc")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Неверный пост", func(t *testing.T) {
+		r := newEventsRouter(&MockChatUseCase{})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/chat/events?post_ids=1,x", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -13,10 +13,17 @@ import (
 
 type CommentHandler struct {
 	commentUC usecase.CommentUseCaseInterface
+	userUC    usecase.UserUseCaseInterface // nil — имена авторов только из репозитория
 }
 
 func NewCommentHandler(commentUC usecase.CommentUseCaseInterface) *CommentHandler {
-	return &CommentHandler{commentUC: commentUC}
+	return NewCommentHandlerWithUsers(commentUC, nil)
+}
+
+// NewCommentHandlerWithUsers is NewCommentHandler that takes the names of
+// comment authors from the user directory, like PostHandler does.
+func NewCommentHandlerWithUsers(commentUC usecase.CommentUseCaseInterface, userUC usecase.UserUseCaseInterface) *CommentHandler {
+	return &CommentHandler{commentUC: commentUC, userUC: userUC}
 }
 
 // CreateComment godoc
@@ -28,10 +35,11 @@ func NewCommentHandler(commentUC usecase.CommentUseCaseInterface) *CommentHandle
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
 // @Param id path int true "Post ID"
-// @Param comment body entity.Comment true "Comment object" SchemaExample({"content":"This is a comment"})
+// @Param comment body entity.Comment true "Comment object; set parent_id to reply to another comment" SchemaExample({"content":"This is a comment","parent_id":1})
 // @Success 201 {object} entity.Comment
 // @Failure 400 {object} docs.Error "Invalid request format"
 // @Failure 401 {object} docs.Error "Missing or invalid authentication token"
+// @Failure 404 {object} docs.Error "Post not found or in the trash"
 // @Failure 500 {object} docs.Error "Server error"
 // @Router /posts/{id}/comments [post]
 
@@ -59,6 +67,14 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 	comment.UserID = userID.(int)
 
 	if err := h.commentUC.CreateComment(c.Request.Context(), &comment); err != nil {
+		if errors.Is(err, usecase.ErrInvalidParent) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -68,11 +84,13 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 
 // GetComments godoc
 // @Summary Get comments for a post
-// @Description Retrieve all comments for a specific post
+// @Description Retrieve the comment tree of a specific post. Replies are nested under their parent; comments at the depth limit report hidden replies in collapsed_count.
 // @Tags comments
 // @Accept json
 // @Produce json
 // @Param id path int true "Post ID"
+// @Param depth query int false "Maximum nesting depth (default 5, max 50)"
+// @Param sort query string false "Order of sibling comments: old (default), new, top or hot"
 // @Success 200 {array} entity.Comment
 // @Failure 400 {object} docs.Error
 // @Failure 500 {object} docs.Error
@@ -85,18 +103,97 @@ func (h *CommentHandler) GetComments(c *gin.Context) {
 		return
 	}
 
-	comments, err := h.commentUC.GetCommentsByPostID(c.Request.Context(), postID)
+	depth := 0
+	if v := c.Query("depth"); v != "" {
+		depth, err = strconv.Atoi(v)
+		if err != nil || depth <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid depth"})
+			return
+		}
+	}
+
+	sort := c.Query("sort")
+	switch sort {
+	case "", entity.SortOld, entity.SortNew, entity.SortTop, entity.SortHot:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort: must be old, new, top or hot"})
+		return
+	}
+
+	comments, err := h.commentUC.GetCommentTree(c.Request.Context(), postID, depth, sort)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	resolveAuthors(c.Request.Context(), h.userUC, nil, comments)
 
 	c.JSON(http.StatusOK, comments)
 }
 
+// EditComment godoc
+// @Summary Edit comment
+// @Description Replace the text of the caller's comment. Clients watching the post receive a comment_edited event.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Param comment_id path int true "Comment ID"
+// @Param comment body object true "New text" SchemaExample({"content":"Updated comment"})
+// @Success 200 {object} entity.Comment
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /posts/{id}/comments/{comment_id} [patch]
+func (h *CommentHandler) EditComment(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var request struct {
+		Content string `json:"content" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.commentUC.EditComment(c.Request.Context(), postID, commentID, userID.(int), request.Content)
+	if err != nil {
+		if errors.Is(err, usecase.ErrEmptyComment) || errors.Is(err, usecase.ErrInvalidPostID) ||
+			errors.Is(err, usecase.ErrInvalidCommentID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, usecase.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "comment not found or not authorized"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
 // DeleteComment godoc
 // @Summary Delete comment
-// @Description Delete a specific comment
+// @Description Move the caller's comment to the trash
 // @Tags comments
 // @Accept json
 // @Produce json
@@ -145,3 +242,52 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "comment deleted successfully"})
 }
+
+// VoteComment godoc
+// @Summary Vote on a comment
+// @Description Upvote (1), downvote (-1) or clear (0) the caller's vote on a comment. Each user has at most one vote per comment. A comment that doesn't belong to the post is not found.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Param comment_id path int true "Comment ID"
+// @Param vote body object true "Vote" SchemaExample({"value":1})
+// @Success 200 {object} entity.VoteResult
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /posts/{id}/comments/{comment_id}/vote [post]
+func (h *CommentHandler) VoteComment(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+	commentID, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req voteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.commentUC.VoteComment(c.Request.Context(), postID, commentID, userID.(int), *req.Value)
+	if err != nil {
+		writeVoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
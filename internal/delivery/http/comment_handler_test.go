@@ -3,6 +3,8 @@ package delivery
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,8 +12,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockCommentUseCase - мок для CommentUseCase
@@ -29,6 +33,27 @@ func (m *MockCommentUseCase) GetCommentsByPostID(ctx context.Context, postID int
 	return args.Get(0).([]entity.Comment), args.Error(1)
 }
 
+func (m *MockCommentUseCase) GetCommentTree(ctx context.Context, postID, depth int, sort string) ([]*entity.Comment, error) {
+	args := m.Called(ctx, postID, depth, sort)
+	return args.Get(0).([]*entity.Comment), args.Error(1)
+}
+
+func (m *MockCommentUseCase) VoteComment(ctx context.Context, postID, commentID, userID, value int) (*entity.VoteResult, error) {
+	args := m.Called(ctx, postID, commentID, userID, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.VoteResult), args.Error(1)
+}
+
+func (m *MockCommentUseCase) EditComment(ctx context.Context, postID, commentID, userID int, content string) (*entity.Comment, error) {
+	args := m.Called(ctx, postID, commentID, userID, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Comment), args.Error(1)
+}
+
 func (m *MockCommentUseCase) DeleteComment(ctx context.Context, commentID, userID int) error {
 	args := m.Called(ctx, commentID, userID)
 	return args.Error(0)
@@ -84,12 +109,12 @@ func TestGetComments(t *testing.T) {
 	c.Request = req
 	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
 
-	comments := []entity.Comment{
+	comments := []*entity.Comment{
 		{PostID: 1, UserID: 1, Content: "Comment 1"},
 		{PostID: 1, UserID: 2, Content: "Comment 2"},
 	}
 
-	mockCommentUC.On("GetCommentsByPostID", mock.Anything, 1).Return(comments, nil)
+	mockCommentUC.On("GetCommentTree", mock.Anything, 1, 0, "").Return(comments, nil)
 
 	handler.GetComments(c)
 
@@ -97,6 +122,138 @@ func TestGetComments(t *testing.T) {
 	mockCommentUC.AssertExpectations(t)
 }
 
+func TestGetCommentsWithDepth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/posts/1/comments?depth=2&sort=top", nil)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	comments := []*entity.Comment{{ID: 1, PostID: 1, CollapsedCount: 3}}
+	mockCommentUC.On("GetCommentTree", mock.Anything, 1, 2, "top").Return(comments, nil)
+
+	handler.GetComments(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"collapsed_count":3`)
+	mockCommentUC.AssertExpectations(t)
+}
+
+func TestGetCommentsAuthorNames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		users    map[int]*entity.User
+		err      error
+		expected []string
+	}{
+		{
+			name:     "FromDirectory",
+			users:    map[int]*entity.User{1: {ID: 1, Username: "alice"}, 2: {ID: 2, Username: "bob"}},
+			expected: []string{"alice", "bob"},
+		},
+		{
+			// Каталог недоступен: пустое имя из репозитория заменяется заглушкой
+			name:     "DirectoryDown",
+			err:      usecase.ErrUsersUnavailable,
+			expected: []string{usecase.PlaceholderAuthorName, usecase.PlaceholderAuthorName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommentUC := new(MockCommentUseCase)
+			mockUserUC := new(MockUserUseCase)
+
+			comments := []*entity.Comment{{ID: 1, PostID: 1, UserID: 1, Replies: []*entity.Comment{
+				{ID: 2, PostID: 1, UserID: 2},
+			}}}
+			mockCommentUC.On("GetCommentTree", mock.Anything, 1, 0, "").Return(comments, nil)
+			mockUserUC.On("GetUsersByIDs", mock.Anything, []int{1, 2}).Return(tt.users, tt.err).Once()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/posts/1/comments", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewCommentHandlerWithUsers(mockCommentUC, mockUserUC).GetComments(c)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var tree []entity.Comment
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tree))
+			require.Len(t, tree, 1)
+			require.Len(t, tree[0].Replies, 1)
+			assert.Equal(t, tt.expected, []string{tree[0].Author, tree[0].Replies[0].Author})
+			mockUserUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetCommentsInvalidDepth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewCommentHandler(new(MockCommentUseCase))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/posts/1/comments?depth=zero", nil)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	handler.GetComments(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateCommentInvalidParent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts/1/comments", strings.NewReader(`{"content": "Reply", "parent_id": 9}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", 1)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	mockCommentUC.On("CreateComment", mock.Anything, mock.MatchedBy(func(comment *entity.Comment) bool {
+		return comment.ParentID != nil && *comment.ParentID == 9
+	})).Return(usecase.ErrInvalidParent)
+
+	handler.CreateComment(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockCommentUC.AssertExpectations(t)
+}
+
+func TestCreateCommentPostNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts/1/comments", strings.NewReader(`{"content": "Hello"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", 1)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	// Пост удалён в корзину
+	mockCommentUC.On("CreateComment", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: post 1", usecase.ErrNotFound))
+
+	handler.CreateComment(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockCommentUC.AssertExpectations(t)
+}
+
 func TestDeleteComment(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -119,6 +276,102 @@ func TestDeleteComment(t *testing.T) {
 	mockCommentUC.AssertExpectations(t)
 }
 
+func TestEditComment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/comments/1", strings.NewReader(`{"content":"Updated"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("user_id", 1)
+	c.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "comment_id", Value: "1"}}
+
+	mockCommentUC.On("EditComment", mock.Anything, 3, 1, 1, "Updated").
+		Return(&entity.Comment{ID: 1, PostID: 3, Content: "Updated"}, nil)
+
+	handler.EditComment(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"content":"Updated"`)
+	mockCommentUC.AssertExpectations(t)
+}
+
+func TestEditCommentNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/comments/1", strings.NewReader(`{"content":"Updated"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("user_id", 2)
+	c.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "comment_id", Value: "1"}}
+
+	mockCommentUC.On("EditComment", mock.Anything, 3, 1, 2, "Updated").Return(nil, sql.ErrNoRows)
+
+	handler.EditComment(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockCommentUC.AssertExpectations(t)
+}
+
+func TestEditCommentWrongPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/posts/4/comments/1", strings.NewReader(`{"content":"Updated"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("user_id", 1)
+	c.Params = gin.Params{{Key: "id", Value: "4"}, {Key: "comment_id", Value: "1"}}
+
+	mockCommentUC.On("EditComment", mock.Anything, 4, 1, 1, "Updated").
+		Return(nil, fmt.Errorf("%w: comment 1 on post 4", usecase.ErrNotFound))
+
+	handler.EditComment(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockCommentUC.AssertExpectations(t)
+}
+
+func TestEditCommentBlankContent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/posts/3/comments/1", strings.NewReader(`{"content":"   "}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("user_id", 1)
+	c.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "comment_id", Value: "1"}}
+
+	mockCommentUC.On("EditComment", mock.Anything, 3, 1, 1, "   ").Return(nil, usecase.ErrEmptyComment)
+
+	handler.EditComment(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), usecase.ErrEmptyComment.Error())
+	mockCommentUC.AssertExpectations(t)
+}
+
 func TestCreateCommentUnauthorized(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -234,3 +487,96 @@ func TestDeleteCommentNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	mockCommentUC.AssertExpectations(t)
 }
+
+func TestGetCommentsInvalidSort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewCommentHandler(new(MockCommentUseCase))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/posts/1/comments?sort=best", nil)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+	handler.GetComments(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVoteComment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts/1/comments/3/vote", strings.NewReader(`{"value": -1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", 2)
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "comment_id", Value: "3"}}
+
+	mockCommentUC.On("VoteComment", mock.Anything, 1, 3, 2, -1).Return(&entity.VoteResult{Vote: -1, Score: -1}, nil)
+
+	handler.VoteComment(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"vote": -1, "score": -1}`, w.Body.String())
+	mockCommentUC.AssertExpectations(t)
+}
+
+func TestVoteCommentNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockCommentUC := new(MockCommentUseCase)
+	handler := NewCommentHandler(mockCommentUC)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/posts/1/comments/3/vote", strings.NewReader(`{"value": 1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user_id", 2)
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "comment_id", Value: "3"}}
+
+	mockCommentUC.On("VoteComment", mock.Anything, 1, 3, 2, 1).Return(nil, usecase.ErrNotFound)
+
+	handler.VoteComment(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVoteCommentInvalidIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		params gin.Params
+		err    error
+	}{
+		{name: "BadPostID", params: gin.Params{{Key: "id", Value: "abc"}, {Key: "comment_id", Value: "3"}}},
+		{name: "BadCommentID", params: gin.Params{{Key: "id", Value: "1"}, {Key: "comment_id", Value: "abc"}}},
+		{name: "ZeroPostID", params: gin.Params{{Key: "id", Value: "0"}, {Key: "comment_id", Value: "3"}}, err: usecase.ErrInvalidPostID},
+		{name: "ZeroCommentID", params: gin.Params{{Key: "id", Value: "1"}, {Key: "comment_id", Value: "0"}}, err: usecase.ErrInvalidCommentID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommentUC := new(MockCommentUseCase)
+			if tt.err != nil {
+				mockCommentUC.On("VoteComment", mock.Anything, mock.Anything, mock.Anything, 2, 1).Return(nil, tt.err)
+			}
+			handler := NewCommentHandler(mockCommentUC)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/posts/1/comments/3/vote", strings.NewReader(`{"value": 1}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", 2)
+			c.Params = tt.params
+
+			handler.VoteComment(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockCommentUC.AssertExpectations(t)
+		})
+	}
+}
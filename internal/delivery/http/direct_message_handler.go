@@ -0,0 +1,180 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+)
+
+// GetConversations godoc
+// @Summary List direct message conversations
+// @Description Conversations of the current user, most recently active first, with unread counts
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} entity.ConversationList
+// @Failure 401 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /chat/dm [get]
+func (h *ChatHandler) GetConversations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	list, err := h.chatUC.GetConversations(c.Request.Context(), userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetDirectMessages godoc
+// @Summary Get direct messages
+// @Description Messages exchanged with another user, newest first. Pass next_before as "before" for older ones.
+// @Tags chat
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path int true "Peer user ID"
+// @Param before query int false "Return messages older than this ID"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Success 200 {object} entity.DirectMessagePage
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /chat/dm/{user_id}/messages [get]
+func (h *ChatHandler) GetDirectMessages(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	peerID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	before, err := strconv.Atoi(c.DefaultQuery("before", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid before"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	page, err := h.chatUC.GetDirectMessages(c.Request.Context(), userID.(int), peerID, before, limit)
+	if err != nil {
+		writeDirectMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// SendDirectMessage godoc
+// @Summary Send direct message
+// @Description Send a private message to another user. It is delivered live to their open chat sockets.
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path int true "Recipient user ID"
+// @Param message body object true "Message object" SchemaExample({"text":"Hi!"})
+// @Success 201 {object} entity.DirectMessage
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error "Muted or banned"
+// @Failure 429 {object} docs.Error "Flood limit"
+// @Failure 500 {object} docs.Error
+// @Router /chat/dm/{user_id}/messages [post]
+func (h *ChatHandler) SendDirectMessage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	username, _ := c.Get("username")
+	author, _ := username.(string)
+
+	recipientID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var request struct {
+		Text string `json:"text" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	msg := &entity.DirectMessage{
+		SenderID:    userID.(int),
+		RecipientID: recipientID,
+		Author:      author,
+		Text:        request.Text,
+	}
+	if err := h.chatUC.SendDirectMessage(c.Request.Context(), msg); err != nil {
+		writeDirectMessageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg)
+}
+
+// MarkConversationRead godoc
+// @Summary Mark conversation read
+// @Description Mark every message the peer has sent to the current user as read
+// @Tags chat
+// @Security BearerAuth
+// @Param user_id path int true "Peer user ID"
+// @Success 204
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /chat/dm/{user_id}/read [post]
+func (h *ChatHandler) MarkConversationRead(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	peerID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.chatUC.MarkConversationRead(c.Request.Context(), userID.(int), peerID); err != nil {
+		writeDirectMessageError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeDirectMessageError(c *gin.Context, err error) {
+	if writeChatRestrictionError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrInvalidDirectMessage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,124 @@
+package delivery_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	delivery "github.com/perfect1337/forum-service/internal/delivery/http"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDirectMessageRouter(mockUC *MockChatUseCase) *gin.Engine {
+	handler := delivery.NewChatHandler(mockUC)
+	r := gin.New()
+	dm := r.Group("/chat/dm", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Set("username", "alice")
+	})
+	dm.GET("", handler.GetConversations)
+	dm.GET("/:user_id/messages", handler.GetDirectMessages)
+	dm.POST("/:user_id/messages", handler.SendDirectMessage)
+	dm.POST("/:user_id/read", handler.MarkConversationRead)
+	return r
+}
+
+func TestChatHandler_SendDirectMessage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var got *entity.DirectMessage
+		r := newDirectMessageRouter(&MockChatUseCase{
+			SendDirectMessageFunc: func(ctx context.Context, msg *entity.DirectMessage) error {
+				got = msg
+				msg.ID = 10
+				return nil
+			},
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/chat/dm/2/messages", strings.NewReader(`{"text":"hi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 1, got.SenderID)
+		assert.Equal(t, 2, got.RecipientID)
+		assert.Equal(t, "alice", got.Author)
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		r := newDirectMessageRouter(&MockChatUseCase{
+			SendDirectMessageFunc: func(ctx context.Context, msg *entity.DirectMessage) error {
+				return usecase.ErrInvalidDirectMessage
+			},
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/chat/dm/1/messages", strings.NewReader(`{"text":"hi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestChatHandler_GetDirectMessages(t *testing.T) {
+	var gotPeer, gotBefore, gotLimit int
+	r := newDirectMessageRouter(&MockChatUseCase{
+		GetDirectMessagesFunc: func(ctx context.Context, userID, peerID, beforeID, limit int) (*entity.DirectMessagePage, error) {
+			gotPeer, gotBefore, gotLimit = peerID, beforeID, limit
+			return &entity.DirectMessagePage{Messages: []entity.DirectMessage{{ID: 5}}, NextBefore: 5}, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/chat/dm/2/messages?before=9&limit=1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, gotPeer)
+	assert.Equal(t, 9, gotBefore)
+	assert.Equal(t, 1, gotLimit)
+	assert.Contains(t, w.Body.String(), `"next_before":5`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/chat/dm/2/messages?before=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestChatHandler_GetConversations(t *testing.T) {
+	r := newDirectMessageRouter(&MockChatUseCase{
+		GetConversationsFunc: func(ctx context.Context, userID int) (*entity.ConversationList, error) {
+			return &entity.ConversationList{
+				Conversations: []entity.Conversation{{ID: 1, PeerID: 2, UnreadCount: 3}},
+				UnreadTotal:   3,
+			}, nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/chat/dm", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"unread_total":3`)
+}
+
+func TestChatHandler_MarkConversationRead(t *testing.T) {
+	var gotUser, gotPeer int
+	r := newDirectMessageRouter(&MockChatUseCase{
+		MarkConversationReadFunc: func(ctx context.Context, userID, peerID int) error {
+			gotUser, gotPeer = userID, peerID
+			return nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/chat/dm/2/read", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 1, gotUser)
+	assert.Equal(t, 2, gotPeer)
+}
@@ -0,0 +1,49 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthReporter tells whether the service can take traffic and which of
+// its dependencies are up.
+type HealthReporter interface {
+	Status() (serving bool, dependencies map[string]bool)
+}
+
+type HealthHandler struct {
+	health HealthReporter
+}
+
+func NewHealthHandler(health HealthReporter) *HealthHandler {
+	return &HealthHandler{health: health}
+}
+
+// Health godoc
+// @Summary Service health
+// @Description Reports "ok", "degraded" when an optional dependency such as auth-service is down, or "unavailable" with 503 when the service can't take traffic, e.g. during shutdown.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /health [get]
+func (h *HealthHandler) Health(c *gin.Context) {
+	serving, dependencies := h.health.Status()
+	deps := make(gin.H, len(dependencies))
+	state := "ok"
+	for name, up := range dependencies {
+		if up {
+			deps[name] = "up"
+			continue
+		}
+		deps[name] = "down"
+		state = "degraded"
+	}
+
+	if !serving {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "dependencies": deps})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": state, "dependencies": deps})
+}
@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHealthReporter struct {
+	serving      bool
+	dependencies map[string]bool
+}
+
+func (f fakeHealthReporter) Status() (bool, map[string]bool) {
+	return f.serving, f.dependencies
+}
+
+func TestHealthHandler_Health(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		reporter       fakeHealthReporter
+		expectedStatus int
+		expectedState  string
+	}{
+		{
+			name:           "AllUp",
+			reporter:       fakeHealthReporter{serving: true, dependencies: map[string]bool{"postgres": true, "auth-service": true}},
+			expectedStatus: http.StatusOK,
+			expectedState:  "ok",
+		},
+		{
+			name:           "AuthDown",
+			reporter:       fakeHealthReporter{serving: true, dependencies: map[string]bool{"postgres": true, "auth-service": false}},
+			expectedStatus: http.StatusOK,
+			expectedState:  "degraded",
+		},
+		{
+			name:           "NotServing",
+			reporter:       fakeHealthReporter{serving: false, dependencies: map[string]bool{"postgres": false}},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedState:  "unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/health", NewHealthHandler(tt.reporter).Health)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body struct {
+				Status       string            `json:"status"`
+				Dependencies map[string]string `json:"dependencies"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedState, body.Status)
+			for name, up := range tt.reporter.dependencies {
+				if up {
+					assert.Equal(t, "up", body.Dependencies[name])
+				} else {
+					assert.Equal(t, "down", body.Dependencies[name])
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package delivery
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/perfect1337/forum-service/internal/entity"
@@ -35,7 +39,7 @@ func NewPostHandler(
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer <token>"
-// @Param post body entity.Post true "Post object" SchemaExample({"title":"My Post","content":"Post content"})
+// @Param post body entity.Post true "Post object" SchemaExample({"title":"My Post","content":"Post content","category_id":1,"tags":["go","help"]})
 // @Success 201 {object} entity.Post
 // @Failure 400 {object} docs.Error "Invalid request format"
 // @Failure 401 {object} docs.Error "Missing or invalid authentication token"
@@ -64,6 +68,10 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	}
 
 	if err := h.postUC.CreatePost(c.Request.Context(), &post); err != nil {
+		if errors.Is(err, usecase.ErrInvalidTag) || errors.Is(err, usecase.ErrInvalidCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -97,25 +105,18 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 		return
 	}
 
-	// Получаем имя автора
-	user, err := h.userUC.GetUserByID(c.Request.Context(), post.UserID)
-	if err == nil {
-		post.Author = user.Username
-	}
-
 	comments, err := h.commentUC.GetCommentsByPostID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Получаем имена авторов комментариев
+	// Имена автора поста и авторов комментариев — одним запросом
+	commentRefs := make([]*entity.Comment, len(comments))
 	for i := range comments {
-		user, err := h.userUC.GetUserByID(c.Request.Context(), comments[i].UserID)
-		if err == nil {
-			comments[i].Author = user.Username
-		}
+		commentRefs[i] = &comments[i]
 	}
+	resolveAuthors(c.Request.Context(), h.userUC, []*entity.Post{post}, commentRefs)
 
 	response := gin.H{
 		"post":     post,
@@ -127,56 +128,201 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 
 // GetAllPosts godoc
 // @Summary Get all posts
-// @Description Retrieve a list of all forum posts
+// @Description Retrieve a page of forum posts using cursor-based pagination
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param includeComments query boolean false "Include comments in response"
-// @Success 200 {array} entity.Post
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Opaque cursor from the previous page's next_cursor"
+// @Param author_id query int false "Only posts by this user ID"
+// @Param author query string false "Only posts by this username"
+// @Param category query string false "Only posts in the category with this slug"
+// @Param tag query string false "Only posts with this tag"
+// @Param from query string false "Only posts created at or after this time (RFC3339)"
+// @Param to query string false "Only posts created before this time (RFC3339)"
+// @Param sort query string false "Ranking: new (default), top (by score) or hot (score decayed by age)"
+// @Param order query string false "Sort direction: desc (default) or asc"
+// @Success 200 {object} entity.PostPage
+// @Failure 400 {object} docs.Error
 // @Failure 500 {object} docs.Error
 // @Router /posts [get]
 
 func (h *PostHandler) GetAllPosts(c *gin.Context) {
-	includeComments := c.Query("includeComments") == "true"
+	filter, err := parsePostFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	posts, err := h.postUC.GetAllPosts(c.Request.Context())
+	h.writePostPage(c, filter)
+}
+
+// GetPostsByTag godoc
+// @Summary Get posts by tag
+// @Description Retrieve a page of posts with the given tag. Accepts the same query parameters as GET /posts.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param name path string true "Tag name"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Opaque cursor from the previous page's next_cursor"
+// @Param sort query string false "Ranking: new (default), top or hot"
+// @Success 200 {object} entity.PostPage
+// @Failure 400 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /tags/{name}/posts [get]
+func (h *PostHandler) GetPostsByTag(c *gin.Context) {
+	filter, err := parsePostFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	filter.Tag = c.Param("name")
 
-	// Получаем имена авторов для постов
-	for i := range posts {
-		user, err := h.userUC.GetUserByID(c.Request.Context(), posts[i].UserID)
-		if err == nil {
-			posts[i].Author = user.Username
+	h.writePostPage(c, filter)
+}
+
+func (h *PostHandler) writePostPage(c *gin.Context, filter entity.PostFilter) {
+	includeComments := c.Query("includeComments") == "true"
+
+	page, err := h.postUC.GetAllPosts(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidCursor) || errors.Is(err, usecase.ErrInvalidPostFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	posts := page.Posts
 
-		if includeComments {
+	if includeComments {
+		for i := range posts {
 			comments, err := h.commentUC.GetCommentsByPostID(c.Request.Context(), posts[i].ID)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
+			posts[i].Comments = comments
+		}
+	}
 
-			// Получаем имена авторов для комментариев
-			for j := range comments {
-				user, err := h.userUC.GetUserByID(c.Request.Context(), comments[j].UserID)
-				if err == nil {
-					comments[j].Author = user.Username
-				}
-			}
+	// Имена авторов всей страницы — одним запросом к каталогу пользователей
+	resolveAuthors(c.Request.Context(), h.userUC, posts, nil)
 
-			posts[i].Comments = comments
+	c.JSON(http.StatusOK, page)
+}
+
+// resolveAuthors fills in the names of the authors of posts, their Comments and
+// comments with their replies from the user directory with a single
+// GetUsersByIDs call. If the directory is nil, fails or doesn't know a user,
+// the name the repository returned is kept, and an empty one is replaced with
+// usecase.PlaceholderAuthorName.
+func resolveAuthors(ctx context.Context, directory usecase.UserUseCaseInterface, posts []*entity.Post, comments []*entity.Comment) {
+	all := make([]*entity.Comment, 0, len(comments))
+	all = append(all, comments...)
+	for _, post := range posts {
+		for i := range post.Comments {
+			all = append(all, &post.Comments[i])
+		}
+	}
+	// Ответы дерева комментариев дописываются в конец и обходятся тем же циклом
+	for i := 0; i < len(all); i++ {
+		all = append(all, all[i].Replies...)
+	}
+
+	seen := make(map[int]bool, len(posts)+len(all))
+	var ids []int
+	add := func(id int) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, post := range posts {
+		add(post.UserID)
+	}
+	for _, comment := range all {
+		add(comment.UserID)
+	}
+
+	var users map[int]*entity.User
+	if directory != nil && len(ids) > 0 {
+		if found, err := directory.GetUsersByIDs(ctx, ids); err == nil {
+			users = found
+		}
+	}
+	name := func(userID int, local string) string {
+		if user, ok := users[userID]; ok {
+			return user.Username
+		}
+		if local != "" {
+			return local
+		}
+		return usecase.PlaceholderAuthorName
+	}
+	for _, post := range posts {
+		post.Author = name(post.UserID, post.Author)
+	}
+	for _, comment := range all {
+		comment.Author = name(comment.UserID, comment.Author)
+	}
+}
+
+func parsePostFilter(c *gin.Context) (entity.PostFilter, error) {
+	filter := entity.PostFilter{
+		Cursor:   c.Query("cursor"),
+		Author:   c.Query("author"),
+		Category: c.Query("category"),
+		Tag:      c.Query("tag"),
+		Sort:     c.Query("sort"),
+		Order:    c.Query("order"),
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = limit
+	}
+	if v := c.Query("author_id"); v != "" {
+		authorID, err := strconv.Atoi(v)
+		if err != nil || authorID <= 0 {
+			return filter, fmt.Errorf("invalid author_id")
 		}
+		filter.AuthorID = authorID
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: expected RFC3339 time")
+		}
+		filter.From = from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: expected RFC3339 time")
+		}
+		filter.To = to
+	}
+	if filter.Order != "" && filter.Order != "asc" && filter.Order != "desc" {
+		return filter, fmt.Errorf("invalid order: must be asc or desc")
+	}
+	switch filter.Sort {
+	case "", entity.SortNew, entity.SortTop, entity.SortHot:
+	default:
+		return filter, fmt.Errorf("invalid sort: must be new, top or hot")
 	}
 
-	c.JSON(http.StatusOK, posts)
+	return filter, nil
 }
 
 // DeletePost godoc
 // @Summary Delete post
-// @Description Delete a specific post
+// @Description Move a post to the trash. Admins can restore it until the retention period runs out.
 // @Tags posts
 // @Accept json
 // @Produce json
@@ -185,6 +331,7 @@ func (h *PostHandler) GetAllPosts(c *gin.Context) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} docs.Error
 // @Failure 401 {object} docs.Error
+// @Failure 404 {object} docs.Error
 // @Failure 500 {object} docs.Error
 // @Router /posts/{id} [delete]
 
@@ -202,6 +349,14 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	}
 
 	if err := h.postUC.DeletePost(c.Request.Context(), postID, userID.(int)); err != nil {
+		if errors.Is(err, usecase.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrUsersUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -211,7 +366,7 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 
 // UpdatePost godoc
 // @Summary Update post
-// @Description Update a specific post. Only the owner or admin can update.
+// @Description Update a specific post. Only the owner or admin can update. Omitted category_id and tags are left as is; category_id 0 removes the category and an empty tags list removes all tags.
 // @Tags posts
 // @Accept json
 // @Produce json
@@ -244,14 +399,20 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	err = h.postUC.UpdatePost(c.Request.Context(), postID, userID.(int), req.Title, req.Content)
-	if err != nil {
-		if err.Error() == "unauthorized: you can only update your own posts" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	// Категорию и теги можно менять без правки текста, и наоборот
+	if req.Title != "" || req.Content != "" {
+		err = h.postUC.UpdatePost(c.Request.Context(), postID, userID.(int), req.Title, req.Content)
+		if err != nil {
+			writeUpdatePostError(c, err)
+			return
+		}
+	}
+	if req.CategoryID != nil || req.Tags != nil {
+		err = h.postUC.UpdatePostTaxonomy(c.Request.Context(), postID, userID.(int), req.CategoryID, req.Tags)
+		if err != nil {
+			writeUpdatePostError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
 	}
 
 	updatedPost, err := h.postUC.GetPostByID(c.Request.Context(), postID)
@@ -261,3 +422,75 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, updatedPost)
 }
+
+func writeUpdatePostError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUsersUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotPostAuthor):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidTag), errors.Is(err, usecase.ErrInvalidCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type voteRequest struct {
+	Value *int `json:"value" binding:"required"`
+}
+
+// VotePost godoc
+// @Summary Vote on a post
+// @Description Upvote (1), downvote (-1) or clear (0) the caller's vote on a post. Each user has at most one vote per post.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Param vote body object true "Vote" SchemaExample({"value":1})
+// @Success 200 {object} entity.VoteResult
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /posts/{id}/vote [post]
+func (h *PostHandler) VotePost(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req voteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.postUC.VotePost(c.Request.Context(), postID, userID.(int), *req.Value)
+	if err != nil {
+		writeVoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func writeVoteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidVote), errors.Is(err, usecase.ErrInvalidPostID),
+		errors.Is(err, usecase.ErrInvalidCommentID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
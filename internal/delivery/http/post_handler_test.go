@@ -2,16 +2,19 @@ package delivery
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/perfect1337/forum-service/internal/entity"
 	"github.com/perfect1337/forum-service/internal/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockPostUseCase реализация мока для PostUseCase
@@ -30,11 +33,22 @@ func (m *MockPostUseCase) GetPostByID(ctx context.Context, id int) (*entity.Post
 	return args.Get(0).(*entity.Post), args.Error(1)
 }
 
-func (m *MockPostUseCase) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
-	args := m.Called(ctx)
+func (m *MockPostUseCase) GetPostsByIDs(ctx context.Context, ids []int) ([]*entity.Post, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]*entity.Post), args.Error(1)
 }
 
+func (m *MockPostUseCase) GetAllPosts(ctx context.Context, filter entity.PostFilter) (*entity.PostPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PostPage), args.Error(1)
+}
+
 func (m *MockPostUseCase) DeletePost(ctx context.Context, postID, userID int) error {
 	args := m.Called(ctx, postID, userID)
 	return args.Error(0)
@@ -44,6 +58,48 @@ func (m *MockPostUseCase) UpdatePost(ctx context.Context, postID, userID int, ti
 	return args.Error(0)
 }
 
+func (m *MockPostUseCase) VotePost(ctx context.Context, postID, userID, value int) (*entity.VoteResult, error) {
+	args := m.Called(ctx, postID, userID, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.VoteResult), args.Error(1)
+}
+
+func (m *MockPostUseCase) GetPostRevisions(ctx context.Context, postID int) ([]*entity.PostRevision, error) {
+	args := m.Called(ctx, postID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.PostRevision), args.Error(1)
+}
+
+func (m *MockPostUseCase) GetPostRevision(ctx context.Context, postID, revision int) (*entity.PostRevision, error) {
+	args := m.Called(ctx, postID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PostRevision), args.Error(1)
+}
+
+func (m *MockPostUseCase) DiffPostRevisions(ctx context.Context, postID, from, to int) (*entity.RevisionDiff, error) {
+	args := m.Called(ctx, postID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RevisionDiff), args.Error(1)
+}
+
+func (m *MockPostUseCase) RestorePostRevision(ctx context.Context, postID, revision, userID int) error {
+	args := m.Called(ctx, postID, revision, userID)
+	return args.Error(0)
+}
+
+func (m *MockPostUseCase) UpdatePostTaxonomy(ctx context.Context, postID, userID int, categoryID *int, tags []string) error {
+	args := m.Called(ctx, postID, userID, categoryID, tags)
+	return args.Error(0)
+}
+
 // MockUserUseCase
 type MockUserUseCase struct {
 	mock.Mock
@@ -150,7 +206,7 @@ func TestPostHandler_GetPostByID_Success(t *testing.T) {
 	testComments := []entity.Comment{{ID: 1, PostID: 1, UserID: 1}}
 
 	mockPostUC.On("GetPostByID", mock.Anything, 1).Return(testPost, nil)
-	mockUserUC.On("GetUserByID", mock.Anything, 1).Return(testUser, nil).Twice()
+	mockUserUC.On("GetUsersByIDs", mock.Anything, []int{1}).Return(map[int]*entity.User{1: testUser}, nil).Once()
 	mockCommentUC.On("GetCommentsByPostID", mock.Anything, 1).Return(testComments, nil)
 
 	w := httptest.NewRecorder()
@@ -222,9 +278,10 @@ func TestPostHandler_GetAllPosts_Success(t *testing.T) {
 	testUser1 := &entity.User{ID: 1, Username: "user1"}
 	testUser2 := &entity.User{ID: 2, Username: "user2"}
 
-	mockPostUC.On("GetAllPosts", mock.Anything).Return(testPosts, nil)
-	mockUserUC.On("GetUserByID", mock.Anything, 1).Return(testUser1, nil)
-	mockUserUC.On("GetUserByID", mock.Anything, 2).Return(testUser2, nil)
+	mockPostUC.On("GetAllPosts", mock.Anything, mock.Anything).Return(&entity.PostPage{Posts: testPosts}, nil)
+	// Одна выборка авторов на страницу
+	mockUserUC.On("GetUsersByIDs", mock.Anything, []int{1, 2}).
+		Return(map[int]*entity.User{1: testUser1, 2: testUser2}, nil).Once()
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -252,9 +309,9 @@ func TestPostHandler_GetAllPosts_WithComments(t *testing.T) {
 	testComments1 := []entity.Comment{{ID: 1, PostID: 1, UserID: 1}}
 	testComments2 := []entity.Comment{} // Пустой список комментариев для поста 2
 
-	mockPostUC.On("GetAllPosts", mock.Anything).Return(testPosts, nil)
-	mockUserUC.On("GetUserByID", mock.Anything, 1).Return(testUser1, nil).Twice()
-	mockUserUC.On("GetUserByID", mock.Anything, 2).Return(testUser2, nil)
+	mockPostUC.On("GetAllPosts", mock.Anything, mock.Anything).Return(&entity.PostPage{Posts: testPosts}, nil)
+	mockUserUC.On("GetUsersByIDs", mock.Anything, []int{1, 2}).
+		Return(map[int]*entity.User{1: testUser1, 2: testUser2}, nil).Once()
 	// Настраиваем моки для обоих постов
 	mockCommentUC.On("GetCommentsByPostID", mock.Anything, 1).Return(testComments1, nil)
 	mockCommentUC.On("GetCommentsByPostID", mock.Anything, 2).Return(testComments2, nil)
@@ -272,6 +329,63 @@ func TestPostHandler_GetAllPosts_WithComments(t *testing.T) {
 	mockCommentUC.AssertExpectations(t)
 }
 
+func TestPostHandler_GetAllPosts_AuthorNames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		users    map[int]*entity.User
+		err      error
+		expected []string
+	}{
+		{
+			name:     "FromDirectory",
+			users:    map[int]*entity.User{1: {ID: 1, Username: "alice"}, 2: {ID: 2, Username: "bob"}},
+			expected: []string{"alice", "bob", "bob"},
+		},
+		{
+			// Пользователя нет в каталоге — остаётся имя из репозитория,
+			// а пустое заменяется заглушкой
+			name:     "UnknownUser",
+			users:    map[int]*entity.User{1: {ID: 1, Username: "alice"}},
+			expected: []string{"alice", "local", usecase.PlaceholderAuthorName},
+		},
+		{
+			name:     "DirectoryDown",
+			err:      usecase.ErrUsersUnavailable,
+			expected: []string{usecase.PlaceholderAuthorName, "local", usecase.PlaceholderAuthorName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostUC := new(MockPostUseCase)
+			mockCommentUC := new(MockCommentUseCase)
+			mockUserUC := new(MockUserUseCase)
+
+			posts := []*entity.Post{{ID: 1, UserID: 1}, {ID: 2, UserID: 2, Author: "local"}}
+			mockPostUC.On("GetAllPosts", mock.Anything, mock.Anything).Return(&entity.PostPage{Posts: posts}, nil)
+			mockCommentUC.On("GetCommentsByPostID", mock.Anything, 1).Return([]entity.Comment{{ID: 1, PostID: 1, UserID: 2}}, nil)
+			mockCommentUC.On("GetCommentsByPostID", mock.Anything, 2).Return([]entity.Comment{}, nil)
+			mockUserUC.On("GetUsersByIDs", mock.Anything, []int{1, 2}).Return(tt.users, tt.err).Once()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/posts?includeComments=true", nil)
+
+			NewPostHandler(mockPostUC, mockCommentUC, mockUserUC).GetAllPosts(c)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var page entity.PostPage
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+			require.Len(t, page.Posts, 2)
+			require.Len(t, page.Posts[0].Comments, 1)
+			assert.Equal(t, tt.expected, []string{page.Posts[0].Author, page.Posts[1].Author, page.Posts[0].Comments[0].Author})
+			mockUserUC.AssertExpectations(t)
+		})
+	}
+}
+
 func TestPostHandler_DeletePost_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -356,3 +470,231 @@ func TestPostHandler_DeletePost_InternalError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	mockPostUC.AssertExpectations(t)
 }
+
+func TestPostHandler_GetAllPosts_Filters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockPostUC := new(MockPostUseCase)
+	mockCommentUC := new(MockCommentUseCase)
+	mockUserUC := new(MockUserUseCase)
+
+	expected := entity.PostFilter{
+		Limit:    10,
+		Cursor:   "abc",
+		AuthorID: 3,
+		From:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Order:    "asc",
+	}
+	mockPostUC.On("GetAllPosts", mock.Anything, expected).
+		Return(&entity.PostPage{Posts: []*entity.Post{}, NextCursor: "next"}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/posts?limit=10&cursor=abc&author_id=3&from=2025-01-01T00:00:00Z&order=asc", nil)
+
+	handler := NewPostHandler(mockPostUC, mockCommentUC, mockUserUC)
+	handler.GetAllPosts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"next_cursor":"next"`)
+	mockPostUC.AssertExpectations(t)
+}
+
+func TestPostHandler_GetPostsByTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockPostUC := new(MockPostUseCase)
+	mockPostUC.On("GetAllPosts", mock.Anything, entity.PostFilter{Tag: "golang", Category: "help"}).
+		Return(&entity.PostPage{Posts: []*entity.Post{}}, nil)
+
+	handler := NewPostHandler(mockPostUC, new(MockCommentUseCase), new(MockUserUseCase))
+	router := gin.New()
+	router.GET("/tags/:name/posts", handler.GetPostsByTag)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/tags/golang/posts?category=help", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockPostUC.AssertExpectations(t)
+}
+
+func TestPostHandler_GetAllPosts_BadRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, query := range []string{"limit=abc", "author_id=-1", "from=yesterday", "order=random", "sort=best"} {
+		mockPostUC := new(MockPostUseCase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/posts?"+query, nil)
+
+		handler := NewPostHandler(mockPostUC, new(MockCommentUseCase), new(MockUserUseCase))
+		handler.GetAllPosts(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// Ошибки фильтра из usecase — тоже 400, а не 500
+	for _, err := range []error{usecase.ErrInvalidCursor, usecase.ErrInvalidPostFilter} {
+		mockPostUC := new(MockPostUseCase)
+		mockPostUC.On("GetAllPosts", mock.Anything, mock.Anything).Return(nil, err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/posts?cursor=bad", nil)
+
+		handler := NewPostHandler(mockPostUC, new(MockCommentUseCase), new(MockUserUseCase))
+		handler.GetAllPosts(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, err.Error())
+	}
+}
+
+func TestPostHandler_VotePost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		authenticated  bool
+		mockSetup      func(*MockPostUseCase)
+		expectedStatus int
+	}{
+		{
+			name:          "Upvote",
+			body:          `{"value": 1}`,
+			authenticated: true,
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("VotePost", mock.Anything, 1, 7, 1).Return(&entity.VoteResult{Vote: 1, Score: 5}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:          "ClearVote",
+			body:          `{"value": 0}`,
+			authenticated: true,
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("VotePost", mock.Anything, 1, 7, 0).Return(&entity.VoteResult{Vote: 0, Score: 4}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "MissingValue",
+			body:           `{}`,
+			authenticated:  true,
+			mockSetup:      func(m *MockPostUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:          "InvalidValue",
+			body:          `{"value": 3}`,
+			authenticated: true,
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("VotePost", mock.Anything, 1, 7, 3).Return(nil, usecase.ErrInvalidVote)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:          "InvalidPostID",
+			body:          `{"value": 1}`,
+			authenticated: true,
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("VotePost", mock.Anything, 1, 7, 1).Return(nil, usecase.ErrInvalidPostID)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:          "PostNotFound",
+			body:          `{"value": -1}`,
+			authenticated: true,
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("VotePost", mock.Anything, 1, 7, -1).Return(nil, usecase.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Unauthorized",
+			body:           `{"value": 1}`,
+			mockSetup:      func(m *MockPostUseCase) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostUC := new(MockPostUseCase)
+			tt.mockSetup(mockPostUC)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/posts/1/vote", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+			if tt.authenticated {
+				c.Set("user_id", 7)
+			}
+
+			handler := NewPostHandler(mockPostUC, new(MockCommentUseCase), new(MockUserUseCase))
+			handler.VotePost(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockPostUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostHandler_UpdatePost_Taxonomy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	categoryID := 3
+
+	tests := []struct {
+		name      string
+		body      string
+		mockSetup func(*MockPostUseCase)
+	}{
+		{
+			name: "TagsOnly",
+			body: `{"tags": ["go"]}`,
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("UpdatePostTaxonomy", mock.Anything, 1, 7, (*int)(nil), []string{"go"}).Return(nil)
+			},
+		},
+		{
+			name: "CategoryOnly",
+			body: `{"category_id": 3}`,
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("UpdatePostTaxonomy", mock.Anything, 1, 7, &categoryID, []string(nil)).Return(nil)
+			},
+		},
+		{
+			name: "ClearTags",
+			body: `{"tags": []}`,
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("UpdatePostTaxonomy", mock.Anything, 1, 7, (*int)(nil), []string{}).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostUC := new(MockPostUseCase)
+			tt.mockSetup(mockPostUC)
+			mockPostUC.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", 7)
+			c.Request = httptest.NewRequest("PUT", "/posts/1", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			handler := NewPostHandler(mockPostUC, new(MockCommentUseCase), new(MockUserUseCase))
+			handler.UpdatePost(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockPostUC.AssertExpectations(t)
+			// Текст поста не трогается, если title и content не переданы.
+			mockPostUC.AssertNotCalled(t, "UpdatePost", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
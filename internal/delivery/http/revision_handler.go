@@ -0,0 +1,170 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/usecase"
+)
+
+// GetPostRevisions godoc
+// @Summary List post revisions
+// @Description Edit history of a post, oldest first. Revision 1 is the original text.
+// @Tags posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {array} entity.PostRevision
+// @Failure 400 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /posts/{id}/revisions [get]
+func (h *PostHandler) GetPostRevisions(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+
+	revisions, err := h.postUC.GetPostRevisions(c.Request.Context(), postID)
+	if err != nil {
+		writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetPostRevision godoc
+// @Summary Get a post revision
+// @Description Title and content of a post as of the given revision
+// @Tags posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} entity.PostRevision
+// @Failure 400 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /posts/{id}/revisions/{rev} [get]
+func (h *PostHandler) GetPostRevision(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	revision, err := h.postUC.GetPostRevision(c.Request.Context(), postID, rev)
+	if err != nil {
+		writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffPostRevisions godoc
+// @Summary Diff two post revisions
+// @Description Line-based diff of title and content between two revisions
+// @Tags posts
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param from query int true "Older revision number"
+// @Param to query int true "Newer revision number"
+// @Success 200 {object} entity.RevisionDiff
+// @Failure 400 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /posts/{id}/revisions/diff [get]
+func (h *PostHandler) DiffPostRevisions(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from revision"})
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to revision"})
+		return
+	}
+
+	diff, err := h.postUC.DiffPostRevisions(c.Request.Context(), postID, from, to)
+	if err != nil {
+		writeRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestorePostRevision godoc
+// @Summary Restore a post revision
+// @Description Replace the post text with an old revision. The restore is saved as a new revision. Only the owner or admin can restore.
+// @Tags posts
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} entity.Post
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /posts/{id}/revisions/{rev}/restore [post]
+func (h *PostHandler) RestorePostRevision(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	if err := h.postUC.RestorePostRevision(c.Request.Context(), postID, rev, userID.(int)); err != nil {
+		if errors.Is(err, usecase.ErrNotPostAuthor) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		writeRevisionError(c, err)
+		return
+	}
+
+	post, err := h.postUC.GetPostByID(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, post)
+}
+
+func writeRevisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRevision):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
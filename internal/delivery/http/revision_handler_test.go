@@ -0,0 +1,113 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostHandler_DiffPostRevisions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*MockPostUseCase)
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			url:  "/posts/1/revisions/diff?from=1&to=2",
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("DiffPostRevisions", mock.Anything, 1, 1, 2).
+					Return(&entity.RevisionDiff{PostID: 1, From: 1, To: 2}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "MissingFrom",
+			url:            "/posts/1/revisions/diff?to=2",
+			mockSetup:      func(m *MockPostUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "UnknownRevision",
+			url:  "/posts/1/revisions/diff?from=1&to=9",
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("DiffPostRevisions", mock.Anything, 1, 1, 9).Return(nil, usecase.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostUC := new(MockPostUseCase)
+			tt.mockSetup(mockPostUC)
+
+			handler := NewPostHandler(mockPostUC, new(MockCommentUseCase), new(MockUserUseCase))
+			router := gin.New()
+			router.GET("/posts/:id/revisions/diff", handler.DiffPostRevisions)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockPostUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostHandler_RestorePostRevision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockPostUseCase)
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("RestorePostRevision", mock.Anything, 1, 2, 7).Return(nil)
+				m.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, Title: "Old"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "NotOwner",
+			mockSetup: func(m *MockPostUseCase) {
+				m.On("RestorePostRevision", mock.Anything, 1, 2, 7).
+					Return(fmt.Errorf("%w: you can only update your own posts", usecase.ErrNotPostAuthor))
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostUC := new(MockPostUseCase)
+			tt.mockSetup(mockPostUC)
+
+			handler := NewPostHandler(mockPostUC, new(MockCommentUseCase), new(MockUserUseCase))
+			router := gin.New()
+			router.POST("/posts/:id/revisions/:rev/restore", func(c *gin.Context) {
+				c.Set("user_id", 7)
+				handler.RestorePostRevision(c)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/posts/1/revisions/2/restore", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockPostUC.AssertExpectations(t)
+		})
+	}
+}
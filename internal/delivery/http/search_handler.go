@@ -0,0 +1,73 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+)
+
+type SearchHandler struct {
+	searchUC usecase.SearchUseCaseInterface
+}
+
+func NewSearchHandler(searchUC usecase.SearchUseCaseInterface) *SearchHandler {
+	return &SearchHandler{searchUC: searchUC}
+}
+
+// Search godoc
+// @Summary Search posts and comments
+// @Description Full-text search over post titles, post content and comments. Results are ranked; titles and snippets are HTML-escaped, with matched terms wrapped in <mark> tags.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query (supports quoted phrases, OR and -exclusion)"
+// @Param type query string false "Restrict results to post or comment"
+// @Param limit query int false "Page size (default 20, max 50)"
+// @Param offset query int false "Offset from the previous page's next_offset"
+// @Success 200 {object} entity.SearchPage
+// @Failure 400 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := entity.SearchQuery{
+		Query: c.Query("q"),
+		Type:  c.Query("type"),
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		query.Limit = limit
+	}
+	if v := c.Query("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+		query.Offset = offset
+	}
+	if query.Query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter q is required"})
+		return
+	}
+
+	page, err := h.searchUC.Search(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidSearchQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
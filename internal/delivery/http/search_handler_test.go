@@ -0,0 +1,94 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSearchUseCase struct {
+	mock.Mock
+}
+
+func (m *MockSearchUseCase) Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.SearchPage), args.Error(1)
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*MockSearchUseCase)
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			url:  "/search?q=golang&type=post&limit=5&offset=10",
+			mockSetup: func(m *MockSearchUseCase) {
+				m.On("Search", mock.Anything, entity.SearchQuery{Query: "golang", Type: "post", Limit: 5, Offset: 10}).
+					Return(&entity.SearchPage{Results: []entity.SearchResult{{ID: 1, Snippet: "<mark>golang</mark>"}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "MissingQuery",
+			url:            "/search",
+			mockSetup:      func(m *MockSearchUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "InvalidLimit",
+			url:            "/search?q=golang&limit=-1",
+			mockSetup:      func(m *MockSearchUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "InvalidQuery",
+			url:  "/search?q=golang&type=user",
+			mockSetup: func(m *MockSearchUseCase) {
+				m.On("Search", mock.Anything, mock.Anything).
+					Return(nil, fmt.Errorf("%w: type must be post or comment", usecase.ErrInvalidSearchQuery))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "InternalError",
+			url:  "/search?q=golang",
+			mockSetup: func(m *MockSearchUseCase) {
+				m.On("Search", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSearchUC := new(MockSearchUseCase)
+			tt.mockSetup(mockSearchUC)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", tt.url, nil)
+
+			handler := NewSearchHandler(mockSearchUC)
+			handler.Search(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockSearchUC.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/usecase"
+)
+
+type TrashHandler struct {
+	trashUC usecase.TrashUseCaseInterface
+}
+
+func NewTrashHandler(trashUC usecase.TrashUseCaseInterface) *TrashHandler {
+	return &TrashHandler{trashUC: trashUC}
+}
+
+// ListTrash godoc
+// @Summary List deleted items
+// @Description Soft-deleted posts and comments, most recently deleted first. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Only items of this type: post or comment"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset from the previous page's next_offset"
+// @Success 200 {object} entity.TrashPage
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/trash [get]
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	limit, offset := 0, 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = n
+	}
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+		offset = n
+	}
+
+	page, err := h.trashUC.ListTrash(c.Request.Context(), userID.(int), c.Query("type"), limit, offset)
+	if err != nil {
+		writeTrashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// RestoreItem godoc
+// @Summary Restore a deleted item
+// @Description Move a post or comment out of the trash. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "post or comment"
+// @Param id path int true "Item ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/trash/{type}/{id}/restore [post]
+func (h *TrashHandler) RestoreItem(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item ID"})
+		return
+	}
+
+	if err := h.trashUC.RestoreItem(c.Request.Context(), userID.(int), c.Param("type"), id); err != nil {
+		writeTrashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "item restored successfully"})
+}
+
+// PurgeItem godoc
+// @Summary Permanently delete an item
+// @Description Remove a post (with its comments) or a comment from the trash for good. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "post or comment"
+// @Param id path int true "Item ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} docs.Error
+// @Failure 401 {object} docs.Error
+// @Failure 403 {object} docs.Error
+// @Failure 404 {object} docs.Error
+// @Failure 500 {object} docs.Error
+// @Router /admin/trash/{type}/{id} [delete]
+func (h *TrashHandler) PurgeItem(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item ID"})
+		return
+	}
+
+	if err := h.trashUC.PurgeItem(c.Request.Context(), userID.(int), c.Param("type"), id); err != nil {
+		writeTrashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "item purged successfully"})
+}
+
+func writeTrashError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidTrashItem):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "item not found in trash"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
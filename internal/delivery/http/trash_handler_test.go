@@ -0,0 +1,104 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTrashUseCase struct {
+	mock.Mock
+}
+
+func (m *MockTrashUseCase) ListTrash(ctx context.Context, adminID int, itemType string, limit, offset int) (*entity.TrashPage, error) {
+	args := m.Called(ctx, adminID, itemType, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TrashPage), args.Error(1)
+}
+
+func (m *MockTrashUseCase) RestoreItem(ctx context.Context, adminID int, itemType string, id int) error {
+	return m.Called(ctx, adminID, itemType, id).Error(0)
+}
+
+func (m *MockTrashUseCase) PurgeItem(ctx context.Context, adminID int, itemType string, id int) error {
+	return m.Called(ctx, adminID, itemType, id).Error(0)
+}
+
+func TestTrashHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		mockSetup      func(*MockTrashUseCase)
+		expectedStatus int
+	}{
+		{
+			name:   "List",
+			method: "GET",
+			url:    "/admin/trash?type=post&limit=10",
+			mockSetup: func(m *MockTrashUseCase) {
+				m.On("ListTrash", mock.Anything, 1, "post", 10, 0).
+					Return(&entity.TrashPage{Items: []entity.TrashItem{{Type: "post", ID: 4}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "ListForbidden",
+			method: "GET",
+			url:    "/admin/trash",
+			mockSetup: func(m *MockTrashUseCase) {
+				m.On("ListTrash", mock.Anything, 1, "", 0, 0).Return(nil, usecase.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Restore",
+			method: "POST",
+			url:    "/admin/trash/comment/7/restore",
+			mockSetup: func(m *MockTrashUseCase) {
+				m.On("RestoreItem", mock.Anything, 1, "comment", 7).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "PurgeNotInTrash",
+			method: "DELETE",
+			url:    "/admin/trash/post/7",
+			mockSetup: func(m *MockTrashUseCase) {
+				m.On("PurgeItem", mock.Anything, 1, "post", 7).Return(usecase.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTrashUC := new(MockTrashUseCase)
+			tt.mockSetup(mockTrashUC)
+
+			handler := NewTrashHandler(mockTrashUC)
+			router := gin.New()
+			admin := router.Group("/admin/trash", func(c *gin.Context) { c.Set("user_id", 1) })
+			admin.GET("", handler.ListTrash)
+			admin.POST("/:type/:id/restore", handler.RestoreItem)
+			admin.DELETE("/:type/:id", handler.PurgeItem)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockTrashUC.AssertExpectations(t)
+		})
+	}
+}
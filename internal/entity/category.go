@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// Category groups posts by topic. Categories are managed by admins; a post
+// belongs to at most one.
+type Category struct {
+	ID          int       `json:"id" db:"id"`
+	Slug        string    `json:"slug" db:"slug"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
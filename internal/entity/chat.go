@@ -2,10 +2,153 @@ package entity
 
 import "time"
 
+// DefaultChatRoomID is the public room every client is subscribed to. Messages
+// sent without a room go there.
+const DefaultChatRoomID = 1
+
 type ChatMessage struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	Author    string    `json:"author"`
-	Text      string    `json:"text"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int        `json:"id"`
+	RoomID    int        `json:"room_id"`
+	UserID    int        `json:"user_id"`
+	Author    string     `json:"author"`
+	Text      string     `json:"text"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+// ChatHistoryFilter selects a page of room messages by message ID. Without
+// AfterID the newest messages come first; with AfterID the page starts right
+// after it and goes forward in time, which is what a reconnecting client needs.
+type ChatHistoryFilter struct {
+	RoomID   int
+	BeforeID int
+	AfterID  int
+	Limit    int
+}
+
+type ChatRoom struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	CreatedBy   int    `json:"created_by"`
+	MemberCount int    `json:"member_count"`
+	// SlowModeSeconds is how long a user has to wait between two messages
+	// in the room; zero turns slow mode off.
+	SlowModeSeconds int       `json:"slow_mode_seconds"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// DirectMessage is a private message between two users. It is never sent to
+// a chat room.
+type DirectMessage struct {
+	ID             int        `json:"id"`
+	ConversationID int        `json:"conversation_id"`
+	SenderID       int        `json:"sender_id"`
+	RecipientID    int        `json:"recipient_id"`
+	Author         string     `json:"author"`
+	Text           string     `json:"text"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReadAt         *time.Time `json:"read_at,omitempty"`
+}
+
+// Conversation is a direct message thread as seen by one of its participants.
+type Conversation struct {
+	ID           int            `json:"id"`
+	PeerID       int            `json:"peer_id"`
+	PeerUsername string         `json:"peer_username,omitempty"`
+	LastMessage  *DirectMessage `json:"last_message,omitempty"`
+	UnreadCount  int            `json:"unread_count"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+type ConversationList struct {
+	Conversations []Conversation `json:"conversations"`
+	UnreadTotal   int            `json:"unread_total"`
+}
+
+// DirectMessagePage holds messages newest first. NextBefore is the ID to pass
+// as "before" to fetch older messages; zero means there are none.
+type DirectMessagePage struct {
+	Messages   []DirectMessage `json:"messages"`
+	NextBefore int             `json:"next_before,omitempty"`
+}
+
+const (
+	ChatEventMessage       = "message"
+	ChatEventDirectMessage = "direct_message"
+	ChatEventTyping        = "typing"
+	ChatEventPresence      = "presence"
+	ChatEventEdited        = "message_edited"
+	ChatEventDeleted       = "message_deleted"
+	ChatEventSanction      = "sanction"
+	// Forum activity travels the same way, for clients that follow the
+	// "posts" and "comments" feeds or watch a post.
+	ChatEventPostCreated    = "post_created"
+	ChatEventCommentCreated = "comment_created"
+	ChatEventCommentEdited  = "comment_edited"
+	ChatEventCommentDeleted = "comment_deleted"
+)
+
+// ChatEvent is something that happened in the chat or on the forum and has
+// to reach the streaming clients of every running instance. Exactly one of
+// the payload fields is set, according to Type: Message for new and edited
+// room messages, Deleted for removed ones; likewise Comment and
+// DeletedComment for comments.
+type ChatEvent struct {
+	Type           string              `json:"type"`
+	Message        *ChatMessage        `json:"message,omitempty"`
+	Deleted        *DeletedChatMessage `json:"deleted,omitempty"`
+	Direct         *DirectMessage      `json:"direct,omitempty"`
+	Typing         *TypingEvent        `json:"typing,omitempty"`
+	Presence       *PresenceEvent      `json:"presence,omitempty"`
+	Sanction       *ChatSanction       `json:"sanction,omitempty"`
+	Post           *Post               `json:"post,omitempty"`
+	Comment        *Comment            `json:"comment,omitempty"`
+	DeletedComment *DeletedComment     `json:"deleted_comment,omitempty"`
+}
+
+// DeletedChatMessage identifies a room message that was removed, so clients
+// can drop it from their view.
+type DeletedChatMessage struct {
+	ID     int `json:"id"`
+	RoomID int `json:"room_id"`
+}
+
+// DeletedComment identifies a comment that was moved to the trash.
+type DeletedComment struct {
+	ID     int `json:"id"`
+	PostID int `json:"post_id"`
+}
+
+// TypingEvent says a user is typing, either in a room or to another user in
+// a direct conversation. Only one of RoomID and To is set.
+type TypingEvent struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	RoomID   int    `json:"room_id,omitempty"`
+	To       int    `json:"to,omitempty"`
+}
+
+// PresenceEvent says a user got their first connection to an instance, or
+// lost their last one. A user is online while any instance reports them.
+type PresenceEvent struct {
+	Instance string `json:"instance"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Online   bool   `json:"online"`
+}
+
+const (
+	ChatSanctionMute = "mute"
+	ChatSanctionBan  = "ban"
+)
+
+// ChatSanction restricts a user in the chat until ExpiresAt, or for good when
+// it is nil. A muted user can still read the chat; a banned one can't connect.
+type ChatSanction struct {
+	UserID    int        `json:"user_id"`
+	Kind      string     `json:"kind"`
+	Reason    string     `json:"reason,omitempty"`
+	CreatedBy int        `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
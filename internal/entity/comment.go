@@ -3,10 +3,17 @@ package entity
 import "time"
 
 type Comment struct {
-	ID        int       `json:"id" db:"id"`
-	Content   string    `json:"content" db:"content"`
-	PostID    int       `json:"post_id" db:"post_id"` // Должно быть int
-	UserID    int       `json:"user_id" db:"user_id"`
-	Author    string    `json:"author" db:"-"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID        int        `json:"id" db:"id"`
+	Content   string     `json:"content" db:"content"`
+	PostID    int        `json:"post_id" db:"post_id"` // Должно быть int
+	ParentID  *int       `json:"parent_id,omitempty" db:"parent_id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	Author    string     `json:"author" db:"-"`
+	Score     int        `json:"score" db:"score"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty" db:"edited_at"`
+	Replies   []*Comment `json:"replies,omitempty" db:"-"`
+	// CollapsedCount is the number of descendants left out of Replies because
+	// the requested tree depth was reached.
+	CollapsedCount int `json:"collapsed_count,omitempty" db:"-"`
 }
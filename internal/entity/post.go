@@ -4,13 +4,45 @@ import "time"
 
 // internal/entity/post.go
 type Post struct {
-	ID        int       `json:"id" db:"id"`
-	Title     string    `json:"title" db:"title"`
-	Content   string    `json:"content" db:"content"`
-	UserID    int       `json:"user_id" db:"user_id"`
-	Author    string    `json:"author" db:"-"` // db:"-" означает, что это поле не маппится напрямую
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	Comments  []Comment `json:"comments,omitempty" db:"-"`
+	ID         int        `json:"id" db:"id"`
+	Title      string     `json:"title" db:"title"`
+	Content    string     `json:"content" db:"content"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	Author     string     `json:"author" db:"-"` // db:"-" означает, что это поле не маппится напрямую
+	Score      int        `json:"score" db:"score"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	EditedAt   *time.Time `json:"edited_at,omitempty" db:"edited_at"`
+	CategoryID *int       `json:"category_id,omitempty" db:"category_id"`
+	Tags       []string   `json:"tags,omitempty" db:"-"`
+	Comments   []Comment  `json:"comments,omitempty" db:"-"`
+}
+
+// PostFilter describes a page request for the post feed.
+type PostFilter struct {
+	Limit    int
+	Cursor   string      // непрозрачный курсор, пришедший от клиента
+	After    *PostCursor // декодированный курсор, заполняется в usecase
+	AuthorID int
+	Author   string
+	Category string // slug категории
+	Tag      string
+	From     time.Time
+	To       time.Time
+	Sort     string // "new" (по умолчанию), "top" или "hot"
+	Order    string // "desc" (по умолчанию) или "asc"
+}
+
+// PostCursor is the keyset position of the last post on a page.
+type PostCursor struct {
+	Sort      string
+	CreatedAt time.Time
+	Score     int
+	ID        int
+}
+
+type PostPage struct {
+	Posts      []*Post `json:"posts"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
 type User struct {
 	ID           int    `json:"id"`
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// PostRevision is a snapshot of a post's title and content. Revision numbers
+// start at 1 for the original text and grow with every edit.
+type PostRevision struct {
+	ID        int       `json:"id" db:"id"`
+	PostID    int       `json:"post_id" db:"post_id"`
+	Revision  int       `json:"revision" db:"revision"`
+	Title     string    `json:"title" db:"title"`
+	Content   string    `json:"content" db:"content"`
+	EditorID  int       `json:"editor_id" db:"editor_id"`
+	Editor    string    `json:"editor" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiff struct {
+	PostID  int        `json:"post_id"`
+	From    int        `json:"from"`
+	To      int        `json:"to"`
+	Title   []DiffLine `json:"title"`
+	Content []DiffLine `json:"content"`
+}
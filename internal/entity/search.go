@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+const (
+	SearchTypePost    = "post"
+	SearchTypeComment = "comment"
+)
+
+type SearchQuery struct {
+	Query  string
+	Type   string // "", "post" или "comment"
+	Limit  int
+	Offset int
+}
+
+// SearchResult is a single ranked hit. For comments Title holds the title of
+// the post the comment belongs to.
+//
+// Title and Snippet are HTML: the user's text is escaped and matched terms are
+// wrapped in <mark> tags, so clients can render them as-is.
+type SearchResult struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	PostID    int       `json:"post_id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	UserID    int       `json:"user_id"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	NextOffset int            `json:"next_offset,omitempty"`
+}
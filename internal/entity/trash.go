@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	TrashTypePost    = "post"
+	TrashTypeComment = "comment"
+)
+
+// TrashItem is a soft-deleted post or comment waiting to be restored or
+// purged.
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	PostID    int       `json:"post_id"`
+	Title     string    `json:"title,omitempty"`
+	Content   string    `json:"content"`
+	UserID    int       `json:"user_id"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy int       `json:"deleted_by"`
+}
+
+type TrashPage struct {
+	Items      []TrashItem `json:"items"`
+	NextOffset int         `json:"next_offset,omitempty"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+// Sort orders accepted by post and comment listings.
+const (
+	SortNew = "new"
+	SortTop = "top"
+	SortHot = "hot"
+	SortOld = "old" // только для комментариев: хронологический порядок
+)
+
+// VoteResult is returned after a vote is cast: the caller's current vote
+// (-1, 0 or 1) and the item's new aggregated score.
+type VoteResult struct {
+	Vote  int `json:"vote"`
+	Score int `json:"score"`
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+type CategoryRepository interface {
+	GetCategories(ctx context.Context) ([]entity.Category, error)
+	GetCategoryByID(ctx context.Context, id int) (*entity.Category, error)
+	CreateCategory(ctx context.Context, category *entity.Category) error
+	UpdateCategory(ctx context.Context, category *entity.Category) error
+	DeleteCategory(ctx context.Context, id int) error
+}
+
+// ErrConflict is returned when a unique value (such as a category slug) is
+// already taken.
+var ErrConflict = errors.New("already exists")
+
+const uniqueViolation = "23505"
+
+func (p *Postgres) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT id, slug, name, description, created_at FROM categories ORDER BY name, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []entity.Category
+	for rows.Next() {
+		var c entity.Category
+		if err := rows.Scan(&c.ID, &c.Slug, &c.Name, &c.Description, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return categories, nil
+}
+
+func (p *Postgres) GetCategoryByID(ctx context.Context, id int) (*entity.Category, error) {
+	var c entity.Category
+	err := p.db.QueryRowContext(ctx,
+		`SELECT id, slug, name, description, created_at FROM categories WHERE id = $1`, id).
+		Scan(&c.ID, &c.Slug, &c.Name, &c.Description, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return &c, nil
+}
+
+func (p *Postgres) CreateCategory(ctx context.Context, category *entity.Category) error {
+	err := p.db.QueryRowContext(ctx, `
+        INSERT INTO categories (slug, name, description) VALUES ($1, $2, $3)
+        RETURNING id, created_at
+    `, category.Slug, category.Name, category.Description).Scan(&category.ID, &category.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (p *Postgres) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	err := p.db.QueryRowContext(ctx, `
+        UPDATE categories SET slug = $1, name = $2, description = $3
+        WHERE id = $4
+        RETURNING created_at
+    `, category.Slug, category.Name, category.Description, category.ID).Scan(&category.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+// DeleteCategory removes a category; its posts become uncategorized.
+func (p *Postgres) DeleteCategory(ctx context.Context, id int) error {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
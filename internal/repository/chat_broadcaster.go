@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+const (
+	chatEventsChannel = "chat_events"
+	// maxNotifyPayload keeps payloads under Postgres' 8000-byte NOTIFY limit.
+	maxNotifyPayload = 7900
+)
+
+// chatNotification is the NOTIFY payload. When the event doesn't fit, only
+// the message IDs are sent with Ref set, and listeners load the message
+// from the database.
+type chatNotification struct {
+	entity.ChatEvent
+	Ref bool `json:"ref,omitempty"`
+}
+
+// PostgresChatBroadcaster fans chat events out to every instance through
+// LISTEN/NOTIFY. Events published here reach subscribers on all instances,
+// this one included, only via Postgres. Events sent while a listener is
+// reconnecting are lost; clients catch up with last_seen_id on reconnect.
+type PostgresChatBroadcaster struct {
+	repo     *Postgres
+	listener *pq.Listener
+
+	mu       sync.RWMutex
+	handlers []func(entity.ChatEvent)
+}
+
+func NewPostgresChatBroadcaster(repo *Postgres) (*PostgresChatBroadcaster, error) {
+	listener := pq.NewListener(connString(repo.cfg), time.Second, time.Minute,
+		func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Printf("Chat listener error: %v", err)
+			}
+		})
+	if err := listener.Listen(chatEventsChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen for chat events: %w", err)
+	}
+	return &PostgresChatBroadcaster{repo: repo, listener: listener}, nil
+}
+
+func (b *PostgresChatBroadcaster) Publish(ctx context.Context, event entity.ChatEvent) error {
+	payload, err := json.Marshal(chatNotification{ChatEvent: event})
+	if err != nil {
+		return fmt.Errorf("failed to encode chat event: %w", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		if payload, err = chatEventRef(event); err != nil {
+			return err
+		}
+	}
+	if _, err := b.repo.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, chatEventsChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish chat event: %w", err)
+	}
+	return nil
+}
+
+// chatEventRef encodes an event that is too large for NOTIFY as a reference
+// to the stored message, post or comment.
+func chatEventRef(event entity.ChatEvent) ([]byte, error) {
+	ref := chatNotification{ChatEvent: entity.ChatEvent{Type: event.Type}, Ref: true}
+	switch {
+	case event.Message != nil:
+		ref.Message = &entity.ChatMessage{ID: event.Message.ID}
+	case event.Direct != nil:
+		ref.Direct = &entity.DirectMessage{ID: event.Direct.ID}
+	case event.Post != nil:
+		ref.Post = &entity.Post{ID: event.Post.ID}
+	case event.Comment != nil:
+		ref.Comment = &entity.Comment{ID: event.Comment.ID}
+	default:
+		return nil, fmt.Errorf("chat event %q is too large to publish", event.Type)
+	}
+	return json.Marshal(ref)
+}
+
+// Subscribe registers a handler for events published by any instance.
+// Handlers run on the listener goroutine, one event at a time.
+func (b *PostgresChatBroadcaster) Subscribe(handler func(entity.ChatEvent)) {
+	b.mu.Lock()
+	b.handlers = append(b.handlers, handler)
+	b.mu.Unlock()
+}
+
+// Start delivers notifications to subscribers until ctx is cancelled, then
+// closes the listener.
+func (b *PostgresChatBroadcaster) Start(ctx context.Context) {
+	go func() {
+		defer b.listener.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-b.listener.Notify:
+				if n == nil {
+					// Соединение переустановлено; что пришло за время разрыва, потеряно.
+					continue
+				}
+				b.dispatch(ctx, n.Extra)
+			case <-time.After(90 * time.Second):
+				go b.listener.Ping()
+			}
+		}
+	}()
+}
+
+func (b *PostgresChatBroadcaster) dispatch(ctx context.Context, payload string) {
+	var n chatNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		log.Printf("Invalid chat notification: %v", err)
+		return
+	}
+	if n.Ref {
+		if err := b.load(ctx, &n.ChatEvent); err != nil {
+			log.Printf("Failed to load chat event %q: %v", n.Type, err)
+			return
+		}
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(n.ChatEvent)
+	}
+}
+
+// load replaces the IDs of a reference event with the stored records.
+func (b *PostgresChatBroadcaster) load(ctx context.Context, event *entity.ChatEvent) error {
+	var err error
+	switch {
+	case event.Message != nil:
+		event.Message, err = b.repo.GetChatMessageByID(ctx, event.Message.ID)
+	case event.Direct != nil:
+		event.Direct, err = b.repo.GetDirectMessageByID(ctx, event.Direct.ID)
+	case event.Post != nil:
+		event.Post, err = b.repo.GetPostByID(ctx, event.Post.ID)
+	case event.Comment != nil:
+		event.Comment, err = b.repo.GetCommentByID(ctx, event.Comment.ID)
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/config"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresChatBroadcaster(t *testing.T) {
+	repo, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
+	}
+	repo.cfg = config.Load()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Два брокера на одной базе — как два экземпляра сервиса.
+	publisher, err := NewPostgresChatBroadcaster(repo)
+	require.NoError(t, err)
+	publisher.Start(ctx)
+	subscriber, err := NewPostgresChatBroadcaster(repo)
+	require.NoError(t, err)
+	subscriber.Start(ctx)
+
+	events := make(chan entity.ChatEvent, 2)
+	subscriber.Subscribe(func(ev entity.ChatEvent) { events <- ev })
+
+	receive := func() entity.ChatEvent {
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("no chat event received")
+			return entity.ChatEvent{}
+		}
+	}
+
+	t.Run("Обычное сообщение", func(t *testing.T) {
+		msg := &entity.ChatMessage{ID: 1, RoomID: 1, UserID: 1, Author: "alice", Text: "hello"}
+		require.NoError(t, publisher.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventMessage, Message: msg}))
+
+		ev := receive()
+		assert.Equal(t, entity.ChatEventMessage, ev.Type)
+		require.NotNil(t, ev.Message)
+		assert.Equal(t, "hello", ev.Message.Text)
+	})
+
+	t.Run("Большое сообщение загружается из базы", func(t *testing.T) {
+		msg := &entity.ChatMessage{RoomID: 1, UserID: 1, Author: "alice", Text: strings.Repeat("x", 10000)}
+		require.NoError(t, repo.SaveChatMessage(ctx, msg))
+		require.NoError(t, publisher.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventMessage, Message: msg}))
+
+		ev := receive()
+		require.NotNil(t, ev.Message)
+		assert.Equal(t, msg.ID, ev.Message.ID)
+		assert.Equal(t, msg.Text, ev.Message.Text)
+	})
+}
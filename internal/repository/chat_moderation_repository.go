@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+// ChatModerationRepository stores chat sanctions and per-room slow mode.
+// Expiry is compared with the database clock, like created_at.
+type ChatModerationRepository interface {
+	SetChatSanction(ctx context.Context, sanction *entity.ChatSanction, duration time.Duration) error
+	DeleteChatSanction(ctx context.Context, userID int, kind string) error
+	GetChatSanctions(ctx context.Context) ([]entity.ChatSanction, error)
+	GetUserChatSanctions(ctx context.Context, userID int) ([]entity.ChatSanction, error)
+	SetChatRoomSlowMode(ctx context.Context, roomID, seconds int) error
+	ChatSlowModeWait(ctx context.Context, roomID, userID int) (time.Duration, error)
+}
+
+// SetChatSanction mutes or bans a user for duration, or for good when it is
+// zero. An existing sanction of the same kind is replaced.
+func (p *Postgres) SetChatSanction(ctx context.Context, sanction *entity.ChatSanction, duration time.Duration) error {
+	err := p.db.QueryRowContext(ctx, `
+        INSERT INTO chat_sanctions (user_id, kind, reason, created_by, expires_at)
+        VALUES ($1, $2, $3, $4,
+            CASE WHEN $5::float8 > 0 THEN NOW() + make_interval(secs => $5::float8) END)
+        ON CONFLICT (user_id, kind) DO UPDATE
+        SET reason = EXCLUDED.reason,
+            created_by = EXCLUDED.created_by,
+            created_at = NOW(),
+            expires_at = EXCLUDED.expires_at
+        RETURNING created_at, expires_at
+    `, sanction.UserID, sanction.Kind, sanction.Reason, sanction.CreatedBy, duration.Seconds(),
+	).Scan(&sanction.CreatedAt, &sanction.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save chat sanction: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) DeleteChatSanction(ctx context.Context, userID int, kind string) error {
+	result, err := p.db.ExecContext(ctx, `
+        DELETE FROM chat_sanctions
+        WHERE user_id = $1 AND kind = $2 AND (expires_at IS NULL OR expires_at > NOW())
+    `, userID, kind)
+	if err != nil {
+		return fmt.Errorf("failed to delete chat sanction: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetChatSanctions lists sanctions that are still in force, newest first.
+func (p *Postgres) GetChatSanctions(ctx context.Context) ([]entity.ChatSanction, error) {
+	return p.queryChatSanctions(ctx, `
+        SELECT user_id, kind, reason, created_by, created_at, expires_at
+        FROM chat_sanctions
+        WHERE expires_at IS NULL OR expires_at > NOW()
+        ORDER BY created_at DESC
+    `)
+}
+
+func (p *Postgres) GetUserChatSanctions(ctx context.Context, userID int) ([]entity.ChatSanction, error) {
+	return p.queryChatSanctions(ctx, `
+        SELECT user_id, kind, reason, created_by, created_at, expires_at
+        FROM chat_sanctions
+        WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+    `, userID)
+}
+
+func (p *Postgres) queryChatSanctions(ctx context.Context, query string, args ...interface{}) ([]entity.ChatSanction, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chat sanctions: %w", err)
+	}
+	defer rows.Close()
+
+	var sanctions []entity.ChatSanction
+	for rows.Next() {
+		var s entity.ChatSanction
+		if err := rows.Scan(&s.UserID, &s.Kind, &s.Reason, &s.CreatedBy, &s.CreatedAt, &s.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan chat sanction: %w", err)
+		}
+		sanctions = append(sanctions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return sanctions, nil
+}
+
+func (p *Postgres) SetChatRoomSlowMode(ctx context.Context, roomID, seconds int) error {
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE chat_rooms SET slow_mode_seconds = $2 WHERE id = $1`, roomID, seconds)
+	if err != nil {
+		return fmt.Errorf("failed to set slow mode: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ChatSlowModeWait returns how long the user still has to wait before
+// posting to the room again; zero or less means they may post now.
+func (p *Postgres) ChatSlowModeWait(ctx context.Context, roomID, userID int) (time.Duration, error) {
+	var seconds float64
+	err := p.db.QueryRowContext(ctx, `
+        SELECT COALESCE(EXTRACT(EPOCH FROM
+            (SELECT MAX(m.created_at) FROM chat_messages m WHERE m.room_id = r.id AND m.user_id = $2)
+            + make_interval(secs => r.slow_mode_seconds) - NOW()), 0)
+        FROM chat_rooms r
+        WHERE r.id = $1 AND r.slow_mode_seconds > 0
+    `, roomID, userID).Scan(&seconds)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check slow mode: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
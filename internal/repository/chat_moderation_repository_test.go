@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresChatSanctions(t *testing.T) {
+	repo, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
+	}
+
+	ctx := context.Background()
+	userID := int(time.Now().UnixNano() % 1000000)
+	mute := &entity.ChatSanction{UserID: userID, Kind: entity.ChatSanctionMute, Reason: "spam", CreatedBy: 1}
+	assert.NoError(t, repo.SetChatSanction(ctx, mute, time.Hour))
+	assert.NotNil(t, mute.ExpiresAt)
+
+	// Повторная выдача заменяет санкцию: теперь бессрочно.
+	assert.NoError(t, repo.SetChatSanction(ctx, mute, 0))
+	assert.Nil(t, mute.ExpiresAt)
+
+	sanctions, err := repo.GetUserChatSanctions(ctx, userID)
+	assert.NoError(t, err)
+	assert.Len(t, sanctions, 1)
+
+	assert.NoError(t, repo.DeleteChatSanction(ctx, userID, entity.ChatSanctionMute))
+	assert.ErrorIs(t, repo.DeleteChatSanction(ctx, userID, entity.ChatSanctionMute), ErrNotFound)
+
+	sanctions, err = repo.GetUserChatSanctions(ctx, userID)
+	assert.NoError(t, err)
+	assert.Empty(t, sanctions)
+}
+
+func TestPostgresChatSlowMode(t *testing.T) {
+	repo, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
+	}
+
+	ctx := context.Background()
+	room := &entity.ChatRoom{Name: fmt.Sprintf("slow_%d", time.Now().UnixNano()), CreatedBy: 1}
+	assert.NoError(t, repo.CreateChatRoom(ctx, room))
+
+	wait, err := repo.ChatSlowModeWait(ctx, room.ID, 1)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, wait, time.Duration(0))
+
+	assert.NoError(t, repo.SetChatRoomSlowMode(ctx, room.ID, 60))
+	assert.NoError(t, repo.SaveChatMessage(ctx, &entity.ChatMessage{RoomID: room.ID, UserID: 1, Author: "testuser", Text: "first"}))
+	wait, err = repo.ChatSlowModeWait(ctx, room.ID, 1)
+	assert.NoError(t, err)
+	assert.Greater(t, wait, 50*time.Second)
+
+	assert.ErrorIs(t, repo.SetChatRoomSlowMode(ctx, -1, 60), ErrNotFound)
+}
@@ -2,29 +2,74 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/perfect1337/forum-service/internal/entity"
 )
 
 type ChatRepository interface {
 	CreateChatMessage(ctx context.Context, message *entity.ChatMessage) error
-	GetChatMessages(ctx context.Context, limit int) ([]entity.ChatMessage, error)
+	GetChatMessages(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error)
+	GetChatMessageByID(ctx context.Context, id int) (*entity.ChatMessage, error)
 	SaveChatMessage(ctx context.Context, message *entity.ChatMessage) error
-	DeleteOldChatMessages(ctx context.Context, olderThan time.Duration) error
+	UpdateChatMessageText(ctx context.Context, id int, text string, maxAge time.Duration) (*entity.ChatMessage, error)
+	DeleteChatMessage(ctx context.Context, id int, maxAge time.Duration) error
 }
 
-func (p *Postgres) DeleteOldChatMessages(ctx context.Context, olderThan time.Duration) error {
-	query := `DELETE FROM chat_messages WHERE created_at < NOW() - $1::interval`
-	_, err := p.db.ExecContext(ctx, query, olderThan.String())
-	return err
+// ChatRetentionRepository deletes room messages that have outlived their
+// retention period. Direct messages are not affected.
+type ChatRetentionRepository interface {
+	DeleteChatMessagesOlderThan(ctx context.Context, age time.Duration, exceptRooms []int) (int64, error)
+	DeleteRoomChatMessagesOlderThan(ctx context.Context, roomID int, age time.Duration) (int64, error)
+}
+
+type ChatRoomRepository interface {
+	CreateChatRoom(ctx context.Context, room *entity.ChatRoom) error
+	GetChatRooms(ctx context.Context) ([]entity.ChatRoom, error)
+	GetChatRoomByID(ctx context.Context, id int) (*entity.ChatRoom, error)
+	JoinChatRoom(ctx context.Context, roomID, userID int) error
+	IsChatRoomMember(ctx context.Context, roomID, userID int) (bool, error)
+}
+
+// DeleteChatMessagesOlderThan removes messages older than age from every room
+// except exceptRooms, which have their own retention. Age is measured by the
+// database clock, which also fills created_at.
+func (p *Postgres) DeleteChatMessagesOlderThan(ctx context.Context, age time.Duration, exceptRooms []int) (int64, error) {
+	if exceptRooms == nil {
+		exceptRooms = []int{}
+	}
+	res, err := p.db.ExecContext(ctx, `
+        DELETE FROM chat_messages
+        WHERE created_at < NOW() - make_interval(secs => $1::float8) AND NOT (room_id = ANY($2))
+    `, age.Seconds(), pq.Array(exceptRooms))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old chat messages: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteRoomChatMessagesOlderThan removes messages older than age from one
+// room.
+func (p *Postgres) DeleteRoomChatMessagesOlderThan(ctx context.Context, roomID int, age time.Duration) (int64, error) {
+	res, err := p.db.ExecContext(ctx, `
+        DELETE FROM chat_messages
+        WHERE room_id = $1 AND created_at < NOW() - make_interval(secs => $2::float8)
+    `, roomID, age.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old room messages: %w", err)
+	}
+	return res.RowsAffected()
 }
 
 func (p *Postgres) CreateChatMessage(ctx context.Context, message *entity.ChatMessage) error {
-	query := `INSERT INTO chat_messages (user_id, author, text, created_at) 
-              VALUES ($1, $2, $3, $4) RETURNING id`
+	query := `INSERT INTO chat_messages (room_id, user_id, author, text, created_at) 
+              VALUES ($1, $2, $3, $4, $5) RETURNING id`
 	return p.db.QueryRowContext(ctx, query,
+		chatRoomOrDefault(message.RoomID),
 		message.UserID,
 		message.Author,
 		message.Text,
@@ -32,15 +77,26 @@ func (p *Postgres) CreateChatMessage(ctx context.Context, message *entity.ChatMe
 	).Scan(&message.ID)
 }
 
-func (p *Postgres) GetChatMessages(ctx context.Context, limit int) ([]entity.ChatMessage, error) {
+// GetChatMessages returns a page of a room's messages. Pages are ordered
+// newest first unless filter.AfterID is set, in which case they go forward
+// from it.
+func (p *Postgres) GetChatMessages(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
+	order := "DESC"
+	if filter.AfterID > 0 {
+		order = "ASC"
+	}
 	query := `
-        SELECT id, user_id, author, text, created_at 
+        SELECT id, room_id, user_id, author, text, created_at, edited_at
         FROM chat_messages 
-        ORDER BY created_at DESC 
-        LIMIT $1
+        WHERE room_id = $1
+          AND ($2 = 0 OR id < $2)
+          AND ($3 = 0 OR id > $3)
+        ORDER BY id ` + order + `
+        LIMIT $4
     `
 
-	rows, err := p.db.QueryContext(ctx, query, limit)
+	rows, err := p.db.QueryContext(ctx, query,
+		chatRoomOrDefault(filter.RoomID), filter.BeforeID, filter.AfterID, filter.Limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chat messages: %w", err)
 	}
@@ -49,7 +105,7 @@ func (p *Postgres) GetChatMessages(ctx context.Context, limit int) ([]entity.Cha
 	var messages []entity.ChatMessage
 	for rows.Next() {
 		var msg entity.ChatMessage
-		if err := rows.Scan(&msg.ID, &msg.UserID, &msg.Author, &msg.Text, &msg.CreatedAt); err != nil {
+		if err := rows.Scan(&msg.ID, &msg.RoomID, &msg.UserID, &msg.Author, &msg.Text, &msg.CreatedAt, &msg.EditedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan chat message: %w", err)
 		}
 		messages = append(messages, msg)
@@ -57,16 +113,34 @@ func (p *Postgres) GetChatMessages(ctx context.Context, limit int) ([]entity.Cha
 
 	return messages, nil
 }
+
+func (p *Postgres) GetChatMessageByID(ctx context.Context, id int) (*entity.ChatMessage, error) {
+	var msg entity.ChatMessage
+	err := p.db.QueryRowContext(ctx, `
+        SELECT id, room_id, user_id, author, text, created_at, edited_at
+        FROM chat_messages WHERE id = $1
+    `, id).Scan(&msg.ID, &msg.RoomID, &msg.UserID, &msg.Author, &msg.Text, &msg.CreatedAt, &msg.EditedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat message: %w", err)
+	}
+	return &msg, nil
+}
+
 func (p *Postgres) SaveChatMessage(ctx context.Context, message *entity.ChatMessage) error {
+	message.RoomID = chatRoomOrDefault(message.RoomID)
 	query := `
-        INSERT INTO chat_messages (user_id, author, text, created_at)
-        VALUES ($1, $2, $3, NOW())
+        INSERT INTO chat_messages (room_id, user_id, author, text, created_at)
+        VALUES ($1, $2, $3, $4, NOW())
         RETURNING id, created_at
     `
 
 	err := p.db.QueryRowContext(
 		ctx,
 		query,
+		message.RoomID,
 		message.UserID,
 		message.Author,
 		message.Text,
@@ -78,3 +152,143 @@ func (p *Postgres) SaveChatMessage(ctx context.Context, message *entity.ChatMess
 
 	return nil
 }
+
+// UpdateChatMessageText replaces the text of a message, stamps edited_at and
+// returns the updated message. A message older than maxAge by the database
+// clock is left alone and reported as ErrNotFound; zero means no limit.
+func (p *Postgres) UpdateChatMessageText(ctx context.Context, id int, text string, maxAge time.Duration) (*entity.ChatMessage, error) {
+	var msg entity.ChatMessage
+	err := p.db.QueryRowContext(ctx, `
+        UPDATE chat_messages SET text = $2, edited_at = NOW()
+        WHERE id = $1 AND ($3::float8 <= 0 OR created_at > NOW() - make_interval(secs => $3::float8))
+        RETURNING id, room_id, user_id, author, text, created_at, edited_at
+    `, id, text, maxAge.Seconds()).Scan(&msg.ID, &msg.RoomID, &msg.UserID, &msg.Author, &msg.Text, &msg.CreatedAt, &msg.EditedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update chat message: %w", err)
+	}
+	return &msg, nil
+}
+
+// DeleteChatMessage removes a message. Like UpdateChatMessageText, it reports
+// a message older than maxAge as ErrNotFound; zero means no limit.
+func (p *Postgres) DeleteChatMessage(ctx context.Context, id int, maxAge time.Duration) error {
+	result, err := p.db.ExecContext(ctx, `
+        DELETE FROM chat_messages
+        WHERE id = $1 AND ($2::float8 <= 0 OR created_at > NOW() - make_interval(secs => $2::float8))
+    `, id, maxAge.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to delete chat message: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func chatRoomOrDefault(roomID int) int {
+	if roomID == 0 {
+		return entity.DefaultChatRoomID
+	}
+	return roomID
+}
+
+// CreateChatRoom creates a room and makes its creator the first member.
+func (p *Postgres) CreateChatRoom(ctx context.Context, room *entity.ChatRoom) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO chat_rooms (name, created_by) VALUES ($1, $2)
+        RETURNING id, created_at
+    `, room.Name, room.CreatedBy).Scan(&room.ID, &room.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create chat room: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO chat_room_members (room_id, user_id) VALUES ($1, $2)`, room.ID, room.CreatedBy); err != nil {
+		return fmt.Errorf("failed to add room creator: %w", err)
+	}
+	room.MemberCount = 1
+
+	return tx.Commit()
+}
+
+func (p *Postgres) GetChatRooms(ctx context.Context) ([]entity.ChatRoom, error) {
+	rows, err := p.db.QueryContext(ctx, `
+        SELECT r.id, r.name, r.created_by, r.slow_mode_seconds, r.created_at,
+            (SELECT COUNT(*) FROM chat_room_members m WHERE m.room_id = r.id)
+        FROM chat_rooms r
+        ORDER BY r.id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chat rooms: %w", err)
+	}
+	defer rows.Close()
+
+	var rooms []entity.ChatRoom
+	for rows.Next() {
+		var room entity.ChatRoom
+		if err := rows.Scan(&room.ID, &room.Name, &room.CreatedBy, &room.SlowModeSeconds, &room.CreatedAt, &room.MemberCount); err != nil {
+			return nil, fmt.Errorf("failed to scan chat room: %w", err)
+		}
+		rooms = append(rooms, room)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return rooms, nil
+}
+
+func (p *Postgres) GetChatRoomByID(ctx context.Context, id int) (*entity.ChatRoom, error) {
+	var room entity.ChatRoom
+	err := p.db.QueryRowContext(ctx, `
+        SELECT r.id, r.name, r.created_by, r.slow_mode_seconds, r.created_at,
+            (SELECT COUNT(*) FROM chat_room_members m WHERE m.room_id = r.id)
+        FROM chat_rooms r
+        WHERE r.id = $1
+    `, id).Scan(&room.ID, &room.Name, &room.CreatedBy, &room.SlowModeSeconds, &room.CreatedAt, &room.MemberCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat room: %w", err)
+	}
+	return &room, nil
+}
+
+// JoinChatRoom adds the user to the room; joining twice is a no-op.
+func (p *Postgres) JoinChatRoom(ctx context.Context, roomID, userID int) error {
+	_, err := p.db.ExecContext(ctx, `
+        INSERT INTO chat_room_members (room_id, user_id) VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `, roomID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to join chat room: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) IsChatRoomMember(ctx context.Context, roomID, userID int) (bool, error) {
+	var member bool
+	err := p.db.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM chat_room_members WHERE room_id = $1 AND user_id = $2)
+    `, roomID, userID).Scan(&member)
+	if err != nil {
+		return false, fmt.Errorf("failed to check room membership: %w", err)
+	}
+	return member, nil
+}
@@ -24,6 +24,7 @@ func setupTestDB() (*Postgres, error) {
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS chat_messages (
 			id SERIAL PRIMARY KEY,
+			room_id INTEGER NOT NULL DEFAULT 1,
 			user_id INTEGER,
 			author VARCHAR(255),
 			text TEXT,
@@ -33,6 +34,9 @@ func setupTestDB() (*Postgres, error) {
 	if err != nil {
 		return nil, fmt.Errorf("не удалось создать тестовую таблицу: %v", err)
 	}
+	if _, err = db.Exec(`ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP`); err != nil {
+		return nil, fmt.Errorf("не удалось обновить тестовую таблицу: %v", err)
+	}
 
 	return &Postgres{db: db}, nil
 }
@@ -73,11 +77,45 @@ func TestPostgresGetChatMessages(t *testing.T) {
 		t.Fatalf("не удалось вставить тестовые данные: %v", err)
 	}
 
-	messages, err := repo.GetChatMessages(ctx, limit)
+	messages, err := repo.GetChatMessages(ctx, entity.ChatHistoryFilter{RoomID: 1, Limit: limit})
 	assert.NoError(t, err)
 	assert.NotEmpty(t, messages)
 }
 
+func TestPostgresGetChatMessagesCursors(t *testing.T) {
+	repo, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
+	}
+
+	ctx := context.Background()
+	room := &entity.ChatRoom{Name: fmt.Sprintf("cursors_%d", time.Now().UnixNano()), CreatedBy: 1}
+	if err := repo.CreateChatRoom(ctx, room); err != nil {
+		t.Fatalf("не удалось создать комнату: %v", err)
+	}
+	var ids []int
+	for i := 0; i < 5; i++ {
+		msg := &entity.ChatMessage{RoomID: room.ID, UserID: 1, Author: "testuser", Text: fmt.Sprintf("m%d", i)}
+		if err := repo.SaveChatMessage(ctx, msg); err != nil {
+			t.Fatalf("не удалось сохранить сообщение: %v", err)
+		}
+		ids = append(ids, msg.ID)
+	}
+
+	older, err := repo.GetChatMessages(ctx, entity.ChatHistoryFilter{RoomID: room.ID, BeforeID: ids[3], Limit: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, older, 2) {
+		assert.Equal(t, ids[2], older[0].ID, "before pages go newest first")
+		assert.Equal(t, ids[1], older[1].ID)
+	}
+
+	newer, err := repo.GetChatMessages(ctx, entity.ChatHistoryFilter{RoomID: room.ID, AfterID: ids[1], Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, newer, 3) {
+		assert.Equal(t, ids[2], newer[0].ID, "after pages go oldest first")
+	}
+}
+
 func TestPostgresSaveChatMessage(t *testing.T) {
 	repo, err := setupTestDB()
 	if err != nil {
@@ -95,26 +133,98 @@ func TestPostgresSaveChatMessage(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotZero(t, message.ID)
 	assert.NotZero(t, message.CreatedAt)
+	assert.Equal(t, entity.DefaultChatRoomID, message.RoomID)
 }
 
-func TestPostgresDeleteOldChatMessages(t *testing.T) {
+func TestPostgresEditAndDeleteChatMessage(t *testing.T) {
 	repo, err := setupTestDB()
 	if err != nil {
 		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
 	}
 
 	ctx := context.Background()
-	olderThan := time.Hour * 24
+	message := &entity.ChatMessage{UserID: 1, Author: "testuser", Text: "typo"}
+	assert.NoError(t, repo.SaveChatMessage(ctx, message))
 
-	// Вставка тестовых данных
+	edited, err := repo.UpdateChatMessageText(ctx, message.ID, "fixed", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "fixed", edited.Text)
+	assert.NotNil(t, edited.EditedAt)
+
+	// Окно правки считается по часам базы
+	_, err = repo.db.ExecContext(ctx, `UPDATE chat_messages SET created_at = NOW() - INTERVAL '1 hour' WHERE id = $1`, message.ID)
+	assert.NoError(t, err)
+	_, err = repo.UpdateChatMessageText(ctx, message.ID, "late", time.Minute)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteChatMessage(ctx, message.ID, time.Minute), ErrNotFound)
+
+	assert.NoError(t, repo.DeleteChatMessage(ctx, message.ID, 0))
+	assert.ErrorIs(t, repo.DeleteChatMessage(ctx, message.ID, 0), ErrNotFound)
+	_, err = repo.UpdateChatMessageText(ctx, message.ID, "again", 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPostgresChatRooms(t *testing.T) {
+	repo, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
+	}
+
+	ctx := context.Background()
+	room := &entity.ChatRoom{Name: fmt.Sprintf("room_%d", time.Now().UnixNano()), CreatedBy: 1}
+	err = repo.CreateChatRoom(ctx, room)
+	assert.NoError(t, err)
+	assert.NotZero(t, room.ID)
+
+	err = repo.CreateChatRoom(ctx, &entity.ChatRoom{Name: room.Name, CreatedBy: 2})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	member, err := repo.IsChatRoomMember(ctx, room.ID, 1)
+	assert.NoError(t, err)
+	assert.True(t, member)
+
+	assert.NoError(t, repo.JoinChatRoom(ctx, room.ID, 2))
+	assert.NoError(t, repo.JoinChatRoom(ctx, room.ID, 2))
+	got, err := repo.GetChatRoomByID(ctx, room.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got.MemberCount)
+
+	message := &entity.ChatMessage{RoomID: room.ID, UserID: 2, Author: "testuser", Text: "in room"}
+	assert.NoError(t, repo.SaveChatMessage(ctx, message))
+	messages, err := repo.GetChatMessages(ctx, entity.ChatHistoryFilter{RoomID: room.ID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+}
+
+func TestPostgresDeleteChatMessagesOlderThan(t *testing.T) {
+	repo, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
+	}
+
+	ctx := context.Background()
+	room := &entity.ChatRoom{Name: fmt.Sprintf("retention_%d", time.Now().UnixNano()), CreatedBy: 1}
+	if err := repo.CreateChatRoom(ctx, room); err != nil {
+		t.Fatalf("не удалось создать комнату: %v", err)
+	}
+
+	// Вставка тестовых данных: по старому сообщению в общей и отдельной комнате
 	_, err = repo.db.ExecContext(ctx, `
-		INSERT INTO chat_messages (user_id, author, text, created_at)
-		VALUES (1, 'testuser', 'Hello, World!', NOW() - INTERVAL '25 hours')
-	`)
+		INSERT INTO chat_messages (room_id, user_id, author, text, created_at)
+		VALUES (1, 1, 'testuser', 'old general', NOW() - INTERVAL '25 hours'),
+		       ($1, 1, 'testuser', 'old room', NOW() - INTERVAL '25 hours')
+	`, room.ID)
 	if err != nil {
 		t.Fatalf("не удалось вставить тестовые данные: %v", err)
 	}
 
-	err = repo.DeleteOldChatMessages(ctx, olderThan)
+	_, err = repo.DeleteChatMessagesOlderThan(ctx, 24*time.Hour, []int{room.ID})
+	assert.NoError(t, err)
+	messages, err := repo.GetChatMessages(ctx, entity.ChatHistoryFilter{RoomID: room.ID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1, "room with its own retention must be skipped")
+
+	deleted, err := repo.DeleteRoomChatMessagesOlderThan(ctx, room.ID, 24*time.Hour)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
 }
@@ -11,15 +11,17 @@ import (
 type CommentRepository interface {
 	CreateComment(ctx context.Context, comment *entity.Comment) error
 	GetCommentsByPostID(ctx context.Context, postID int) ([]entity.Comment, error)
+	GetCommentByID(ctx context.Context, id int) (*entity.Comment, error)
+	UpdateComment(ctx context.Context, commentID, userID int, content string) (*entity.Comment, error)
 	DeleteComment(ctx context.Context, commentID int, userID string) error
 }
 
 func (p *Postgres) CreateComment(ctx context.Context, comment *entity.Comment) error {
-	query := `INSERT INTO comments (content, post_id, user_id) 
-				VALUES ($1, $2, $3)
+	query := `INSERT INTO comments (content, post_id, user_id, parent_id) 
+				VALUES ($1, $2, $3, $4)
 				RETURNING id, created_at`
 	return p.db.QueryRowContext(ctx, query,
-		comment.Content, comment.PostID, comment.UserID).
+		comment.Content, comment.PostID, comment.UserID, comment.ParentID).
 		Scan(&comment.ID, &comment.CreatedAt)
 }
 
@@ -29,12 +31,16 @@ func (p *Postgres) GetCommentsByPostID(ctx context.Context, postID int) ([]entit
 				c.id, 
 				c.content, 
 				c.post_id, 
+				c.parent_id,
 				c.user_id, 
-				u.username AS author,
-				c.created_at
+				COALESCE(u.username, '') AS author,
+				c.score,
+				c.created_at,
+				c.edited_at
 			FROM comments c
-			JOIN users u ON c.user_id = u.id
-			WHERE c.post_id = $1
+			LEFT JOIN users u ON c.user_id = u.id
+			JOIN posts p ON c.post_id = p.id
+			WHERE c.post_id = $1 AND c.deleted_at IS NULL AND p.deleted_at IS NULL
 			ORDER BY c.created_at
 		`
 	rows, err := p.db.QueryContext(ctx, query, postID)
@@ -50,9 +56,12 @@ func (p *Postgres) GetCommentsByPostID(ctx context.Context, postID int) ([]entit
 			&comment.ID,
 			&comment.Content,
 			&comment.PostID,
+			&comment.ParentID,
 			&comment.UserID,
 			&comment.Author,
+			&comment.Score,
 			&comment.CreatedAt,
+			&comment.EditedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan comment: %w", err)
 		}
@@ -63,8 +72,68 @@ func (p *Postgres) GetCommentsByPostID(ctx context.Context, postID int) ([]entit
 	}
 	return comments, nil
 }
+
+func (p *Postgres) GetCommentByID(ctx context.Context, id int) (*entity.Comment, error) {
+	query := `
+			SELECT c.id, c.content, c.post_id, c.parent_id, c.user_id, COALESCE(u.username, ''), c.score, c.created_at, c.edited_at
+			FROM comments c
+			LEFT JOIN users u ON c.user_id = u.id
+			WHERE c.id = $1 AND c.deleted_at IS NULL
+		`
+	var comment entity.Comment
+	err := p.db.QueryRowContext(ctx, query, id).Scan(
+		&comment.ID,
+		&comment.Content,
+		&comment.PostID,
+		&comment.ParentID,
+		&comment.UserID,
+		&comment.Author,
+		&comment.Score,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment by ID: %w", err)
+	}
+	return &comment, nil
+}
+
+// UpdateComment replaces the text of the user's own comment and marks it as
+// edited. Like DeleteComment, it returns sql.ErrNoRows when there is no such
+// comment or it belongs to someone else.
+func (p *Postgres) UpdateComment(ctx context.Context, commentID, userID int, content string) (*entity.Comment, error) {
+	query := `
+			WITH updated AS (
+				UPDATE comments SET content = $3, edited_at = NOW()
+				WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+				RETURNING id, content, post_id, parent_id, user_id, score, created_at, edited_at
+			)
+			SELECT c.id, c.content, c.post_id, c.parent_id, c.user_id, COALESCE(u.username, ''), c.score, c.created_at, c.edited_at
+			FROM updated c
+			LEFT JOIN users u ON c.user_id = u.id
+		`
+	var comment entity.Comment
+	err := p.db.QueryRowContext(ctx, query, commentID, userID, content).Scan(
+		&comment.ID,
+		&comment.Content,
+		&comment.PostID,
+		&comment.ParentID,
+		&comment.UserID,
+		&comment.Author,
+		&comment.Score,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+	return &comment, nil
+}
+
+// DeleteComment moves the user's own comment to the trash.
 func (p *Postgres) DeleteComment(ctx context.Context, commentID int, userID int) error {
-	query := `DELETE FROM comments WHERE id = $1 AND user_id = $2`
+	query := `UPDATE comments SET deleted_at = NOW(), deleted_by = $2
+              WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 
 	result, err := p.db.ExecContext(ctx, query, commentID, userID)
 	if err != nil {
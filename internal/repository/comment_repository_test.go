@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/perfect1337/forum-service/internal/entity"
@@ -114,3 +115,49 @@ func TestPostgresDeleteComment(t *testing.T) {
 	err = repo.DeleteComment(ctx, commentID, userID)
 	assert.NoError(t, err)
 }
+
+func TestPostgresUpdateComment(t *testing.T) {
+	repo, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
+	}
+
+	ctx := context.Background()
+	comment := &entity.Comment{Content: "Before", PostID: 1, UserID: 1}
+	if err := repo.CreateComment(ctx, comment); err != nil {
+		t.Fatalf("не удалось создать комментарий: %v", err)
+	}
+
+	updated, err := repo.UpdateComment(ctx, comment.ID, 1, "After")
+	assert.NoError(t, err)
+	assert.Equal(t, "After", updated.Content)
+	assert.NotNil(t, updated.EditedAt)
+	assert.NotEmpty(t, updated.Author)
+
+	// Чужой комментарий не меняется
+	_, err = repo.UpdateComment(ctx, comment.ID, 2, "Hijacked")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestPostgresCreateReply(t *testing.T) {
+	repo, err := setupTestDB()
+	if err != nil {
+		t.Fatalf("не удалось настроить тестовую базу данных: %v", err)
+	}
+
+	ctx := context.Background()
+	parent := &entity.Comment{Content: "Parent comment", PostID: 1, UserID: 1}
+	err = repo.CreateComment(ctx, parent)
+	assert.NoError(t, err)
+
+	reply := &entity.Comment{Content: "Reply", PostID: 1, UserID: 1, ParentID: &parent.ID}
+	err = repo.CreateComment(ctx, reply)
+	assert.NoError(t, err)
+
+	got, err := repo.GetCommentByID(ctx, reply.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, got.ParentID) {
+		assert.Equal(t, parent.ID, *got.ParentID)
+	}
+	assert.Equal(t, 1, got.PostID)
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+type DirectMessageRepository interface {
+	SaveDirectMessage(ctx context.Context, msg *entity.DirectMessage) error
+	GetConversations(ctx context.Context, userID int) ([]entity.Conversation, error)
+	GetDirectMessages(ctx context.Context, userID, peerID, beforeID, limit int) ([]entity.DirectMessage, error)
+	GetDirectMessageByID(ctx context.Context, id int) (*entity.DirectMessage, error)
+	MarkDirectMessagesRead(ctx context.Context, userID, peerID int) error
+}
+
+// conversationPair orders two user IDs the way dm_conversations stores them.
+func conversationPair(a, b int) (int, int) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
+
+// SaveDirectMessage stores the message, creating the conversation on the
+// first message between the two users.
+func (p *Postgres) SaveDirectMessage(ctx context.Context, msg *entity.DirectMessage) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	userA, userB := conversationPair(msg.SenderID, msg.RecipientID)
+	// DO UPDATE вместо DO NOTHING, чтобы RETURNING вернул id существующего диалога
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO dm_conversations (user_a, user_b) VALUES ($1, $2)
+        ON CONFLICT (user_a, user_b) DO UPDATE SET last_message_at = NOW()
+        RETURNING id
+    `, userA, userB).Scan(&msg.ConversationID)
+	if err != nil {
+		return fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO direct_messages (conversation_id, sender_id, recipient_id, author, text)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at
+    `, msg.ConversationID, msg.SenderID, msg.RecipientID, msg.Author, msg.Text).Scan(&msg.ID, &msg.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save direct message: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetConversations lists the user's conversations, most recently active
+// first, with the last message and the number of unread messages in each.
+func (p *Postgres) GetConversations(ctx context.Context, userID int) ([]entity.Conversation, error) {
+	rows, err := p.db.QueryContext(ctx, `
+        SELECT c.id,
+            CASE WHEN c.user_a = $1 THEN c.user_b ELSE c.user_a END AS peer_id,
+            COALESCE(u.username, ''),
+            c.last_message_at,
+            m.id, m.sender_id, m.recipient_id, m.author, m.text, m.created_at, m.read_at,
+            (SELECT COUNT(*) FROM direct_messages d
+             WHERE d.conversation_id = c.id AND d.recipient_id = $1 AND d.read_at IS NULL)
+        FROM dm_conversations c
+        LEFT JOIN users u ON u.id = CASE WHEN c.user_a = $1 THEN c.user_b ELSE c.user_a END
+        JOIN LATERAL (
+            SELECT * FROM direct_messages d WHERE d.conversation_id = c.id ORDER BY d.id DESC LIMIT 1
+        ) m ON TRUE
+        WHERE c.user_a = $1 OR c.user_b = $1
+        ORDER BY c.last_message_at DESC, c.id DESC
+    `, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []entity.Conversation
+	for rows.Next() {
+		var conv entity.Conversation
+		last := entity.DirectMessage{}
+		if err := rows.Scan(
+			&conv.ID, &conv.PeerID, &conv.PeerUsername, &conv.UpdatedAt,
+			&last.ID, &last.SenderID, &last.RecipientID, &last.Author, &last.Text, &last.CreatedAt, &last.ReadAt,
+			&conv.UnreadCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		last.ConversationID = conv.ID
+		conv.LastMessage = &last
+		conversations = append(conversations, conv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return conversations, nil
+}
+
+// GetDirectMessages returns up to limit messages between the two users,
+// newest first. A positive beforeID returns only messages older than it.
+func (p *Postgres) GetDirectMessages(ctx context.Context, userID, peerID, beforeID, limit int) ([]entity.DirectMessage, error) {
+	userA, userB := conversationPair(userID, peerID)
+	rows, err := p.db.QueryContext(ctx, `
+        SELECT m.id, m.conversation_id, m.sender_id, m.recipient_id, m.author, m.text, m.created_at, m.read_at
+        FROM direct_messages m
+        JOIN dm_conversations c ON c.id = m.conversation_id
+        WHERE c.user_a = $1 AND c.user_b = $2 AND ($3 = 0 OR m.id < $3)
+        ORDER BY m.id DESC
+        LIMIT $4
+    `, userA, userB, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query direct messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []entity.DirectMessage
+	for rows.Next() {
+		var msg entity.DirectMessage
+		if err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.RecipientID,
+			&msg.Author, &msg.Text, &msg.CreatedAt, &msg.ReadAt); err != nil {
+			return nil, fmt.Errorf("failed to scan direct message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return messages, nil
+}
+
+func (p *Postgres) GetDirectMessageByID(ctx context.Context, id int) (*entity.DirectMessage, error) {
+	var msg entity.DirectMessage
+	err := p.db.QueryRowContext(ctx, `
+        SELECT id, conversation_id, sender_id, recipient_id, author, text, created_at, read_at
+        FROM direct_messages WHERE id = $1
+    `, id).Scan(&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.RecipientID,
+		&msg.Author, &msg.Text, &msg.CreatedAt, &msg.ReadAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get direct message: %w", err)
+	}
+	return &msg, nil
+}
+
+// MarkDirectMessagesRead marks every message peerID sent to userID as read.
+func (p *Postgres) MarkDirectMessagesRead(ctx context.Context, userID, peerID int) error {
+	_, err := p.db.ExecContext(ctx, `
+        UPDATE direct_messages SET read_at = NOW()
+        WHERE recipient_id = $1 AND sender_id = $2 AND read_at IS NULL
+    `, userID, peerID)
+	if err != nil {
+		return fmt.Errorf("failed to mark direct messages read: %w", err)
+	}
+	return nil
+}
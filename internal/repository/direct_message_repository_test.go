@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDirectMessages(t *testing.T) {
+	repo, err := setupTestDB()
+	require.NoError(t, err, "Failed to setup test database")
+
+	ctx := context.Background()
+	// Уникальная пара пользователей, чтобы не пересекаться с прошлыми запусками
+	alice := int(time.Now().UnixNano() % 1_000_000_000)
+	bob := alice + 1
+
+	first := &entity.DirectMessage{SenderID: alice, RecipientID: bob, Author: "alice", Text: "hi bob"}
+	require.NoError(t, repo.SaveDirectMessage(ctx, first))
+	assert.NotZero(t, first.ConversationID)
+
+	reply := &entity.DirectMessage{SenderID: bob, RecipientID: alice, Author: "bob", Text: "hi alice"}
+	require.NoError(t, repo.SaveDirectMessage(ctx, reply))
+	assert.Equal(t, first.ConversationID, reply.ConversationID, "both directions share one conversation")
+
+	second := &entity.DirectMessage{SenderID: alice, RecipientID: bob, Author: "alice", Text: "how are you?"}
+	require.NoError(t, repo.SaveDirectMessage(ctx, second))
+
+	conversations, err := repo.GetConversations(ctx, bob)
+	require.NoError(t, err)
+	require.Len(t, conversations, 1)
+	assert.Equal(t, alice, conversations[0].PeerID)
+	assert.Equal(t, 2, conversations[0].UnreadCount)
+	assert.Equal(t, second.ID, conversations[0].LastMessage.ID)
+
+	messages, err := repo.GetDirectMessages(ctx, bob, alice, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, second.ID, messages[0].ID)
+
+	older, err := repo.GetDirectMessages(ctx, bob, alice, messages[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, older, 1)
+	assert.Equal(t, first.ID, older[0].ID)
+
+	require.NoError(t, repo.MarkDirectMessagesRead(ctx, bob, alice))
+	conversations, err = repo.GetConversations(ctx, bob)
+	require.NoError(t, err)
+	assert.Zero(t, conversations[0].UnreadCount)
+}
@@ -3,19 +3,28 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"html"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/perfect1337/forum-service/internal/config"
 	"github.com/perfect1337/forum-service/internal/entity"
 )
 
 type PostRepository interface {
 	CreatePost(ctx context.Context, post *entity.Post) error
-	GetAllPosts(ctx context.Context) ([]*entity.Post, error)
+	GetAllPosts(ctx context.Context, filter entity.PostFilter) ([]*entity.Post, error)
 	GetPostByID(ctx context.Context, id int) (*entity.Post, error)
-	DeletePost(ctx context.Context, id int) error
-	UpdatePost(ctx context.Context, postID int, title, content string) error
+	GetPostsByIDs(ctx context.Context, ids []int) ([]*entity.Post, error)
+	DeletePost(ctx context.Context, id, deletedBy int) error
+	UpdatePost(ctx context.Context, postID, editorID int, title, content string) error
+}
+
+type SearchRepository interface {
+	Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error)
 }
 
 type Postgres struct {
@@ -24,13 +33,7 @@ type Postgres struct {
 }
 
 func NewPostgres(cfg *config.Config) (*Postgres, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.User,
-		cfg.Postgres.Password, cfg.Postgres.DBName, cfg.Postgres.SSLMode,
-	)
-
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", connString(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db: %w", err)
 	}
@@ -42,27 +45,138 @@ func NewPostgres(cfg *config.Config) (*Postgres, error) {
 	return &Postgres{db: db, cfg: cfg}, nil
 }
 
+// Ping checks that the database is still reachable.
+func (p *Postgres) Ping(ctx context.Context) error {
+	if err := p.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping db: %w", err)
+	}
+	return nil
+}
+
+func connString(cfg *config.Config) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.User,
+		cfg.Postgres.Password, cfg.Postgres.DBName, cfg.Postgres.SSLMode,
+	)
+}
+
+// CreatePost inserts the post together with its first revision.
 func (p *Postgres) CreatePost(ctx context.Context, post *entity.Post) error {
-	query := `INSERT INTO posts (title, content, user_id) VALUES ($1, $2, $3) 
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO posts (title, content, user_id, category_id) VALUES ($1, $2, $3, $4) 
               RETURNING id, created_at`
-	return p.db.QueryRowContext(ctx, query, post.Title, post.Content, post.UserID).
-		Scan(&post.ID, &post.CreatedAt)
+	if err := tx.QueryRowContext(ctx, query, post.Title, post.Content, post.UserID, post.CategoryID).
+		Scan(&post.ID, &post.CreatedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at)
+        VALUES ($1, 1, $2, $3, $4, $5)
+    `, post.ID, post.Title, post.Content, post.UserID, post.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	if err := insertPostTags(ctx, tx, post.ID, post.Tags); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (p *Postgres) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
+// GetAllPosts returns one page of the feed using keyset pagination on
+// (created_at, id). The caller asks for Limit rows; the use case requests one
+// extra row to find out whether a next page exists.
+func (p *Postgres) GetAllPosts(ctx context.Context, filter entity.PostFilter) ([]*entity.Post, error) {
+	var (
+		conds = []string{"p.deleted_at IS NULL"}
+		args  []interface{}
+	)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.AuthorID > 0 {
+		conds = append(conds, "p.user_id = "+arg(filter.AuthorID))
+	}
+	if filter.Author != "" {
+		conds = append(conds, "u.username = "+arg(filter.Author))
+	}
+	if filter.Category != "" {
+		conds = append(conds, "p.category_id = (SELECT id FROM categories WHERE slug = "+arg(filter.Category)+")")
+	}
+	if filter.Tag != "" {
+		conds = append(conds, `EXISTS (
+                SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+                WHERE pt.post_id = p.id AND t.name = `+arg(filter.Tag)+`)`)
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, "p.created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, "p.created_at < "+arg(filter.To))
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.Order == "asc" {
+		order, cmp = "ASC", ">"
+	}
+
+	// sortKey — выражение, по которому строится keyset; для курсора
+	// то же выражение вычисляется от значений последнего поста страницы.
+	var sortKey string
+	switch filter.Sort {
+	case entity.SortTop:
+		sortKey = "p.score"
+		if filter.After != nil {
+			conds = append(conds, fmt.Sprintf("(p.score, p.id) %s (%s, %s)",
+				cmp, arg(filter.After.Score), arg(filter.After.ID)))
+		}
+	case entity.SortHot:
+		sortKey = hotRankSQL("p.score", "p.created_at")
+		if filter.After != nil {
+			after := hotRankSQL(arg(filter.After.Score)+"::int", arg(filter.After.CreatedAt)+"::timestamp")
+			conds = append(conds, fmt.Sprintf("(%s, p.id) %s (%s, %s)",
+				sortKey, cmp, after, arg(filter.After.ID)))
+		}
+	default:
+		sortKey = "p.created_at"
+		if filter.After != nil {
+			conds = append(conds, fmt.Sprintf("(p.created_at, p.id) %s (%s, %s)",
+				cmp, arg(filter.After.CreatedAt), arg(filter.After.ID)))
+		}
+	}
+
+	// Локальная копия users может отставать от auth-service: пост без
+	// строки в users всё равно возвращается, с пустым автором
 	query := `
-            SELECT 
-            p.id, 
-            p.title, 
-            p.content, 
-            p.user_id, 
-            u.username AS author,  -- Получаем имя автора из users
-            p.created_at
+        SELECT
+            p.id,
+            p.title,
+            p.content,
+            p.user_id,
+            COALESCE(u.username, '') AS author,
+            p.score,
+            p.created_at,
+            p.edited_at,
+            p.category_id,
+            ` + postTagsSQL + ` AS tags
         FROM posts p
-        JOIN users u ON p.user_id = u.id  -- Важно: соединяем с таблицей users
-        ORDER BY p.created_at DESC
-    `
-	rows, err := p.db.QueryContext(ctx, query)
+        LEFT JOIN users u ON p.user_id = u.id`
+	query += "\n        WHERE " + strings.Join(conds, " AND ")
+	query += fmt.Sprintf("\n        ORDER BY %s %s, p.id %s", sortKey, order, order)
+	if filter.Limit > 0 {
+		query += "\n        LIMIT " + arg(filter.Limit)
+	}
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query posts: %w", err)
 	}
@@ -77,7 +191,11 @@ func (p *Postgres) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
 			&post.Content,
 			&post.UserID,
 			&post.Author, // Получаем username из таблицы users
+			&post.Score,
 			&post.CreatedAt,
+			&post.EditedAt,
+			&post.CategoryID,
+			pq.Array(&post.Tags),
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
@@ -88,12 +206,107 @@ func (p *Postgres) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
 	}
 	return posts, nil
 }
+
+// ts_headline marks matches with private-use characters instead of <mark>
+// so that the text around them can be HTML-escaped first.
+const (
+	searchMarkStart = "\uE000"
+	searchMarkStop  = "\uE001"
+
+	searchTitleOptions    = "HighlightAll=true, StartSel=" + searchMarkStart + ", StopSel=" + searchMarkStop
+	searchHeadlineOptions = "StartSel=" + searchMarkStart + ", StopSel=" + searchMarkStop + ", MaxWords=35, MinWords=15, MaxFragments=2"
+)
+
+var searchMarkReplacer = strings.NewReplacer(searchMarkStart, "<mark>", searchMarkStop, "</mark>")
+
+// highlightSearchText HTML-escapes ts_headline output and turns the match
+// markers into <mark> tags.
+func highlightSearchText(s string) string {
+	return searchMarkReplacer.Replace(html.EscapeString(s))
+}
+
+// Search runs a full-text query over posts and comments and returns hits
+// ordered by rank. Titles and snippets are HTML-escaped, with matched terms
+// wrapped in <mark> tags.
+func (p *Postgres) Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error) {
+	postsQuery := `
+        SELECT 'post' AS type, p.id, p.id AS post_id,
+            ts_headline('simple', p.title, q.query, '` + searchTitleOptions + `') AS title,
+            ts_headline('simple', p.content, q.query, $4) AS snippet,
+            ts_rank(p.search_vector, q.query) AS rank,
+            p.user_id, COALESCE(u.username, ''), p.created_at
+        FROM posts p
+        LEFT JOIN users u ON p.user_id = u.id, q
+        WHERE p.search_vector @@ q.query AND p.deleted_at IS NULL`
+	commentsQuery := `
+        SELECT 'comment' AS type, c.id, c.post_id,
+            p.title,
+            ts_headline('simple', c.content, q.query, $4) AS snippet,
+            ts_rank(c.search_vector, q.query) AS rank,
+            c.user_id, COALESCE(u.username, ''), c.created_at
+        FROM comments c
+        JOIN posts p ON c.post_id = p.id
+        LEFT JOIN users u ON c.user_id = u.id, q
+        WHERE c.search_vector @@ q.query
+            AND c.deleted_at IS NULL AND p.deleted_at IS NULL`
+
+	var union string
+	switch query.Type {
+	case entity.SearchTypePost:
+		union = postsQuery
+	case entity.SearchTypeComment:
+		union = commentsQuery
+	default:
+		union = postsQuery + "\n        UNION ALL" + commentsQuery
+	}
+
+	sqlQuery := `
+        WITH q AS (SELECT websearch_to_tsquery('simple', $1) AS query)
+        SELECT type, id, post_id, title, snippet, rank, user_id, username, created_at
+        FROM (` + union + `
+        ) results
+        ORDER BY rank DESC, created_at DESC, id DESC
+        LIMIT $2 OFFSET $3`
+
+	rows, err := p.db.QueryContext(ctx, sqlQuery, query.Query, query.Limit, query.Offset, searchHeadlineOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []entity.SearchResult
+	for rows.Next() {
+		var r entity.SearchResult
+		if err := rows.Scan(
+			&r.Type,
+			&r.ID,
+			&r.PostID,
+			&r.Title,
+			&r.Snippet,
+			&r.Rank,
+			&r.UserID,
+			&r.Author,
+			&r.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		r.Title = highlightSearchText(r.Title)
+		r.Snippet = highlightSearchText(r.Snippet)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return results, nil
+}
+
 func (p *Postgres) GetPostByID(ctx context.Context, id int) (*entity.Post, error) {
 	query := `
-        SELECT p.id, p.title, p.content, p.user_id, u.username, p.created_at
+        SELECT p.id, p.title, p.content, p.user_id, COALESCE(u.username, ''), p.score, p.created_at, p.edited_at,
+            p.category_id, ` + postTagsSQL + `
         FROM posts p
-        JOIN users u ON p.user_id = u.id
-        WHERE p.id = $1
+        LEFT JOIN users u ON p.user_id = u.id
+        WHERE p.id = $1 AND p.deleted_at IS NULL
     `
 	var post entity.Post
 	err := p.db.QueryRowContext(ctx, query, id).
@@ -103,7 +316,11 @@ func (p *Postgres) GetPostByID(ctx context.Context, id int) (*entity.Post, error
 			&post.Content,
 			&post.UserID,
 			&post.Author,
+			&post.Score,
 			&post.CreatedAt,
+			&post.EditedAt,
+			&post.CategoryID,
+			pq.Array(&post.Tags),
 		)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post by ID: %w", err)
@@ -111,14 +328,97 @@ func (p *Postgres) GetPostByID(ctx context.Context, id int) (*entity.Post, error
 	return &post, nil
 }
 
-func (p *Postgres) DeletePost(ctx context.Context, id int) error {
-	query := `DELETE FROM posts WHERE id = $1`
-	_, err := p.db.ExecContext(ctx, query, id)
-	return err
+// GetPostsByIDs returns the posts with the given IDs that exist and aren't in
+// the trash, in no particular order.
+func (p *Postgres) GetPostsByIDs(ctx context.Context, ids []int) ([]*entity.Post, error) {
+	query := `
+        SELECT p.id, p.title, p.content, p.user_id, COALESCE(u.username, ''), p.score, p.created_at, p.edited_at,
+            p.category_id, ` + postTagsSQL + `
+        FROM posts p
+        LEFT JOIN users u ON p.user_id = u.id
+        WHERE p.id = ANY($1) AND p.deleted_at IS NULL
+    `
+	rows, err := p.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts by IDs: %w", err)
+	}
+	defer rows.Close()
+
+	posts := make([]*entity.Post, 0, len(ids))
+	for rows.Next() {
+		var post entity.Post
+		if err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Content,
+			&post.UserID,
+			&post.Author,
+			&post.Score,
+			&post.CreatedAt,
+			&post.EditedAt,
+			&post.CategoryID,
+			pq.Array(&post.Tags),
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, &post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return posts, nil
+}
+
+// DeletePost moves the post to the trash. It stays there until an admin
+// restores it or the retention period runs out.
+func (p *Postgres) DeletePost(ctx context.Context, id, deletedBy int) error {
+	query := `UPDATE posts SET deleted_at = NOW(), deleted_by = $2
+              WHERE id = $1 AND deleted_at IS NULL`
+	result, err := p.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
-func (p *Postgres) UpdatePost(ctx context.Context, postID int, title, content string) error {
-	query := `UPDATE posts SET title = $1, content = $2 WHERE id = $3`
-	_, err := p.db.ExecContext(ctx, query, title, content, postID)
-	return err
+// UpdatePost changes the post text, stamps edited_at and records the new
+// text as the next revision. Returns ErrNotFound when the post is gone.
+func (p *Postgres) UpdatePost(ctx context.Context, postID, editorID int, title, content string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var editedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+        UPDATE posts SET title = $1, content = $2, edited_at = NOW()
+        WHERE id = $3 AND deleted_at IS NULL
+        RETURNING edited_at
+    `, title, content, postID).Scan(&editedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update post: %w", err)
+	}
+
+	// Строка поста заблокирована UPDATE'ом выше, поэтому MAX(revision)+1
+	// не гоняется с параллельным редактированием.
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at)
+        SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5
+        FROM post_revisions WHERE post_id = $1
+    `, postID, title, content, editorID, editedAt); err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	return tx.Commit()
 }
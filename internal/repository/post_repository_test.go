@@ -12,6 +12,15 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestPostgresPing(t *testing.T) {
+	repo, err := setupTestDB()
+	require.NoError(t, err)
+	assert.NoError(t, repo.Ping(context.Background()))
+
+	require.NoError(t, repo.db.Close())
+	assert.Error(t, repo.Ping(context.Background()))
+}
+
 func TestPostgresCreatePost(t *testing.T) {
 	repo, err := setupTestDB()
 	if err != nil {
@@ -61,7 +70,7 @@ func TestPostgresGetAllPosts(t *testing.T) {
 	require.NoError(t, err, "Failed to insert test post")
 
 	// Тестируем получение постов
-	posts, err := repo.GetAllPosts(ctx)
+	posts, err := repo.GetAllPosts(ctx, entity.PostFilter{Author: username})
 	assert.NoError(t, err)
 	assert.NotEmpty(t, posts)
 	assert.Equal(t, "Test Post", posts[0].Title)
@@ -110,6 +119,23 @@ func TestPostgresGetPostByID(t *testing.T) {
 	assert.Equal(t, postTitle, post.Title)
 	assert.Equal(t, username, post.Author)
 }
+
+func TestPostgresGetPostsByIDs(t *testing.T) {
+	repo, err := setupTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	first := &entity.Post{Title: "First", Content: "Content", UserID: 1}
+	second := &entity.Post{Title: "Second", Content: "Content", UserID: 1}
+	require.NoError(t, repo.CreatePost(ctx, first))
+	require.NoError(t, repo.CreatePost(ctx, second))
+
+	// Несуществующий ID просто пропускается
+	posts, err := repo.GetPostsByIDs(ctx, []int{first.ID, second.ID, -1})
+	require.NoError(t, err)
+	assert.Len(t, posts, 2)
+}
+
 func TestPostgresDeletePost(t *testing.T) {
 	repo, err := setupTestDB()
 	require.NoError(t, err, "Failed to setup test database")
@@ -147,12 +173,116 @@ func TestPostgresDeletePost(t *testing.T) {
 	require.NoError(t, err, "Failed to get post ID")
 
 	// Тестируем удаление
-	err = repo.DeletePost(ctx, postID)
+	err = repo.DeletePost(ctx, postID, userID)
 	assert.NoError(t, err)
 
-	// Проверяем, что пост действительно удален
-	var count int
-	err = repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM posts WHERE id = $1", postID).Scan(&count)
+	// Пост остаётся в корзине с пометкой, кто и когда его удалил
+	var deletedBy int
+	err = repo.db.QueryRowContext(ctx,
+		"SELECT deleted_by FROM posts WHERE id = $1 AND deleted_at IS NOT NULL", postID).Scan(&deletedBy)
 	assert.NoError(t, err)
-	assert.Equal(t, 0, count)
+	assert.Equal(t, userID, deletedBy)
+}
+
+func TestPostgresSearch(t *testing.T) {
+	repo, err := setupTestDB()
+	require.NoError(t, err, "Failed to setup test database")
+
+	ctx := context.Background()
+
+	timestamp := time.Now().UnixNano()
+	username := fmt.Sprintf("user_%d", timestamp)
+	email := fmt.Sprintf("search_%d@example.com", timestamp)
+	word := fmt.Sprintf("needle%d", timestamp)
+
+	var userID int
+	err = repo.db.QueryRowContext(ctx, `
+        INSERT INTO users (username, email, password_hash)
+        VALUES ($1, $2, 'hash')
+        RETURNING id
+    `, username, email).Scan(&userID)
+	require.NoError(t, err, "Failed to insert test user")
+
+	var postID int
+	err = repo.db.QueryRowContext(ctx, `
+        INSERT INTO posts (title, content, user_id)
+        VALUES ('Search test', $1, $2)
+        RETURNING id
+    `, "haystack "+word+" haystack", userID).Scan(&postID)
+	require.NoError(t, err, "Failed to insert test post")
+
+	_, err = repo.db.ExecContext(ctx, `
+        INSERT INTO comments (content, post_id, user_id)
+        VALUES ($1, $2, $3)
+    `, "reply with "+word, postID, userID)
+	require.NoError(t, err, "Failed to insert test comment")
+
+	results, err := repo.Search(ctx, entity.SearchQuery{Query: word, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Contains(t, results[0].Snippet, "<mark>"+word+"</mark>")
+
+	// Разметка из текста поста не проходит в сниппет
+	_, err = repo.db.ExecContext(ctx, `
+        INSERT INTO posts (title, content, user_id)
+        VALUES ('<b>XSS</b>', $1, $2)
+    `, "<img src=x onerror=alert(1)> "+word+"x", userID)
+	require.NoError(t, err)
+	results, err = repo.Search(ctx, entity.SearchQuery{Query: word + "x", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "&lt;b&gt;XSS&lt;/b&gt;", results[0].Title)
+	assert.Equal(t, "&lt;img src=x onerror=alert(1)&gt; <mark>"+word+"x</mark>", results[0].Snippet)
+
+	results, err = repo.Search(ctx, entity.SearchQuery{Query: word, Type: entity.SearchTypeComment, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, postID, results[0].PostID)
+}
+
+func TestHighlightSearchText(t *testing.T) {
+	assert.Equal(t,
+		"&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>go</mark> &amp; <mark>rust</mark>",
+		highlightSearchText(`<script>alert("x")</script> `+searchMarkStart+"go"+searchMarkStop+" & "+searchMarkStart+"rust"+searchMarkStop),
+	)
+}
+
+func TestPostgresPostTags(t *testing.T) {
+	repo, err := setupTestDB()
+	require.NoError(t, err, "Failed to setup test database")
+
+	ctx := context.Background()
+
+	timestamp := time.Now().UnixNano()
+	var userID int
+	err = repo.db.QueryRowContext(ctx, `
+        INSERT INTO users (username, email, password_hash)
+        VALUES ($1, $2, 'hash')
+        RETURNING id
+    `, fmt.Sprintf("tagger_%d", timestamp), fmt.Sprintf("tagger_%d@example.com", timestamp)).Scan(&userID)
+	require.NoError(t, err, "Failed to insert test user")
+
+	category := &entity.Category{Slug: fmt.Sprintf("cat-%d", timestamp), Name: "Test category"}
+	require.NoError(t, repo.CreateCategory(ctx, category))
+	assert.ErrorIs(t, repo.CreateCategory(ctx, &entity.Category{Slug: category.Slug, Name: "Dup"}), ErrConflict)
+
+	tag := fmt.Sprintf("tag%d", timestamp)
+	post := &entity.Post{Title: "Tagged", Content: "Content", UserID: userID, CategoryID: &category.ID, Tags: []string{tag, "common"}}
+	require.NoError(t, repo.CreatePost(ctx, post))
+
+	posts, err := repo.GetAllPosts(ctx, entity.PostFilter{Tag: tag})
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	assert.ElementsMatch(t, []string{tag, "common"}, posts[0].Tags)
+	require.NotNil(t, posts[0].CategoryID)
+	assert.Equal(t, category.ID, *posts[0].CategoryID)
+
+	posts, err = repo.GetAllPosts(ctx, entity.PostFilter{Category: category.Slug})
+	require.NoError(t, err)
+	assert.Len(t, posts, 1)
+
+	require.NoError(t, repo.ReplacePostTags(ctx, post.ID, []string{"common"}))
+	posts, err = repo.GetAllPosts(ctx, entity.PostFilter{Tag: tag})
+	require.NoError(t, err)
+	assert.Empty(t, posts)
 }
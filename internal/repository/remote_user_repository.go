@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	userProto "github.com/perfect1337/forum-service/internal/proto/user"
+)
+
+// ErrUserDirectoryUnavailable means auth-service could not be asked about a
+// user and there was no cached copy to fall back to.
+var ErrUserDirectoryUnavailable = errors.New("user directory unavailable")
+
+// RemoteUserOptions configure RemoteUsers. Zero values get defaults.
+type RemoteUserOptions struct {
+	// TTL is how long a user fetched from auth-service is served from memory
+	// without asking again.
+	TTL time.Duration
+	// BatchSize caps the IDs sent in one GetUsers call.
+	BatchSize int
+}
+
+// RemoteUsers is a UserRepository backed by auth-service instead of the
+// local users table. Lookups are batched into GetUsers calls and cached for
+// TTL. Expired entries are kept, so while auth-service is unreachable the
+// last known users are still served.
+type RemoteUsers struct {
+	client    userProto.UserServiceClient
+	ttl       time.Duration
+	batchSize int
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[int]cachedUser
+}
+
+type cachedUser struct {
+	user      entity.User
+	fetchedAt time.Time
+}
+
+func NewRemoteUsers(client userProto.UserServiceClient, opts RemoteUserOptions) *RemoteUsers {
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	return &RemoteUsers{
+		client:    client,
+		ttl:       opts.TTL,
+		batchSize: opts.BatchSize,
+		now:       time.Now,
+		cache:     make(map[int]cachedUser),
+	}
+}
+
+// CreateUser only caches the user: accounts are owned by auth-service.
+func (r *RemoteUsers) CreateUser(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache[user.ID] = cachedUser{user: *user, fetchedAt: r.now()}
+	return nil
+}
+
+// GetUserByID returns ErrNotFound for a user auth-service doesn't know.
+func (r *RemoteUsers) GetUserByID(ctx context.Context, id int) (*entity.User, error) {
+	users, err := r.GetUsersByIDs(ctx, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	user, ok := users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
+
+// GetUsersByIDs returns the users it found keyed by ID, like the Postgres
+// version. Only the IDs missing from the cache or expired go to
+// auth-service.
+func (r *RemoteUsers) GetUsersByIDs(ctx context.Context, ids []int) (map[int]*entity.User, error) {
+	users := make(map[int]*entity.User, len(ids))
+	stale := make(map[int]*entity.User)
+	var missing []int
+
+	r.mu.Lock()
+	now := r.now()
+	for _, id := range ids {
+		if _, seen := users[id]; seen {
+			continue
+		}
+		if _, seen := stale[id]; seen {
+			continue
+		}
+		entry, ok := r.cache[id]
+		switch {
+		case ok && now.Sub(entry.fetchedAt) < r.ttl:
+			user := entry.user
+			users[id] = &user
+		case ok:
+			user := entry.user
+			stale[id] = &user
+			missing = append(missing, id)
+		default:
+			stale[id] = nil
+			missing = append(missing, id)
+		}
+	}
+	r.mu.Unlock()
+
+	for start := 0; start < len(missing); start += r.batchSize {
+		end := min(start+r.batchSize, len(missing))
+		fetched, err := r.fetch(ctx, missing[start:end])
+		if err != nil {
+			// auth-service недоступен: отдаём то, что осталось в кэше.
+			for _, id := range missing[start:] {
+				if stale[id] == nil {
+					return nil, fmt.Errorf("%w: %w", ErrUserDirectoryUnavailable, err)
+				}
+				users[id] = stale[id]
+			}
+			return users, nil
+		}
+		for id, user := range fetched {
+			users[id] = user
+		}
+	}
+	return users, nil
+}
+
+// fetch asks auth-service for one batch and caches the answer. IDs it
+// doesn't return are dropped from the cache, so deleted users go away too.
+func (r *RemoteUsers) fetch(ctx context.Context, ids []int) (map[int]*entity.User, error) {
+	req := &userProto.UsersRequest{UserIds: make([]int32, 0, len(ids))}
+	for _, id := range ids {
+		req.UserIds = append(req.UserIds, int32(id))
+	}
+	resp, err := r.client.GetUsers(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	users := make(map[int]*entity.User, len(resp.GetUsers()))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	for _, id := range ids {
+		delete(r.cache, id)
+	}
+	for _, u := range resp.GetUsers() {
+		user := entity.User{
+			ID:       int(u.GetId()),
+			Username: u.GetUsername(),
+			Email:    u.GetEmail(),
+			Role:     u.GetRole(),
+		}
+		r.cache[user.ID] = cachedUser{user: user, fetchedAt: now}
+		users[user.ID] = &user
+	}
+	return users, nil
+}
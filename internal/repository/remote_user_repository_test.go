@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	userProto "github.com/perfect1337/forum-service/internal/proto/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUserClient answers GetUsers from a fixed set of users and records the
+// batches it was asked for.
+type fakeUserClient struct {
+	userProto.UserServiceClient
+
+	mu      sync.Mutex
+	users   map[int32]*userProto.User
+	down    bool
+	batches [][]int32
+}
+
+func (c *fakeUserClient) GetUsers(ctx context.Context, in *userProto.UsersRequest, opts ...grpc.CallOption) (*userProto.UsersResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, in.GetUserIds())
+	if c.down {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}
+	resp := &userProto.UsersResponse{}
+	for _, id := range in.GetUserIds() {
+		if user, ok := c.users[id]; ok {
+			resp.Users = append(resp.Users, user)
+		}
+	}
+	return resp, nil
+}
+
+func (c *fakeUserClient) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = down
+}
+
+func (c *fakeUserClient) calls() [][]int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.batches
+}
+
+func newFakeUserClient() *fakeUserClient {
+	return &fakeUserClient{users: map[int32]*userProto.User{
+		1: {Id: 1, Username: "alice", Email: "alice@example.com", Role: "admin"},
+		2: {Id: 2, Username: "bob", Role: "user"},
+		3: {Id: 3, Username: "carol", Role: "user"},
+	}}
+}
+
+// newTestRemoteUsers returns RemoteUsers with a clock the test moves by hand.
+func newTestRemoteUsers(client userProto.UserServiceClient, opts RemoteUserOptions) (*RemoteUsers, func(time.Duration)) {
+	r := NewRemoteUsers(client, opts)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	return r, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRemoteUsers_Batching(t *testing.T) {
+	client := newFakeUserClient()
+	r, _ := newTestRemoteUsers(client, RemoteUserOptions{TTL: time.Minute, BatchSize: 2})
+	ctx := context.Background()
+
+	users, err := r.GetUsersByIDs(ctx, []int{1, 2, 2, 3, 42})
+	require.NoError(t, err)
+	assert.Len(t, users, 3)
+	assert.Equal(t, "alice", users[1].Username)
+	assert.Equal(t, "admin", users[1].Role)
+	assert.Equal(t, "alice@example.com", users[1].Email)
+	// Повторы убраны, запрос разбит по BatchSize.
+	assert.Equal(t, [][]int32{{1, 2}, {3, 42}}, client.calls())
+
+	t.Run("Повторный запрос берётся из кэша", func(t *testing.T) {
+		user, err := r.GetUserByID(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "carol", user.Username)
+		assert.Len(t, client.calls(), 2)
+	})
+
+	t.Run("Неизвестный пользователь", func(t *testing.T) {
+		_, err := r.GetUserByID(ctx, 42)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestRemoteUsers_TTL(t *testing.T) {
+	client := newFakeUserClient()
+	r, advance := newTestRemoteUsers(client, RemoteUserOptions{TTL: time.Minute})
+	ctx := context.Background()
+
+	_, err := r.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+
+	client.mu.Lock()
+	client.users[1] = &userProto.User{Id: 1, Username: "alice2"}
+	client.mu.Unlock()
+
+	user, err := r.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user.Username, "fresh entry is served from memory")
+
+	advance(2 * time.Minute)
+	user, err = r.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "alice2", user.Username)
+	assert.Len(t, client.calls(), 2)
+}
+
+func TestRemoteUsers_FallbackWhenDown(t *testing.T) {
+	client := newFakeUserClient()
+	r, advance := newTestRemoteUsers(client, RemoteUserOptions{TTL: time.Minute})
+	ctx := context.Background()
+
+	_, err := r.GetUsersByIDs(ctx, []int{1, 2})
+	require.NoError(t, err)
+	advance(time.Hour)
+	client.setDown(true)
+
+	t.Run("Устаревшие записи отдаются", func(t *testing.T) {
+		users, err := r.GetUsersByIDs(ctx, []int{1, 2})
+		require.NoError(t, err)
+		assert.Equal(t, "alice", users[1].Username)
+		assert.Equal(t, "bob", users[2].Username)
+	})
+
+	t.Run("Без записи в кэше — ошибка", func(t *testing.T) {
+		_, err := r.GetUsersByIDs(ctx, []int{1, 3})
+		assert.ErrorIs(t, err, ErrUserDirectoryUnavailable)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("После восстановления кэш обновляется", func(t *testing.T) {
+		client.setDown(false)
+		user, err := r.GetUserByID(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "carol", user.Username)
+	})
+}
+
+func TestRemoteUsers_CreateUser(t *testing.T) {
+	client := newFakeUserClient()
+	r, _ := newTestRemoteUsers(client, RemoteUserOptions{})
+
+	require.NoError(t, r.CreateUser(context.Background(), &entity.User{ID: 7, Username: "dave"}))
+	user, err := r.GetUserByID(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, "dave", user.Username)
+	assert.Empty(t, client.calls())
+}
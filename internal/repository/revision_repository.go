@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+type RevisionRepository interface {
+	GetPostRevisions(ctx context.Context, postID int) ([]*entity.PostRevision, error)
+	GetPostRevision(ctx context.Context, postID, revision int) (*entity.PostRevision, error)
+}
+
+// GetPostRevisions returns the edit history of a post, oldest first.
+func (p *Postgres) GetPostRevisions(ctx context.Context, postID int) ([]*entity.PostRevision, error) {
+	query := `
+        SELECT r.id, r.post_id, r.revision, r.title, r.content, r.editor_id,
+            COALESCE(u.username, ''), r.created_at
+        FROM post_revisions r
+        JOIN posts p ON r.post_id = p.id
+        LEFT JOIN users u ON r.editor_id = u.id
+        WHERE r.post_id = $1 AND p.deleted_at IS NULL
+        ORDER BY r.revision
+    `
+	rows, err := p.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*entity.PostRevision
+	for rows.Next() {
+		var r entity.PostRevision
+		if err := rows.Scan(
+			&r.ID,
+			&r.PostID,
+			&r.Revision,
+			&r.Title,
+			&r.Content,
+			&r.EditorID,
+			&r.Editor,
+			&r.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return revisions, nil
+}
+
+func (p *Postgres) GetPostRevision(ctx context.Context, postID, revision int) (*entity.PostRevision, error) {
+	query := `
+        SELECT r.id, r.post_id, r.revision, r.title, r.content, r.editor_id,
+            COALESCE(u.username, ''), r.created_at
+        FROM post_revisions r
+        JOIN posts p ON r.post_id = p.id
+        LEFT JOIN users u ON r.editor_id = u.id
+        WHERE r.post_id = $1 AND r.revision = $2 AND p.deleted_at IS NULL
+    `
+	var r entity.PostRevision
+	err := p.db.QueryRowContext(ctx, query, postID, revision).Scan(
+		&r.ID,
+		&r.PostID,
+		&r.Revision,
+		&r.Title,
+		&r.Content,
+		&r.EditorID,
+		&r.Editor,
+		&r.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return &r, nil
+}
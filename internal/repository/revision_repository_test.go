@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresPostRevisions(t *testing.T) {
+	repo, err := setupTestDB()
+	require.NoError(t, err, "Failed to setup test database")
+
+	ctx := context.Background()
+
+	timestamp := time.Now().UnixNano()
+	var userID int
+	err = repo.db.QueryRowContext(ctx, `
+        INSERT INTO users (username, email, password_hash)
+        VALUES ($1, $2, 'hash')
+        RETURNING id
+    `, fmt.Sprintf("editor_%d", timestamp), fmt.Sprintf("editor_%d@example.com", timestamp)).Scan(&userID)
+	require.NoError(t, err, "Failed to insert test user")
+
+	post := &entity.Post{Title: "Original", Content: "first line", UserID: userID}
+	require.NoError(t, repo.CreatePost(ctx, post))
+
+	require.NoError(t, repo.UpdatePost(ctx, post.ID, userID, "Edited", "first line\nsecond line"))
+
+	revisions, err := repo.GetPostRevisions(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 1, revisions[0].Revision)
+	assert.Equal(t, "Original", revisions[0].Title)
+	assert.Equal(t, 2, revisions[1].Revision)
+	assert.Equal(t, userID, revisions[1].EditorID)
+
+	updated, err := repo.GetPostByID(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Edited", updated.Title)
+	assert.NotNil(t, updated.EditedAt)
+
+	_, err = repo.GetPostRevision(ctx, post.ID, 3)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = repo.UpdatePost(ctx, -1, userID, "x", "y")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
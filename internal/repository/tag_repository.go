@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type TagRepository interface {
+	SetPostCategory(ctx context.Context, postID int, categoryID *int) error
+	ReplacePostTags(ctx context.Context, postID int, tags []string) error
+}
+
+// postTagsSQL selects the sorted tag names of post p as a text array.
+const postTagsSQL = `ARRAY(
+                SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+                WHERE pt.post_id = p.id ORDER BY t.name)`
+
+// SetPostCategory moves a post to a category; nil leaves it uncategorized.
+// Tags are not touched.
+func (p *Postgres) SetPostCategory(ctx context.Context, postID int, categoryID *int) error {
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE posts SET category_id = $1 WHERE id = $2 AND deleted_at IS NULL`, categoryID, postID)
+	if err != nil {
+		return fmt.Errorf("failed to set category: %w", err)
+	}
+	return checkRowsAffected(result.RowsAffected())
+}
+
+// ReplacePostTags replaces the full tag set of a post; an empty slice
+// removes all tags. The category is not touched.
+func (p *Postgres) ReplacePostTags(ctx context.Context, postID int, tags []string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM posts WHERE id = $1 AND deleted_at IS NULL)`, postID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check post: %w", err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM post_tags WHERE post_id = $1`, postID); err != nil {
+		return fmt.Errorf("failed to clear tags: %w", err)
+	}
+	if err := insertPostTags(ctx, tx, postID, tags); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertPostTags creates missing tags and links them to the post.
+func insertPostTags(ctx context.Context, tx *sql.Tx, postID int, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO tags (name) SELECT unnest($1::text[])
+        ON CONFLICT (name) DO NOTHING
+    `, pq.Array(tags)); err != nil {
+		return fmt.Errorf("failed to save tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO post_tags (post_id, tag_id)
+        SELECT $1, id FROM tags WHERE name = ANY($2::text[])
+        ON CONFLICT DO NOTHING
+    `, postID, pq.Array(tags)); err != nil {
+		return fmt.Errorf("failed to link tags: %w", err)
+	}
+	return nil
+}
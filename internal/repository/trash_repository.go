@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+type TrashRepository interface {
+	GetDeletedItems(ctx context.Context, itemType string, limit, offset int) ([]entity.TrashItem, error)
+	RestorePost(ctx context.Context, id int) error
+	RestoreComment(ctx context.Context, id int) error
+	PurgePost(ctx context.Context, id int) error
+	PurgeComment(ctx context.Context, id int) error
+	PurgeDeletedOlderThan(ctx context.Context, age time.Duration) (int64, error)
+}
+
+// GetDeletedItems lists soft-deleted posts and comments, most recently
+// deleted first. itemType narrows the list to one kind; empty means both.
+func (p *Postgres) GetDeletedItems(ctx context.Context, itemType string, limit, offset int) ([]entity.TrashItem, error) {
+	postsQuery := `
+        SELECT 'post' AS type, p.id, p.id AS post_id, p.title, p.content,
+            p.user_id, COALESCE(u.username, ''), p.created_at, p.deleted_at, COALESCE(p.deleted_by, 0)
+        FROM posts p
+        LEFT JOIN users u ON p.user_id = u.id
+        WHERE p.deleted_at IS NOT NULL`
+	commentsQuery := `
+        SELECT 'comment' AS type, c.id, c.post_id, '' AS title, c.content,
+            c.user_id, COALESCE(u.username, ''), c.created_at, c.deleted_at, COALESCE(c.deleted_by, 0)
+        FROM comments c
+        LEFT JOIN users u ON c.user_id = u.id
+        WHERE c.deleted_at IS NOT NULL`
+
+	var union string
+	switch itemType {
+	case entity.TrashTypePost:
+		union = postsQuery
+	case entity.TrashTypeComment:
+		union = commentsQuery
+	default:
+		union = postsQuery + "\n        UNION ALL" + commentsQuery
+	}
+
+	query := `
+        SELECT type, id, post_id, title, content, user_id, username, created_at, deleted_at, deleted_by
+        FROM (` + union + `
+        ) trash
+        ORDER BY deleted_at DESC, id DESC
+        LIMIT $1 OFFSET $2`
+
+	rows, err := p.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+	defer rows.Close()
+
+	var items []entity.TrashItem
+	for rows.Next() {
+		var item entity.TrashItem
+		if err := rows.Scan(
+			&item.Type,
+			&item.ID,
+			&item.PostID,
+			&item.Title,
+			&item.Content,
+			&item.UserID,
+			&item.Author,
+			&item.CreatedAt,
+			&item.DeletedAt,
+			&item.DeletedBy,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return items, nil
+}
+
+func (p *Postgres) RestorePost(ctx context.Context, id int) error {
+	return p.execTrash(ctx, `UPDATE posts SET deleted_at = NULL, deleted_by = NULL
+                             WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+}
+
+func (p *Postgres) RestoreComment(ctx context.Context, id int) error {
+	return p.execTrash(ctx, `UPDATE comments SET deleted_at = NULL, deleted_by = NULL
+                             WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+}
+
+// PurgePost permanently removes a post from the trash along with its
+// comments and revisions.
+func (p *Postgres) PurgePost(ctx context.Context, id int) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+        DELETE FROM comments
+        WHERE post_id = (SELECT id FROM posts WHERE id = $1 AND deleted_at IS NOT NULL)
+    `, id); err != nil {
+		return fmt.Errorf("failed to purge comments: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM posts WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to purge post: %w", err)
+	}
+	if err := checkRowsAffected(result.RowsAffected()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *Postgres) PurgeComment(ctx context.Context, id int) error {
+	return p.execTrash(ctx, `DELETE FROM comments WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+}
+
+// PurgeDeletedOlderThan permanently removes everything that has been in the
+// trash longer than age and returns the number of purged rows. Age is measured
+// by the database clock, which also fills deleted_at.
+func (p *Postgres) PurgeDeletedOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Комментарии удаляемых постов уходят вместе с ними, даже если сами
+	// не были удалены. NOW() постоянен в пределах транзакции, поэтому оба
+	// запроса используют одну и ту же границу.
+	comments, err := tx.ExecContext(ctx, `
+        DELETE FROM comments
+        WHERE (deleted_at IS NOT NULL AND deleted_at < NOW() - make_interval(secs => $1::float8))
+            OR post_id IN (
+                SELECT id FROM posts
+                WHERE deleted_at IS NOT NULL AND deleted_at < NOW() - make_interval(secs => $1::float8)
+            )
+    `, age.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge comments: %w", err)
+	}
+	posts, err := tx.ExecContext(ctx, `
+        DELETE FROM posts
+        WHERE deleted_at IS NOT NULL AND deleted_at < NOW() - make_interval(secs => $1::float8)
+    `, age.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge posts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+
+	nComments, _ := comments.RowsAffected()
+	nPosts, _ := posts.RowsAffected()
+	return nComments + nPosts, nil
+}
+
+func (p *Postgres) execTrash(ctx context.Context, query string, id int) error {
+	result, err := p.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to update trash: %w", err)
+	}
+	return checkRowsAffected(result.RowsAffected())
+}
+
+// checkRowsAffected turns "nothing matched" into ErrNotFound, e.g. when a
+// trash item does not exist or is not in the trash.
+func checkRowsAffected(rowsAffected int64, err error) error {
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
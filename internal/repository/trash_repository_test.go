@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresSoftDeletePost(t *testing.T) {
+	repo, err := setupTestDB()
+	require.NoError(t, err, "Failed to setup test database")
+
+	ctx := context.Background()
+
+	timestamp := time.Now().UnixNano()
+	var userID int
+	err = repo.db.QueryRowContext(ctx, `
+        INSERT INTO users (username, email, password_hash)
+        VALUES ($1, $2, 'hash')
+        RETURNING id
+    `, fmt.Sprintf("trash_%d", timestamp), fmt.Sprintf("trash_%d@example.com", timestamp)).Scan(&userID)
+	require.NoError(t, err, "Failed to insert test user")
+
+	post := &entity.Post{Title: "Trash test", Content: "Content", UserID: userID}
+	require.NoError(t, repo.CreatePost(ctx, post))
+
+	require.NoError(t, repo.DeletePost(ctx, post.ID, userID))
+	_, err = repo.GetPostByID(ctx, post.ID)
+	assert.Error(t, err, "deleted post must be hidden")
+	assert.ErrorIs(t, repo.DeletePost(ctx, post.ID, userID), ErrNotFound)
+
+	items, err := repo.GetDeletedItems(ctx, entity.TrashTypePost, 100, 0)
+	require.NoError(t, err)
+	found := false
+	for _, item := range items {
+		if item.ID == post.ID {
+			found = true
+			assert.Equal(t, userID, item.DeletedBy)
+		}
+	}
+	assert.True(t, found, "deleted post must be listed in the trash")
+
+	require.NoError(t, repo.RestorePost(ctx, post.ID))
+	_, err = repo.GetPostByID(ctx, post.ID)
+	assert.NoError(t, err)
+
+	require.NoError(t, repo.DeletePost(ctx, post.ID, userID))
+	purged, err := repo.PurgeDeletedOlderThan(ctx, 0)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, int64(1))
+	assert.ErrorIs(t, repo.RestorePost(ctx, post.ID), ErrNotFound)
+}
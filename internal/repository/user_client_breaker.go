@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	userProto "github.com/perfect1337/forum-service/internal/proto/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen is returned without calling auth-service while the breaker
+// is open. It carries codes.Unavailable, like the failures that opened it.
+var ErrCircuitOpen = status.Error(codes.Unavailable, "auth-service circuit breaker is open")
+
+// Circuit breaker states, as reported by UserClientBreaker.State.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// BreakerOptions configure UserClientBreaker. Zero values get defaults.
+type BreakerOptions struct {
+	// Failures is how many calls in a row must fail to open the breaker.
+	Failures int
+	// Cooldown is how long the breaker stays open before one call is let
+	// through to probe auth-service.
+	Cooldown time.Duration
+}
+
+// UserClientBreaker is a UserServiceClient that stops calling auth-service
+// once it keeps failing, so callers get ErrCircuitOpen right away instead of
+// each waiting for its own timeout. Only errors that say auth-service is
+// unreachable or too slow count as failures; NotFound and the like don't.
+type UserClientBreaker struct {
+	client   userProto.UserServiceClient
+	failures int
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	failed    int
+	openUntil time.Time
+	probing   bool
+}
+
+func NewUserClientBreaker(client userProto.UserServiceClient, opts BreakerOptions) *UserClientBreaker {
+	if opts.Failures <= 0 {
+		opts.Failures = 5
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = 10 * time.Second
+	}
+	return &UserClientBreaker{
+		client:   client,
+		failures: opts.Failures,
+		cooldown: opts.Cooldown,
+		now:      time.Now,
+	}
+}
+
+func (b *UserClientBreaker) GetUsername(ctx context.Context, in *userProto.UserRequest, opts ...grpc.CallOption) (*userProto.UserResponse, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := b.client.GetUsername(ctx, in, opts...)
+	b.record(err)
+	return resp, err
+}
+
+func (b *UserClientBreaker) GetUsers(ctx context.Context, in *userProto.UsersRequest, opts ...grpc.CallOption) (*userProto.UsersResponse, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := b.client.GetUsers(ctx, in, opts...)
+	b.record(err)
+	return resp, err
+}
+
+// State returns CircuitClosed, CircuitOpen or CircuitHalfOpen.
+func (b *UserClientBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stateLocked()
+}
+
+// Check is a health check that fails while the breaker is open.
+func (b *UserClientBreaker) Check(ctx context.Context) error {
+	if b.State() == CircuitOpen {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+func (b *UserClientBreaker) stateLocked() string {
+	switch {
+	case b.failed < b.failures:
+		return CircuitClosed
+	case b.now().Before(b.openUntil):
+		return CircuitOpen
+	default:
+		return CircuitHalfOpen
+	}
+}
+
+// allow lets a call through unless the breaker is open. Once the cooldown is
+// over, a single probe goes through while the others keep failing fast.
+func (b *UserClientBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.stateLocked() {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+func (b *UserClientBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !isUnreachable(err) {
+		b.failed = 0
+		return
+	}
+	b.failed++
+	if b.failed >= b.failures {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+func isUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	// Canceled не считается: запрос отменил наш собственный клиент.
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	userProto "github.com/perfect1337/forum-service/internal/proto/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scriptedUserClient fails GetUsername with err and counts the calls that
+// reached it.
+type scriptedUserClient struct {
+	userProto.UserServiceClient
+	err   error
+	calls int
+}
+
+func (c *scriptedUserClient) GetUsername(ctx context.Context, in *userProto.UserRequest, opts ...grpc.CallOption) (*userProto.UserResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &userProto.UserResponse{Username: "alice"}, nil
+}
+
+func newTestBreaker(client userProto.UserServiceClient) (*UserClientBreaker, func(time.Duration)) {
+	b := NewUserClientBreaker(client, BreakerOptions{Failures: 2, Cooldown: time.Minute})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	return b, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestUserClientBreaker(t *testing.T) {
+	client := &scriptedUserClient{err: status.Error(codes.Unavailable, "connection refused")}
+	b, advance := newTestBreaker(client)
+	ctx := context.Background()
+	call := func() error {
+		_, err := b.GetUsername(ctx, &userProto.UserRequest{UserId: 1})
+		return err
+	}
+
+	t.Run("Открывается после серии отказов", func(t *testing.T) {
+		assert.Equal(t, codes.Unavailable, status.Code(call()))
+		assert.Equal(t, CircuitClosed, b.State())
+		assert.Equal(t, codes.Unavailable, status.Code(call()))
+		assert.Equal(t, CircuitOpen, b.State())
+		assert.Error(t, b.Check(ctx))
+
+		// Пока открыт, auth-service не вызывается.
+		assert.ErrorIs(t, call(), ErrCircuitOpen)
+		assert.Equal(t, 2, client.calls)
+	})
+
+	t.Run("Неудачная проба открывает снова", func(t *testing.T) {
+		advance(time.Minute)
+		assert.Equal(t, CircuitHalfOpen, b.State())
+		assert.NoError(t, b.Check(ctx))
+		assert.Equal(t, codes.Unavailable, status.Code(call()))
+		assert.Equal(t, 3, client.calls)
+		assert.Equal(t, CircuitOpen, b.State())
+	})
+
+	t.Run("Удачная проба закрывает", func(t *testing.T) {
+		advance(time.Minute)
+		client.err = nil
+		require.NoError(t, call())
+		assert.Equal(t, CircuitClosed, b.State())
+		require.NoError(t, call())
+		assert.Equal(t, 5, client.calls)
+	})
+}
+
+func TestUserClientBreaker_IgnoresClientErrors(t *testing.T) {
+	client := &scriptedUserClient{err: status.Error(codes.NotFound, "user not found")}
+	b, _ := newTestBreaker(client)
+
+	for i := 0; i < 5; i++ {
+		_, err := b.GetUsername(context.Background(), &userProto.UserRequest{UserId: 1})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.Equal(t, 5, client.calls)
+}
+
+func TestUserClientBreaker_SingleProbe(t *testing.T) {
+	client := &scriptedUserClient{err: status.Error(codes.DeadlineExceeded, "timeout")}
+	b, advance := newTestBreaker(client)
+	for i := 0; i < 2; i++ {
+		_, _ = b.GetUsername(context.Background(), &userProto.UserRequest{UserId: 1})
+	}
+	advance(time.Minute)
+
+	// Пока первая проба не завершилась, остальные отклоняются сразу.
+	require.NoError(t, b.allow())
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+	b.record(nil)
+	assert.NoError(t, b.allow())
+}
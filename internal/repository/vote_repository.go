@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+type VoteRepository interface {
+	VotePost(ctx context.Context, postID, userID, value int) (*entity.VoteResult, error)
+	VoteComment(ctx context.Context, commentID, userID, value int) (*entity.VoteResult, error)
+}
+
+// hotRankSQL builds the "hot" ranking expression: the order of magnitude of
+// the score plus the creation time scaled so that 12.5 hours of age weigh as
+// much as a tenfold score difference. The expression does not depend on
+// NOW(), so it can be used for keyset pagination.
+func hotRankSQL(score, createdAt string) string {
+	return fmt.Sprintf("(SIGN(%[1]s) * LOG(GREATEST(ABS(%[1]s), 1)) + EXTRACT(EPOCH FROM %[2]s) / 45000)",
+		score, createdAt)
+}
+
+type voteTarget struct {
+	table      string // posts или comments
+	votesTable string
+	column     string
+}
+
+var (
+	postVoteTarget    = voteTarget{table: "posts", votesTable: "post_votes", column: "post_id"}
+	commentVoteTarget = voteTarget{table: "comments", votesTable: "comment_votes", column: "comment_id"}
+)
+
+func (p *Postgres) VotePost(ctx context.Context, postID, userID, value int) (*entity.VoteResult, error) {
+	return p.castVote(ctx, postVoteTarget, postID, userID, value)
+}
+
+func (p *Postgres) VoteComment(ctx context.Context, commentID, userID, value int) (*entity.VoteResult, error) {
+	return p.castVote(ctx, commentVoteTarget, commentID, userID, value)
+}
+
+// castVote stores, replaces or (for value 0) removes a user's vote and
+// recalculates the item's score in the same transaction.
+func (p *Postgres) castVote(ctx context.Context, target voteTarget, id, userID, value int) (*entity.VoteResult, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем строку, чтобы параллельные голоса пересчитывали счёт по очереди
+	var locked int
+	err = tx.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT id FROM %s WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, target.table), id).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s row: %w", target.table, err)
+	}
+
+	if value == 0 {
+		_, err = tx.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1 AND %s = $2`, target.votesTable, target.column),
+			userID, id)
+	} else {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %[1]s (user_id, %[2]s, value) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, %[2]s) DO UPDATE SET value = EXCLUDED.value, created_at = NOW()`,
+			target.votesTable, target.column), userID, id, value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store vote: %w", err)
+	}
+
+	result := &entity.VoteResult{Vote: value}
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		UPDATE %[1]s SET score = (
+			SELECT COALESCE(SUM(value), 0) FROM %[2]s WHERE %[3]s = $1
+		)
+		WHERE id = $1
+		RETURNING score`, target.table, target.votesTable, target.column), id).Scan(&result.Score)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update score: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit vote: %w", err)
+	}
+	return result, nil
+}
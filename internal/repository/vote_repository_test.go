@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresVotePost(t *testing.T) {
+	repo, err := setupTestDB()
+	require.NoError(t, err, "Failed to setup test database")
+
+	ctx := context.Background()
+
+	timestamp := time.Now().UnixNano()
+	var userID int
+	err = repo.db.QueryRowContext(ctx, `
+        INSERT INTO users (username, email, password_hash)
+        VALUES ($1, $2, 'hash')
+        RETURNING id
+    `, fmt.Sprintf("voter_%d", timestamp), fmt.Sprintf("voter_%d@example.com", timestamp)).Scan(&userID)
+	require.NoError(t, err, "Failed to insert test user")
+
+	var postID int
+	err = repo.db.QueryRowContext(ctx, `
+        INSERT INTO posts (title, content, user_id)
+        VALUES ('Vote test', 'Content', $1)
+        RETURNING id
+    `, userID).Scan(&postID)
+	require.NoError(t, err, "Failed to insert test post")
+
+	result, err := repo.VotePost(ctx, postID, userID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Score)
+
+	// Повторный голос заменяет предыдущий, а не добавляется к нему
+	result, err = repo.VotePost(ctx, postID, userID, -1)
+	require.NoError(t, err)
+	assert.Equal(t, -1, result.Score)
+
+	result, err = repo.VotePost(ctx, postID, userID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Score)
+
+	_, err = repo.VotePost(ctx, -1, userID, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/repository"
+)
+
+const (
+	MaxTagsPerPost  = 10
+	maxTagLength    = 32
+	maxCategoryName = 100
+)
+
+var (
+	ErrInvalidCategory = errors.New("invalid category")
+	ErrInvalidTag      = errors.New("invalid tag")
+	ErrConflict        = repository.ErrConflict
+
+	categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+type CategoryRepository interface {
+	GetCategories(ctx context.Context) ([]entity.Category, error)
+	GetCategoryByID(ctx context.Context, id int) (*entity.Category, error)
+	CreateCategory(ctx context.Context, category *entity.Category) error
+	UpdateCategory(ctx context.Context, category *entity.Category) error
+	DeleteCategory(ctx context.Context, id int) error
+}
+
+type CategoryUseCaseInterface interface {
+	GetCategories(ctx context.Context) ([]entity.Category, error)
+	CreateCategory(ctx context.Context, adminID int, category *entity.Category) error
+	UpdateCategory(ctx context.Context, adminID int, category *entity.Category) error
+	DeleteCategory(ctx context.Context, adminID, id int) error
+}
+
+// CategoryUseCase lists categories for everyone and lets admins manage them.
+type CategoryUseCase struct {
+	repo     CategoryRepository
+	userRepo UserRepository
+}
+
+func NewCategoryUseCase(repo CategoryRepository, userRepo UserRepository) *CategoryUseCase {
+	return &CategoryUseCase{repo: repo, userRepo: userRepo}
+}
+
+func (uc *CategoryUseCase) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	categories, err := uc.repo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		categories = []entity.Category{}
+	}
+	return categories, nil
+}
+
+func (uc *CategoryUseCase) CreateCategory(ctx context.Context, adminID int, category *entity.Category) error {
+	if err := requireAdmin(ctx, uc.userRepo, adminID); err != nil {
+		return err
+	}
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	return uc.repo.CreateCategory(ctx, category)
+}
+
+func (uc *CategoryUseCase) UpdateCategory(ctx context.Context, adminID int, category *entity.Category) error {
+	if err := requireAdmin(ctx, uc.userRepo, adminID); err != nil {
+		return err
+	}
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	return uc.repo.UpdateCategory(ctx, category)
+}
+
+func (uc *CategoryUseCase) DeleteCategory(ctx context.Context, adminID, id int) error {
+	if err := requireAdmin(ctx, uc.userRepo, adminID); err != nil {
+		return err
+	}
+	return uc.repo.DeleteCategory(ctx, id)
+}
+
+func validateCategory(category *entity.Category) error {
+	if category == nil {
+		return fmt.Errorf("%w: category cannot be nil", ErrInvalidCategory)
+	}
+	category.Slug = strings.ToLower(strings.TrimSpace(category.Slug))
+	category.Name = strings.TrimSpace(category.Name)
+	if !categorySlugPattern.MatchString(category.Slug) || len(category.Slug) > 50 {
+		return fmt.Errorf("%w: slug must be lowercase letters, digits and dashes", ErrInvalidCategory)
+	}
+	if category.Name == "" || utf8.RuneCountInString(category.Name) > maxCategoryName {
+		return fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidCategory, maxCategoryName)
+	}
+	return nil
+}
+
+// normalizeTags lowercases and deduplicates tags, dropping a leading '#'.
+// Tags may contain letters, digits, '-' and '_'.
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tags must be 1-%d characters", ErrInvalidTag, maxTagLength)
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+				return nil, fmt.Errorf("%w: %q contains %q", ErrInvalidTag, tag, r)
+			}
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	if len(result) > MaxTagsPerPost {
+		return nil, fmt.Errorf("%w: at most %d tags per post", ErrInvalidTag, MaxTagsPerPost)
+	}
+	return result, nil
+}
+
+// requireAdmin returns ErrForbidden unless the user has the admin role.
+func requireAdmin(ctx context.Context, userRepo UserRepository, userID int) error {
+	user, err := userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Role != "admin" {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCategoryRepository) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetCategoryByID(ctx context.Context, id int) (*entity.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) CreateCategory(ctx context.Context, category *entity.Category) error {
+	return m.Called(ctx, category).Error(0)
+}
+
+func (m *MockCategoryRepository) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	return m.Called(ctx, category).Error(0)
+}
+
+func (m *MockCategoryRepository) DeleteCategory(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func TestCategoryUseCase_CreateCategory(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		category    *entity.Category
+		mockSetup   func(*MockCategoryRepository)
+		expectedErr error
+	}{
+		{
+			name:     "Success",
+			role:     "admin",
+			category: &entity.Category{Slug: " Golang ", Name: "Go"},
+			mockSetup: func(m *MockCategoryRepository) {
+				m.On("CreateCategory", mock.Anything, &entity.Category{Slug: "golang", Name: "Go"}).Return(nil)
+			},
+		},
+		{
+			name:        "NotAdmin",
+			role:        "user",
+			category:    &entity.Category{Slug: "golang", Name: "Go"},
+			mockSetup:   func(m *MockCategoryRepository) {},
+			expectedErr: usecase.ErrForbidden,
+		},
+		{
+			name:        "InvalidSlug",
+			role:        "admin",
+			category:    &entity.Category{Slug: "go lang", Name: "Go"},
+			mockSetup:   func(m *MockCategoryRepository) {},
+			expectedErr: usecase.ErrInvalidCategory,
+		},
+		{
+			name:     "DuplicateSlug",
+			role:     "admin",
+			category: &entity.Category{Slug: "golang", Name: "Go"},
+			mockSetup: func(m *MockCategoryRepository) {
+				m.On("CreateCategory", mock.Anything, mock.Anything).Return(usecase.ErrConflict)
+			},
+			expectedErr: usecase.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockCategoryRepository)
+			mockUserRepo := new(MockUserRepository)
+			mockUserRepo.On("GetUserByID", mock.Anything, 1).Return(&entity.User{ID: 1, Role: tt.role}, nil)
+			tt.mockSetup(mockRepo)
+
+			uc := usecase.NewCategoryUseCase(mockRepo, mockUserRepo)
+			err := uc.CreateCategory(context.Background(), 1, tt.category)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUseCase_CreatePostWithTags(t *testing.T) {
+	t.Run("NormalizesTags", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+		categoryID := 2
+		mockPostRepo.On("GetCategoryByID", mock.Anything, 2).Return(&entity.Category{ID: 2}, nil)
+		mockPostRepo.On("CreatePost", mock.Anything, mock.MatchedBy(func(p *entity.Post) bool {
+			return assert.ObjectsAreEqual([]string{"go", "help"}, p.Tags)
+		})).Return(nil)
+
+		err := uc.CreatePost(context.Background(), &entity.Post{
+			Title: "T", Content: "C", UserID: 1, CategoryID: &categoryID,
+			Tags: []string{"#Go", "help", "go"},
+		})
+		require.NoError(t, err)
+		mockPostRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidTag", func(t *testing.T) {
+		uc := usecase.NewPostUseCase(new(MockPostRepository), new(MockUserRepository))
+
+		err := uc.CreatePost(context.Background(), &entity.Post{
+			Title: "T", Content: "C", UserID: 1, Tags: []string{"no spaces"},
+		})
+		assert.ErrorIs(t, err, usecase.ErrInvalidTag)
+	})
+
+	t.Run("UnknownCategory", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+		categoryID := 9
+		mockPostRepo.On("GetCategoryByID", mock.Anything, 9).Return(nil, usecase.ErrNotFound)
+
+		err := uc.CreatePost(context.Background(), &entity.Post{
+			Title: "T", Content: "C", UserID: 1, CategoryID: &categoryID,
+		})
+		assert.ErrorIs(t, err, usecase.ErrInvalidCategory)
+	})
+}
+
+func TestPostUseCase_UpdatePostTaxonomy(t *testing.T) {
+	setup := func() (*MockPostRepository, usecase.PostUseCase) {
+		mockPostRepo := new(MockPostRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockPostRepo.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, UserID: 7}, nil)
+		mockUserRepo.On("GetUserByID", mock.Anything, 7).Return(&entity.User{ID: 7, Role: "user"}, nil)
+		return mockPostRepo, usecase.NewPostUseCase(mockPostRepo, mockUserRepo)
+	}
+
+	t.Run("Только теги — категория не трогается", func(t *testing.T) {
+		mockPostRepo, uc := setup()
+		mockPostRepo.On("ReplacePostTags", mock.Anything, 1, []string{"go"}).Return(nil)
+
+		require.NoError(t, uc.UpdatePostTaxonomy(context.Background(), 1, 7, nil, []string{"#Go"}))
+		mockPostRepo.AssertExpectations(t)
+		mockPostRepo.AssertNotCalled(t, "SetPostCategory", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Только категория — теги не трогаются", func(t *testing.T) {
+		mockPostRepo, uc := setup()
+		categoryID := 2
+		mockPostRepo.On("GetCategoryByID", mock.Anything, 2).Return(&entity.Category{ID: 2}, nil)
+		mockPostRepo.On("SetPostCategory", mock.Anything, 1, &categoryID).Return(nil)
+
+		require.NoError(t, uc.UpdatePostTaxonomy(context.Background(), 1, 7, &categoryID, nil))
+		mockPostRepo.AssertExpectations(t)
+		mockPostRepo.AssertNotCalled(t, "ReplacePostTags", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Категория 0 и пустые теги очищают всё", func(t *testing.T) {
+		mockPostRepo, uc := setup()
+		zero := 0
+		mockPostRepo.On("SetPostCategory", mock.Anything, 1, (*int)(nil)).Return(nil)
+		mockPostRepo.On("ReplacePostTags", mock.Anything, 1, []string{}).Return(nil)
+
+		require.NoError(t, uc.UpdatePostTaxonomy(context.Background(), 1, 7, &zero, []string{}))
+		mockPostRepo.AssertExpectations(t)
+	})
+
+	t.Run("Ничего не задано", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+		require.NoError(t, uc.UpdatePostTaxonomy(context.Background(), 1, 7, nil, nil))
+		mockPostRepo.AssertExpectations(t)
+	})
+}
@@ -2,12 +2,14 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/websocket"
@@ -60,10 +62,18 @@ type WebSocketConnection interface {
 	RemoteAddr() net.Addr
 	Subprotocol() string
 	UnderlyingConn() net.Conn
+	WriteControl(messageType int, data []byte, deadline time.Time) error
 }
 
 // ParseToken parses and validates a JWT token, extracting the user ID and username from the claims.
 func (uc *AuthUseCase) ParseToken(tokenString string) (int64, string, error) {
+	userID, username, _, err := uc.ParseTokenClaims(tokenString)
+	return userID, username, err
+}
+
+// ParseTokenClaims is ParseToken that also returns the token's expiry time.
+// The expiry is zero for tokens without an "exp" claim.
+func (uc *AuthUseCase) ParseTokenClaims(tokenString string) (int64, string, time.Time, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -72,93 +82,226 @@ func (uc *AuthUseCase) ParseToken(tokenString string) (int64, string, error) {
 	})
 
 	if err != nil {
-		return 0, "", err
+		return 0, "", time.Time{}, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		userID, ok := claims["user_id"].(float64)
 		if !ok {
-			return 0, "", fmt.Errorf("invalid user_id in token")
+			return 0, "", time.Time{}, fmt.Errorf("invalid user_id in token")
 		}
 
 		username, ok := claims["username"].(string)
 		if !ok {
-			return 0, "", fmt.Errorf("invalid username in token")
+			return 0, "", time.Time{}, fmt.Errorf("invalid username in token")
 		}
 
-		return int64(userID), username, nil
+		var expiresAt time.Time
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+
+		return int64(userID), username, expiresAt, nil
 	}
 
-	return 0, "", fmt.Errorf("invalid token")
+	return 0, "", time.Time{}, fmt.Errorf("invalid token")
 }
 
 type ChatRepository interface {
 	SaveChatMessage(ctx context.Context, msg *entity.ChatMessage) error
-	GetChatMessages(ctx context.Context, limit int) ([]entity.ChatMessage, error)
-	DeleteOldChatMessages(ctx context.Context, olderThan time.Duration) error
+	GetChatMessages(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error)
+	GetChatMessageByID(ctx context.Context, id int) (*entity.ChatMessage, error)
+	UpdateChatMessageText(ctx context.Context, id int, text string, maxAge time.Duration) (*entity.ChatMessage, error)
+	DeleteChatMessage(ctx context.Context, id int, maxAge time.Duration) error
+	CreateChatRoom(ctx context.Context, room *entity.ChatRoom) error
+	GetChatRooms(ctx context.Context) ([]entity.ChatRoom, error)
+	GetChatRoomByID(ctx context.Context, id int) (*entity.ChatRoom, error)
+	JoinChatRoom(ctx context.Context, roomID, userID int) error
+	IsChatRoomMember(ctx context.Context, roomID, userID int) (bool, error)
+	SaveDirectMessage(ctx context.Context, msg *entity.DirectMessage) error
+	GetConversations(ctx context.Context, userID int) ([]entity.Conversation, error)
+	GetDirectMessages(ctx context.Context, userID, peerID, beforeID, limit int) ([]entity.DirectMessage, error)
+	MarkDirectMessagesRead(ctx context.Context, userID, peerID int) error
 }
 
+const (
+	maxChatRoomName = 64
+
+	DefaultDirectMessagePageSize = 50
+	MaxDirectMessagePageSize     = 100
+
+	DefaultChatHistoryLimit = 100
+	MaxChatHistoryLimit     = 500
+	// maxReplayMessages caps how much history a reconnecting client gets over
+	// the socket; anything older has to be fetched over HTTP.
+	maxReplayMessages = 1000
+)
+
+var (
+	ErrInvalidChatRoom      = errors.New("invalid chat room")
+	ErrNotRoomMember        = errors.New("join the room before posting to it")
+	ErrInvalidDirectMessage = errors.New("invalid direct message")
+)
+
 type ChatUseCase struct {
-	repo   ChatRepository
-	authUC AuthUseCaseInterface
-	hub    *WebSocketHub
+	repo        ChatRepository
+	authUC      AuthUseCaseInterface
+	hub         *WebSocketHub
+	broadcaster ChatBroadcaster
+	tickets     *ticketStore
+	users       UserRepository
+	editWindow  time.Duration
+	moderation  ChatModerationRepository
+	flood       *floodLimiter
+}
+
+// ChatOptions configures a ChatUseCase. Zero values keep the defaults.
+type ChatOptions struct {
+	WebSocket WebSocketSettings
+	// Broadcaster delivers messages to the clients of every instance;
+	// nil means a MemoryChatBroadcaster, which only reaches this one.
+	Broadcaster ChatBroadcaster
+	// EditWindow is how long authors may change their messages;
+	// zero means DefaultChatEditWindow.
+	EditWindow time.Duration
+	// Users looks up roles, so admins can delete any message. Without it
+	// only authors can.
+	Users UserRepository
+	// Moderation enables mutes, bans and slow mode; without it only the
+	// flood limit applies.
+	Moderation ChatModerationRepository
+	Flood      FloodLimit
 }
 
 type AuthUseCaseInterface interface {
 	SecretKey() []byte
 	GenerateToken(userID int, username string) (string, error)
 	ParseToken(tokenString string) (int64, string, error)
+	ParseTokenClaims(tokenString string) (int64, string, time.Time, error)
 }
 
 type WebSocketHub struct {
-	clients         map[*WebSocketClient]bool
-	broadcast       chan entity.ChatMessage
-	register        chan *WebSocketClient
-	unregister      chan *WebSocketClient
-	maxConnections  int
+	clients          map[*WebSocketClient]bool
+	events           chan entity.ChatEvent // события от ChatBroadcaster
+	register         chan *WebSocketClient
+	unregister       chan *WebSocketClient
+	subscribe        chan roomSubscription
+	presenceRequests chan *WebSocketClient
+	users            map[int]map[*WebSocketClient]bool // соединения по пользователю, для личных сообщений
+	// online — кто подключён хоть к одному экземпляру, по событиям presence.
+	online   map[int]*userPresence
+	instance string
+	// Изменения присутствия этого экземпляра ждут отправки в presenceQueue:
+	// хаб не может публиковать сам, брокер вызывает его же.
+	presenceMu      sync.Mutex
+	presenceQueue   []entity.PresenceEvent
+	presenceReady   chan struct{}
+	shutdown        chan struct{}
+	closing         bool // хаб закрывает соединения; меняется только в run
+	settings        WebSocketSettings
 	connectionCount int
+	anonymousCount  int            // анонимные потоки, считаются отдельно
+	draining        bool           // под mutex: новые соединения не принимаются
+	connections     sync.WaitGroup // живые соединения, которых ждёт Shutdown
 	mutex           sync.Mutex
 }
 
 type ChatUseCaseInterface interface {
 	SendMessage(ctx context.Context, message *entity.ChatMessage) error
-	GetMessages(ctx context.Context, limit int) ([]entity.ChatMessage, error)
-	HandleWebSocket(conn WebSocketConnection) // Используем интерфейс вместо *websocket.Conn
+	GetMessages(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error)
+	EditMessage(ctx context.Context, messageID, userID int, text string) (*entity.ChatMessage, error)
+	DeleteMessage(ctx context.Context, messageID, userID int) error
+	CreateRoom(ctx context.Context, room *entity.ChatRoom) error
+	GetRooms(ctx context.Context) ([]entity.ChatRoom, error)
+	JoinRoom(ctx context.Context, roomID, userID int) error
+	SendDirectMessage(ctx context.Context, msg *entity.DirectMessage) error
+	GetConversations(ctx context.Context, userID int) (*entity.ConversationList, error)
+	GetDirectMessages(ctx context.Context, userID, peerID, beforeID, limit int) (*entity.DirectMessagePage, error)
+	MarkConversationRead(ctx context.Context, userID, peerID int) error
+	Authenticate(token string) (*ChatIdentity, error)
+	IssueTicket(identity ChatIdentity) (string, time.Time, error)
+	RedeemTicket(ticket string) (*ChatIdentity, error)
+	HandleWebSocket(conn WebSocketConnection, identity ChatIdentity, lastSeenID int) // Используем интерфейс вместо *websocket.Conn
+	OpenEventStream(opts EventStreamOptions) (EventStream, error)
+	OpenChatSession(identity ChatIdentity, lastSeenID int) (ChatSession, error)
 }
 type WebSocketClient struct {
 	conn WebSocketConnection
-	send chan entity.ChatMessage
+	// send никогда не закрывается: писать в него могут и хаб, и readPump.
+	// Завершение соединения сигнализирует done, см. close.
+	send      chan Envelope
+	done      chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+	rooms     map[int]bool    // комнаты, на которые подписан клиент; меняется только в hub.run
+	feeds     map[string]bool // ленты форума (FeedPosts, FeedComments); меняется только в hub.run
+	posts     map[int]bool    // посты, за комментариями которых следит клиент; меняется только в hub.run
+	identity  ChatIdentity    // пользователь, прошедший аутентификацию при подключении; UserID 0 — аноним
+	anonymous bool            // занимает место из MaxAnonymousStreams
+	// replayedUpTo — последний ID общей комнаты, отправленный при повторе
+	// истории; writePump пропускает живые сообщения, которые уже были в повторе.
+	replayedUpTo int
+	typedAt      map[string]time.Time // последние события typing; только для readPump
+}
+
+// roomSubscription asks the hub to add a client to a room or remove it.
+// With postID set it is about the comments of that post instead.
+type roomSubscription struct {
+	client *WebSocketClient
+	roomID int
+	postID int
+	join   bool
 }
 
 func NewChatUseCase(repo ChatRepository, authUC AuthUseCaseInterface) *ChatUseCase {
-	hub := newWebSocketHub(100)
+	return NewChatUseCaseWithOptions(repo, authUC, ChatOptions{})
+}
+
+// NewChatUseCaseWithOptions is NewChatUseCase with custom WebSocket limits
+// and fan-out.
+func NewChatUseCaseWithOptions(repo ChatRepository, authUC AuthUseCaseInterface, opts ChatOptions) *ChatUseCase {
+	hub := newWebSocketHub(opts.WebSocket.withDefaults())
 	go hub.run()
 
-	return &ChatUseCase{
-		repo:   repo,
-		authUC: authUC,
-		hub:    hub,
+	broadcaster := opts.Broadcaster
+	if broadcaster == nil {
+		broadcaster = NewMemoryChatBroadcaster()
 	}
-}
-func (uc *ChatUseCase) startCleanupRoutine() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
+	broadcaster.Subscribe(hub.dispatch)
+	go hub.publishPresence(broadcaster)
 
-	for range ticker.C {
-		ctx := context.Background()
-		err := uc.repo.DeleteOldChatMessages(ctx, 30*time.Minute)
-		if err != nil {
-			log.Printf("Error cleaning old messages: %v", err)
-		}
+	editWindow := opts.EditWindow
+	if editWindow <= 0 {
+		editWindow = DefaultChatEditWindow
+	}
+
+	return &ChatUseCase{
+		repo:        repo,
+		authUC:      authUC,
+		hub:         hub,
+		broadcaster: broadcaster,
+		tickets:     newTicketStore(),
+		users:       opts.Users,
+		editWindow:  editWindow,
+		moderation:  opts.Moderation,
+		flood:       newFloodLimiter(opts.Flood.withDefaults()),
 	}
 }
-func newWebSocketHub(maxConnections int) *WebSocketHub {
+func newWebSocketHub(settings WebSocketSettings) *WebSocketHub {
 	return &WebSocketHub{
-		broadcast:      make(chan entity.ChatMessage),
-		register:       make(chan *WebSocketClient),
-		unregister:     make(chan *WebSocketClient),
-		clients:        make(map[*WebSocketClient]bool),
-		maxConnections: maxConnections,
+		events:           make(chan entity.ChatEvent),
+		register:         make(chan *WebSocketClient),
+		unregister:       make(chan *WebSocketClient),
+		subscribe:        make(chan roomSubscription),
+		presenceRequests: make(chan *WebSocketClient),
+		clients:          make(map[*WebSocketClient]bool),
+		users:            make(map[int]map[*WebSocketClient]bool),
+		online:           make(map[int]*userPresence),
+		instance:         newInstanceID(),
+		presenceReady:    make(chan struct{}, 1),
+		shutdown:         make(chan struct{}),
+		settings:         settings,
 	}
 }
 
@@ -166,128 +309,458 @@ func (h *WebSocketHub) run() {
 	for {
 		select {
 		case client := <-h.register:
+			if h.closing {
+				client.close(websocket.CloseGoingAway, "server shutting down")
+				continue
+			}
 			h.clients[client] = true
-		case client := <-h.unregister:
-			if _, ok := h.clients[client]; ok {
-				close(client.send)
-				delete(h.clients, client)
+			userID := client.identity.UserID
+			if userID == 0 {
+				continue // анонимные потоки только читают и в присутствии не участвуют
+			}
+			if h.users[userID] == nil {
+				h.users[userID] = make(map[*WebSocketClient]bool)
+				h.announce(userID, client.identity.Username, true)
 			}
-		case message := <-h.broadcast:
+			h.users[userID][client] = true
+			h.deliver(client, h.presenceSnapshot())
+		case client := <-h.presenceRequests:
+			if h.clients[client] {
+				h.deliver(client, h.presenceSnapshot())
+			}
+		case client := <-h.unregister:
+			h.removeClient(client)
+		case <-h.shutdown:
+			h.closing = true
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
+				client.close(websocket.CloseGoingAway, "server shutting down")
+				h.removeClient(client)
 			}
+		case sub := <-h.subscribe:
+			if _, ok := h.clients[sub.client]; !ok {
+				continue
+			}
+			if sub.postID != 0 {
+				h.watchPost(sub)
+				continue
+			}
+			if sub.join {
+				sub.client.rooms[sub.roomID] = true
+			} else {
+				delete(sub.client.rooms, sub.roomID)
+			}
+		case event := <-h.events:
+			h.handleEvent(event)
 		}
 	}
 }
 
-func (uc *ChatUseCase) HandleWebSocket(conn WebSocketConnection) {
-	uc.hub.mutex.Lock()
-	if uc.hub.connectionCount >= uc.hub.maxConnections {
-		conn.WriteMessage(websocket.CloseMessage, []byte("too many connections"))
-		conn.Close()
-		uc.hub.mutex.Unlock()
+// deliver queues a frame for the client without blocking the hub. A client
+// whose buffer is full is dropped; its pumps finish on their own.
+func (h *WebSocketHub) deliver(client *WebSocketClient, env Envelope) {
+	select {
+	case client.send <- env:
+	default:
+		client.close(websocket.CloseTryAgainLater, "slow consumer")
+		h.removeClient(client)
+	}
+}
+
+// removeClient forgets the client and tells its writePump to stop. The send
+// channel stays open, so a readPump that is still running can't panic on it.
+func (h *WebSocketHub) removeClient(client *WebSocketClient) {
+	if _, ok := h.clients[client]; !ok {
 		return
 	}
-	uc.hub.connectionCount++
-	uc.hub.mutex.Unlock()
+	client.close(websocket.CloseNormalClosure, "")
+	delete(h.clients, client)
+	if conns, ok := h.users[client.identity.UserID]; ok {
+		delete(conns, client)
+		if len(conns) == 0 {
+			delete(h.users, client.identity.UserID)
+			h.announce(client.identity.UserID, client.identity.Username, false)
+		}
+	}
+}
 
-	client := &WebSocketClient{
-		conn: conn,
-		send: make(chan entity.ChatMessage, 256),
+// HandleWebSocket serves an upgraded connection for an already authenticated
+// user. A positive lastSeenID replays the default room's messages the client
+// missed before live delivery starts. The connection is closed when the
+// user's token expires.
+func (uc *ChatUseCase) HandleWebSocket(conn WebSocketConnection, identity ChatIdentity, lastSeenID int) {
+	settings := uc.hub.settings
+	reject := func(code int, text string) {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text),
+			time.Now().Add(settings.WriteWait))
+		conn.Close()
+	}
+
+	if uc.isBanned(identity.UserID) {
+		reject(websocket.ClosePolicyViolation, "banned")
+		return
 	}
+	if code, text, ok := uc.hub.admit(false); !ok {
+		reject(code, text)
+		return
+	}
+	defer uc.hub.release(false)
+
+	client := newHubClient(conn, identity, settings.SendBuffer)
+	client.rooms[entity.DefaultChatRoomID] = true
 	uc.hub.register <- client
 
+	if !identity.ExpiresAt.IsZero() {
+		expiry := time.AfterFunc(time.Until(identity.ExpiresAt), func() {
+			client.close(websocket.ClosePolicyViolation, "token expired")
+		})
+		defer expiry.Stop()
+	}
+
+	// Клиент уже в хабе, и живые сообщения копятся в send, пока writePump не
+	// запущен: пропущенное пишем первым, без дыр между историей и живым потоком.
+	if lastSeenID > 0 {
+		err := uc.replayMissed(client, lastSeenID, func(env Envelope) error {
+			conn.SetWriteDeadline(time.Now().Add(settings.WriteWait))
+			return conn.WriteJSON(env)
+		})
+		if err != nil {
+			log.Printf("Error replaying chat history: %v", err)
+			client.reply(errorEnvelope("", "failed to replay missed messages"))
+		}
+	}
+
+	// Соединение закрывает только writePump; readPump после этого получает
+	// ошибку чтения и выходит. Обе горутины должны завершиться до возврата.
+	writerDone := make(chan struct{})
 	go func() {
-		client.writePump()
-		uc.hub.mutex.Lock()
-		uc.hub.connectionCount--
-		uc.hub.mutex.Unlock()
+		client.writePump(settings)
+		close(writerDone)
 	}()
-	client.readPump(uc)
+	client.readPump(uc, settings)
+	<-writerDone
 }
 
-func (c *WebSocketClient) readPump(uc *ChatUseCase) {
+func (c *WebSocketClient) readPump(uc *ChatUseCase, settings WebSocketSettings) {
+	// Соединение закроет writePump, отправив перед этим close-фрейм.
 	defer func() {
 		uc.hub.unregister <- c
-		c.conn.Close()
+		c.close(websocket.CloseNormalClosure, "")
 	}()
 
-	// При подключении очистим старые сообщения
+	c.conn.SetReadLimit(settings.ReadLimit)
+	c.conn.SetReadDeadline(time.Now().Add(settings.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(settings.PongWait))
+	})
+
 	ctx := context.Background()
-	_ = uc.repo.DeleteOldChatMessages(ctx, 30*time.Minute)
 	for {
-		var msg struct {
-			Text  string `json:"text"`
-			Token string `json:"token"`
-		}
-		err := c.conn.ReadJSON(&msg)
-		if err != nil {
+		var env Envelope
+		if err := c.conn.ReadJSON(&env); err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
-			break
+			return
 		}
+		c.handleFrame(ctx, uc, env)
+	}
+}
 
-		userID, username, err := uc.authUC.ParseToken(msg.Token)
-		if err != nil {
-			log.Printf("Token validation error: %v", err)
-			c.conn.WriteJSON(map[string]string{"error": "invalid token"})
-			continue
-		}
+// admit reserves a connection slot, from MaxAnonymousStreams for anonymous
+// clients and from MaxConnections otherwise. When the hub is shutting down or
+// full it returns the close code and reason to reject the connection with
+// instead.
+func (h *WebSocketHub) admit(anonymous bool) (int, string, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.draining {
+		return websocket.CloseGoingAway, "server shutting down", false
+	}
+	count, limit := &h.connectionCount, h.settings.MaxConnections
+	if anonymous {
+		count, limit = &h.anonymousCount, h.settings.MaxAnonymousStreams
+	}
+	if *count >= limit {
+		return websocket.CloseTryAgainLater, "too many connections", false
+	}
+	*count++
+	h.connections.Add(1)
+	return 0, "", true
+}
 
-		if strings.TrimSpace(msg.Text) == "" {
-			c.conn.WriteJSON(map[string]string{"error": "message cannot be empty"})
-			continue
-		}
+// release frees a slot taken by admit once the connection is gone.
+func (h *WebSocketHub) release(anonymous bool) {
+	h.mutex.Lock()
+	if anonymous {
+		h.anonymousCount--
+	} else {
+		h.connectionCount--
+	}
+	h.mutex.Unlock()
+	h.connections.Done()
+}
 
-		chatMsg := entity.ChatMessage{
-			UserID:    int(userID), // Convert int64 to int for entity
-			Author:    username,
-			Text:      msg.Text,
-			CreatedAt: time.Now(),
-		}
+// newHubClient creates a client that isn't subscribed to anything yet; conn
+// is nil for streams that don't use a WebSocket.
+func newHubClient(conn WebSocketConnection, identity ChatIdentity, sendBuffer int) *WebSocketClient {
+	return &WebSocketClient{
+		conn:     conn,
+		send:     make(chan Envelope, sendBuffer),
+		done:     make(chan struct{}),
+		rooms:    make(map[int]bool),
+		feeds:    make(map[string]bool),
+		posts:    make(map[int]bool),
+		identity: identity,
+		typedAt:  make(map[string]time.Time),
+	}
+}
 
-		if err := uc.repo.SaveChatMessage(context.Background(), &chatMsg); err != nil {
-			log.Printf("Error saving message: %v", err)
-			c.conn.WriteJSON(map[string]string{"error": "failed to save message"})
-			continue
+// replayMissed passes the default room's messages newer than lastSeenID to
+// write, which sends them straight to the client. It must run before the
+// client's queue is drained, so nothing live slips in between.
+func (uc *ChatUseCase) replayMissed(c *WebSocketClient, lastSeenID int, write func(Envelope) error) error {
+	ctx := context.Background()
+	after := lastSeenID
+	for sent := 0; sent < maxReplayMessages; {
+		messages, err := uc.repo.GetChatMessages(ctx, entity.ChatHistoryFilter{
+			RoomID:  entity.DefaultChatRoomID,
+			AfterID: after,
+			Limit:   MaxChatHistoryLimit,
+		})
+		if err != nil {
+			return err
+		}
+		for _, msg := range messages {
+			env := newEnvelope(EventMessage, "", msg)
+			env.replayID = msg.ID
+			if err := write(env); err != nil {
+				return err
+			}
+			after = msg.ID
+		}
+		c.replayedUpTo = after
+		sent += len(messages)
+		if len(messages) < MaxChatHistoryLimit {
+			return nil
 		}
-
-		uc.hub.broadcast <- chatMsg
 	}
+	// Остальное клиент дочитает через GET /chat/messages?after=...
+	return write(newEnvelope(EventSystem, "", SystemData{
+		Code:    "history_truncated",
+		Message: "too many missed messages, fetch the rest over HTTP",
+		After:   after,
+	}))
 }
 
-func (c *WebSocketClient) writePump() {
-	defer c.conn.Close()
+// writePump is the only goroutine that writes to the connection once it is
+// running. It sends queued frames and pings, and closes the connection when
+// the client is closed or a write fails.
+func (c *WebSocketClient) writePump(settings WebSocketSettings) {
+	ticker := time.NewTicker(settings.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
 	for {
-		message, ok := <-c.send
-		if !ok {
-			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+		select {
+		case <-c.done:
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(c.closeCode, c.closeText),
+				time.Now().Add(settings.WriteWait))
 			return
+		case env := <-c.send:
+			if env.replayID > 0 && env.replayID <= c.replayedUpTo {
+				continue // уже отправлено при повторе истории
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(settings.WriteWait))
+			if err := c.conn.WriteJSON(env); err != nil {
+				// Клиент пропал или не читает: закрываем соединение, readPump
+				// получит ошибку чтения и снимет клиента с хаба.
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(settings.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
-		c.conn.WriteJSON(message)
 	}
 }
 
+// SendMessage stores the message and broadcasts it to the room's subscribers.
+// Only members may post to a room other than the default one; muted users,
+// users over the flood limit and those waiting out slow mode may not post.
 func (uc *ChatUseCase) SendMessage(ctx context.Context, message *entity.ChatMessage) error {
+	if message.RoomID == 0 {
+		message.RoomID = entity.DefaultChatRoomID
+	}
+	if err := uc.checkCanPost(ctx, message.UserID); err != nil {
+		return err
+	}
+	if message.RoomID != entity.DefaultChatRoomID {
+		member, err := uc.repo.IsChatRoomMember(ctx, message.RoomID, message.UserID)
+		if err != nil {
+			return err
+		}
+		if !member {
+			return ErrNotRoomMember
+		}
+	}
+	if err := uc.checkSlowMode(ctx, message.RoomID, message.UserID); err != nil {
+		return err
+	}
 	if err := uc.repo.SaveChatMessage(ctx, message); err != nil {
 		return err // Возвращаем ошибку из репозитория
 	}
-	uc.hub.broadcast <- *message
+	// Сообщение уже сохранено: ошибка рассылки не повод отвечать клиенту
+	// ошибкой, иначе он отправит его повторно.
+	if err := uc.broadcaster.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventMessage, Message: message}); err != nil {
+		log.Printf("Error publishing chat message: %v", err)
+	}
 	return nil
 }
 
-func (uc *ChatUseCase) GetMessages(ctx context.Context, limit int) ([]entity.ChatMessage, error) {
-	// Сначала очистим старые сообщения перед получением
-	err := uc.repo.DeleteOldChatMessages(ctx, 30*time.Minute)
+// GetMessages returns a page of a room's history. See
+// entity.ChatHistoryFilter for how the before/after cursors order the page.
+func (uc *ChatUseCase) GetMessages(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
+	if filter.RoomID == 0 {
+		filter.RoomID = entity.DefaultChatRoomID
+	}
+	if filter.BeforeID < 0 || filter.AfterID < 0 {
+		return nil, fmt.Errorf("%w: message IDs must be positive", ErrInvalidCursor)
+	}
+	if filter.BeforeID > 0 && filter.AfterID > 0 && filter.BeforeID <= filter.AfterID {
+		return nil, fmt.Errorf("%w: before must be greater than after", ErrInvalidCursor)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultChatHistoryLimit
+	}
+	if filter.Limit > MaxChatHistoryLimit {
+		filter.Limit = MaxChatHistoryLimit
+	}
+	if filter.RoomID != entity.DefaultChatRoomID {
+		if _, err := uc.repo.GetChatRoomByID(ctx, filter.RoomID); err != nil {
+			return nil, err
+		}
+	}
+
+	messages, err := uc.repo.GetChatMessages(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
+	if messages == nil {
+		messages = []entity.ChatMessage{}
+	}
+	return messages, nil
+}
 
-	return uc.repo.GetChatMessages(ctx, limit)
+func (uc *ChatUseCase) CreateRoom(ctx context.Context, room *entity.ChatRoom) error {
+	room.Name = strings.TrimSpace(room.Name)
+	if room.Name == "" || utf8.RuneCountInString(room.Name) > maxChatRoomName {
+		return fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidChatRoom, maxChatRoomName)
+	}
+	if room.CreatedBy <= 0 {
+		return errors.New("user ID cannot be empty")
+	}
+	return uc.repo.CreateChatRoom(ctx, room)
+}
+
+func (uc *ChatUseCase) GetRooms(ctx context.Context) ([]entity.ChatRoom, error) {
+	rooms, err := uc.repo.GetChatRooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rooms == nil {
+		rooms = []entity.ChatRoom{}
+	}
+	return rooms, nil
+}
+
+// JoinRoom makes the user a member of an existing room.
+func (uc *ChatUseCase) JoinRoom(ctx context.Context, roomID, userID int) error {
+	if roomID <= 0 {
+		return fmt.Errorf("%w: room ID is required", ErrInvalidChatRoom)
+	}
+	if _, err := uc.repo.GetChatRoomByID(ctx, roomID); err != nil {
+		return err
+	}
+	return uc.repo.JoinChatRoom(ctx, roomID, userID)
+}
+
+// SendDirectMessage stores a private message and delivers it to the open
+// connections of both participants. It never goes through the room broadcast.
+func (uc *ChatUseCase) SendDirectMessage(ctx context.Context, msg *entity.DirectMessage) error {
+	if msg.RecipientID <= 0 || msg.RecipientID == msg.SenderID {
+		return fmt.Errorf("%w: recipient must be another user", ErrInvalidDirectMessage)
+	}
+	if strings.TrimSpace(msg.Text) == "" {
+		return fmt.Errorf("%w: text cannot be empty", ErrInvalidDirectMessage)
+	}
+	if err := uc.checkCanPost(ctx, msg.SenderID); err != nil {
+		return err
+	}
+	if err := uc.repo.SaveDirectMessage(ctx, msg); err != nil {
+		return err
+	}
+	if err := uc.broadcaster.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventDirectMessage, Direct: msg}); err != nil {
+		log.Printf("Error publishing direct message: %v", err)
+	}
+	return nil
+}
+
+// GetConversations lists the user's DM conversations with unread counts.
+func (uc *ChatUseCase) GetConversations(ctx context.Context, userID int) (*entity.ConversationList, error) {
+	conversations, err := uc.repo.GetConversations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	list := &entity.ConversationList{Conversations: conversations}
+	if list.Conversations == nil {
+		list.Conversations = []entity.Conversation{}
+	}
+	for _, conv := range conversations {
+		list.UnreadTotal += conv.UnreadCount
+	}
+	return list, nil
+}
+
+// GetDirectMessages returns a page of the conversation between userID and
+// peerID, newest first. beforeID of 0 starts from the latest message.
+func (uc *ChatUseCase) GetDirectMessages(ctx context.Context, userID, peerID, beforeID, limit int) (*entity.DirectMessagePage, error) {
+	if peerID <= 0 || peerID == userID {
+		return nil, fmt.Errorf("%w: peer must be another user", ErrInvalidDirectMessage)
+	}
+	if beforeID < 0 {
+		return nil, fmt.Errorf("%w: before must be a message ID", ErrInvalidDirectMessage)
+	}
+	if limit <= 0 {
+		limit = DefaultDirectMessagePageSize
+	}
+	if limit > MaxDirectMessagePageSize {
+		limit = MaxDirectMessagePageSize
+	}
+
+	// лишняя строка показывает, есть ли более старые сообщения
+	messages, err := uc.repo.GetDirectMessages(ctx, userID, peerID, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &entity.DirectMessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		page.NextBefore = page.Messages[limit-1].ID
+	}
+	if page.Messages == nil {
+		page.Messages = []entity.DirectMessage{}
+	}
+	return page, nil
+}
+
+// MarkConversationRead marks everything peerID has sent to userID as read.
+func (uc *ChatUseCase) MarkConversationRead(ctx context.Context, userID, peerID int) error {
+	if peerID <= 0 || peerID == userID {
+		return fmt.Errorf("%w: peer must be another user", ErrInvalidDirectMessage)
+	}
+	return uc.repo.MarkDirectMessagesRead(ctx, userID, peerID)
 }
@@ -3,14 +3,21 @@ package usecase_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/perfect1337/forum-service/internal/entity"
 	"github.com/perfect1337/forum-service/internal/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockChatRepository мокает репозиторий чата
@@ -23,13 +30,77 @@ func (m *MockChatRepository) SaveChatMessage(ctx context.Context, msg *entity.Ch
 	return args.Error(0)
 }
 
-func (m *MockChatRepository) GetChatMessages(ctx context.Context, limit int) ([]entity.ChatMessage, error) {
-	args := m.Called(ctx, limit)
+func (m *MockChatRepository) GetChatMessages(ctx context.Context, filter entity.ChatHistoryFilter) ([]entity.ChatMessage, error) {
+	args := m.Called(ctx, filter)
 	return args.Get(0).([]entity.ChatMessage), args.Error(1)
 }
 
-func (m *MockChatRepository) DeleteOldChatMessages(ctx context.Context, olderThan time.Duration) error {
-	args := m.Called(ctx, olderThan)
+func (m *MockChatRepository) GetChatMessageByID(ctx context.Context, id int) (*entity.ChatMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChatMessage), args.Error(1)
+}
+
+func (m *MockChatRepository) UpdateChatMessageText(ctx context.Context, id int, text string, maxAge time.Duration) (*entity.ChatMessage, error) {
+	args := m.Called(ctx, id, text, maxAge)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChatMessage), args.Error(1)
+}
+
+func (m *MockChatRepository) DeleteChatMessage(ctx context.Context, id int, maxAge time.Duration) error {
+	args := m.Called(ctx, id, maxAge)
+	return args.Error(0)
+}
+
+func (m *MockChatRepository) CreateChatRoom(ctx context.Context, room *entity.ChatRoom) error {
+	args := m.Called(ctx, room)
+	return args.Error(0)
+}
+
+func (m *MockChatRepository) GetChatRooms(ctx context.Context) ([]entity.ChatRoom, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entity.ChatRoom), args.Error(1)
+}
+
+func (m *MockChatRepository) GetChatRoomByID(ctx context.Context, id int) (*entity.ChatRoom, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ChatRoom), args.Error(1)
+}
+
+func (m *MockChatRepository) JoinChatRoom(ctx context.Context, roomID, userID int) error {
+	args := m.Called(ctx, roomID, userID)
+	return args.Error(0)
+}
+
+func (m *MockChatRepository) IsChatRoomMember(ctx context.Context, roomID, userID int) (bool, error) {
+	args := m.Called(ctx, roomID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockChatRepository) SaveDirectMessage(ctx context.Context, msg *entity.DirectMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockChatRepository) GetConversations(ctx context.Context, userID int) ([]entity.Conversation, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Conversation), args.Error(1)
+}
+
+func (m *MockChatRepository) GetDirectMessages(ctx context.Context, userID, peerID, beforeID, limit int) ([]entity.DirectMessage, error) {
+	args := m.Called(ctx, userID, peerID, beforeID, limit)
+	return args.Get(0).([]entity.DirectMessage), args.Error(1)
+}
+
+func (m *MockChatRepository) MarkDirectMessagesRead(ctx context.Context, userID, peerID int) error {
+	args := m.Called(ctx, userID, peerID)
 	return args.Error(0)
 }
 
@@ -52,6 +123,77 @@ func (m *mockAuthUC) ParseToken(tokenString string) (int64, string, error) {
 	return args.Get(0).(int64), args.String(1), args.Error(2)
 }
 
+func (m *mockAuthUC) ParseTokenClaims(tokenString string) (int64, string, time.Time, error) {
+	args := m.Called(tokenString)
+	return args.Get(0).(int64), args.String(1), args.Get(2).(time.Time), args.Error(3)
+}
+
+// newChatServer serves uc over WebSocket, authenticating the upgrade with
+// the Authorization header the same way the HTTP handler does.
+func newChatServer(t *testing.T, uc *usecase.ChatUseCase) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := uc.Authenticate(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		lastSeenID, _ := strconv.Atoi(r.URL.Query().Get("last_seen_id"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		uc.HandleWebSocket(conn, *identity, lastSeenID)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func dialChat(t *testing.T, server *httptest.Server, token string) *websocket.Conn {
+	return dialChatURL(t, server, token, "")
+}
+
+func dialChatURL(t *testing.T, server *httptest.Server, token, query string) *websocket.Conn {
+	header := http.Header{"Authorization": {"Bearer " + token}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+query, header)
+	require.NoError(t, err)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// sendEvent writes a protocol frame of the given type.
+func sendEvent(t *testing.T, conn *websocket.Conn, eventType string, data interface{}) {
+	require.NoError(t, conn.WriteJSON(map[string]interface{}{"v": usecase.ChatProtocolVersion, "type": eventType, "data": data}))
+}
+
+// readEvent reads frames until one of the given type arrives, decoding its
+// data into v. Presence updates are skipped unless they are what we wait for.
+func readEvent(t *testing.T, conn *websocket.Conn, eventType string, v interface{}) usecase.Envelope {
+	for {
+		var env usecase.Envelope
+		require.NoError(t, conn.ReadJSON(&env))
+		if env.Type == usecase.EventPresence && eventType != usecase.EventPresence {
+			continue
+		}
+		require.Equal(t, eventType, env.Type, "data: %s", env.Data)
+		if v != nil {
+			require.NoError(t, json.Unmarshal(env.Data, v))
+		}
+		return env
+	}
+}
+
+// readUntilClosed skips frames until the connection fails and returns the
+// error, which carries the close code.
+func readUntilClosed(conn *websocket.Conn) error {
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return err
+		}
+	}
+}
+
 // TestChatUseCase_SendMessage тестирует отправку сообщений
 func TestChatUseCase_SendMessage(t *testing.T) {
 	t.Run("Успешная отправка", func(t *testing.T) {
@@ -83,6 +225,250 @@ func TestChatUseCase_SendMessage(t *testing.T) {
 	})
 }
 
+func TestChatUseCase_SendMessageToRoom(t *testing.T) {
+	t.Run("Не участник комнаты", func(t *testing.T) {
+		mockRepo := new(MockChatRepository)
+		uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+
+		mockRepo.On("IsChatRoomMember", mock.Anything, 2, 7).Return(false, nil)
+
+		err := uc.SendMessage(context.Background(), &entity.ChatMessage{RoomID: 2, UserID: 7, Text: "hi"})
+		assert.ErrorIs(t, err, usecase.ErrNotRoomMember)
+		mockRepo.AssertNotCalled(t, "SaveChatMessage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Комната по умолчанию", func(t *testing.T) {
+		mockRepo := new(MockChatRepository)
+		uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+
+		msg := &entity.ChatMessage{UserID: 7, Text: "hi"}
+		mockRepo.On("SaveChatMessage", mock.Anything, msg).Return(nil)
+
+		assert.NoError(t, uc.SendMessage(context.Background(), msg))
+		assert.Equal(t, entity.DefaultChatRoomID, msg.RoomID)
+		mockRepo.AssertNotCalled(t, "IsChatRoomMember", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestChatUseCase_Rooms(t *testing.T) {
+	t.Run("Пустое имя", func(t *testing.T) {
+		uc := usecase.NewChatUseCase(new(MockChatRepository), new(mockAuthUC))
+		err := uc.CreateRoom(context.Background(), &entity.ChatRoom{Name: "   ", CreatedBy: 1})
+		assert.ErrorIs(t, err, usecase.ErrInvalidChatRoom)
+	})
+
+	t.Run("Создание", func(t *testing.T) {
+		mockRepo := new(MockChatRepository)
+		uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+
+		room := &entity.ChatRoom{Name: "  golang ", CreatedBy: 1}
+		mockRepo.On("CreateChatRoom", mock.Anything, room).Return(nil)
+
+		assert.NoError(t, uc.CreateRoom(context.Background(), room))
+		assert.Equal(t, "golang", room.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Вход в несуществующую комнату", func(t *testing.T) {
+		mockRepo := new(MockChatRepository)
+		uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+
+		mockRepo.On("GetChatRoomByID", mock.Anything, 5).Return(nil, usecase.ErrNotFound)
+
+		err := uc.JoinRoom(context.Background(), 5, 1)
+		assert.ErrorIs(t, err, usecase.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "JoinChatRoom", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("История несуществующей комнаты", func(t *testing.T) {
+		mockRepo := new(MockChatRepository)
+		uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+
+		mockRepo.On("GetChatRoomByID", mock.Anything, 5).Return(nil, usecase.ErrNotFound)
+
+		_, err := uc.GetMessages(context.Background(), entity.ChatHistoryFilter{RoomID: 5, Limit: 100})
+		assert.ErrorIs(t, err, usecase.ErrNotFound)
+	})
+}
+
+// TestChatUseCase_RoomBroadcast проверяет, что сообщение комнаты получают
+// только подписанные на неё клиенты.
+func TestChatUseCase_RoomBroadcast(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	authUC := new(mockAuthUC)
+	uc := usecase.NewChatUseCase(mockRepo, authUC)
+
+	mockRepo.On("GetChatRoomByID", mock.Anything, 2).Return(&entity.ChatRoom{ID: 2, Name: "golang"}, nil)
+	mockRepo.On("JoinChatRoom", mock.Anything, 2, 2).Return(nil)
+	mockRepo.On("IsChatRoomMember", mock.Anything, 2, 2).Return(true, nil)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+	authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+	authUC.On("ParseTokenClaims", "bob").Return(int64(2), "bob", time.Time{}, nil)
+
+	server := newChatServer(t, uc)
+
+	// Своё сообщение, вернувшееся обратно, показывает, что клиент уже в хабе
+	var got entity.ChatMessage
+	alice := dialChat(t, server, "alice")
+	sendEvent(t, alice, usecase.EventMessage, map[string]interface{}{"text": "alice here"})
+	readEvent(t, alice, usecase.EventMessage, &got)
+
+	bob := dialChat(t, server, "bob")
+	sendEvent(t, bob, usecase.EventJoin, map[string]interface{}{"room_id": 2})
+	sendEvent(t, bob, usecase.EventMessage, map[string]interface{}{"room_id": 2, "text": "hello room"})
+
+	readEvent(t, bob, usecase.EventMessage, &got)
+	assert.Equal(t, 2, got.RoomID)
+	assert.Equal(t, "hello room", got.Text)
+	assert.Equal(t, "bob", got.Author, "author comes from the handshake identity")
+
+	// Alice подписана только на общую комнату: первым она должна получить
+	// сообщение из неё, а не из комнаты 2.
+	require.NoError(t, uc.SendMessage(context.Background(), &entity.ChatMessage{UserID: 1, Text: "hello general"}))
+	readEvent(t, alice, usecase.EventMessage, &got)
+	assert.Equal(t, entity.DefaultChatRoomID, got.RoomID)
+	assert.Equal(t, "hello general", got.Text)
+}
+
+func TestChatUseCase_SendDirectMessage(t *testing.T) {
+	t.Run("Сообщение самому себе", func(t *testing.T) {
+		mockRepo := new(MockChatRepository)
+		uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+
+		err := uc.SendDirectMessage(context.Background(), &entity.DirectMessage{SenderID: 1, RecipientID: 1, Text: "hi"})
+		assert.ErrorIs(t, err, usecase.ErrInvalidDirectMessage)
+		mockRepo.AssertNotCalled(t, "SaveDirectMessage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Пустой текст", func(t *testing.T) {
+		uc := usecase.NewChatUseCase(new(MockChatRepository), new(mockAuthUC))
+		err := uc.SendDirectMessage(context.Background(), &entity.DirectMessage{SenderID: 1, RecipientID: 2, Text: "  "})
+		assert.ErrorIs(t, err, usecase.ErrInvalidDirectMessage)
+	})
+}
+
+func TestChatUseCase_GetDirectMessages(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+
+	// Запрашиваем 2, репозиторий просят о 3, чтобы понять, есть ли ещё
+	mockRepo.On("GetDirectMessages", mock.Anything, 1, 2, 0, 3).Return([]entity.DirectMessage{
+		{ID: 30}, {ID: 20}, {ID: 10},
+	}, nil)
+
+	page, err := uc.GetDirectMessages(context.Background(), 1, 2, 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 2)
+	assert.Equal(t, 20, page.NextBefore)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChatUseCase_GetConversations(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+
+	mockRepo.On("GetConversations", mock.Anything, 1).Return([]entity.Conversation{
+		{ID: 1, PeerID: 2, UnreadCount: 3},
+		{ID: 2, PeerID: 3, UnreadCount: 1},
+	}, nil)
+
+	list, err := uc.GetConversations(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, list.Conversations, 2)
+	assert.Equal(t, 4, list.UnreadTotal)
+}
+
+// TestChatUseCase_DirectMessageDelivery проверяет, что личное сообщение
+// получают только соединения собеседников.
+func TestChatUseCase_DirectMessageDelivery(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	authUC := new(mockAuthUC)
+	uc := usecase.NewChatUseCase(mockRepo, authUC)
+
+	mockRepo.On("SaveDirectMessage", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+	authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+	authUC.On("ParseTokenClaims", "bob").Return(int64(2), "bob", time.Time{}, nil)
+	authUC.On("ParseTokenClaims", "carol").Return(int64(3), "carol", time.Time{}, nil)
+
+	server := newChatServer(t, uc)
+
+	// Carol не участвует в переписке
+	var room entity.ChatMessage
+	carol := dialChat(t, server, "carol")
+	sendEvent(t, carol, usecase.EventMessage, map[string]interface{}{"text": "carol here"})
+	readEvent(t, carol, usecase.EventMessage, &room)
+
+	// Отправитель получает копию своего сообщения: по ней видно, что
+	// соединение уже в хабе.
+	var dm entity.DirectMessage
+	alice := dialChat(t, server, "alice")
+	sendEvent(t, alice, usecase.EventDirectMessage, map[string]interface{}{"to": 2, "text": "are you there?"})
+	readEvent(t, alice, usecase.EventDirectMessage, &dm)
+
+	bob := dialChat(t, server, "bob")
+	sendEvent(t, bob, usecase.EventDirectMessage, map[string]interface{}{"to": 1, "text": "hi alice"})
+	readEvent(t, bob, usecase.EventDirectMessage, &dm)
+	readEvent(t, alice, usecase.EventDirectMessage, &dm)
+	assert.Equal(t, 2, dm.SenderID)
+	assert.Equal(t, "hi alice", dm.Text)
+
+	require.NoError(t, uc.SendDirectMessage(context.Background(), &entity.DirectMessage{
+		SenderID: 1, RecipientID: 2, Author: "alice", Text: "hi bob",
+	}))
+	readEvent(t, bob, usecase.EventDirectMessage, &dm)
+	assert.Equal(t, "hi bob", dm.Text)
+
+	// Carol первым должна получить сообщение общей комнаты, а не чужие личные.
+	require.NoError(t, uc.SendMessage(context.Background(), &entity.ChatMessage{UserID: 1, Text: "hello general"}))
+	readEvent(t, carol, usecase.EventMessage, &room)
+	assert.Equal(t, "hello general", room.Text)
+}
+
+func TestChatUseCase_WebSocketAuth(t *testing.T) {
+	t.Run("Без токена", func(t *testing.T) {
+		authUC := new(mockAuthUC)
+		uc := usecase.NewChatUseCase(new(MockChatRepository), authUC)
+		server := newChatServer(t, uc)
+
+		_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		assert.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Истечение токена закрывает соединение", func(t *testing.T) {
+		authUC := new(mockAuthUC)
+		uc := usecase.NewChatUseCase(new(MockChatRepository), authUC)
+		authUC.On("ParseTokenClaims", "short").Return(int64(1), "alice", time.Now().Add(100*time.Millisecond), nil)
+		server := newChatServer(t, uc)
+
+		conn := dialChat(t, server, "short")
+		err := readUntilClosed(conn)
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+	})
+}
+
+func TestChatUseCase_Tickets(t *testing.T) {
+	uc := usecase.NewChatUseCase(new(MockChatRepository), new(mockAuthUC))
+	identity := usecase.ChatIdentity{UserID: 7, Username: "alice"}
+
+	ticket, expiresAt, err := uc.IssueTicket(identity)
+	require.NoError(t, err)
+	assert.NotEmpty(t, ticket)
+	assert.True(t, expiresAt.After(time.Now()))
+
+	got, err := uc.RedeemTicket(ticket)
+	require.NoError(t, err)
+	assert.Equal(t, identity, *got)
+
+	_, err = uc.RedeemTicket(ticket)
+	assert.ErrorIs(t, err, usecase.ErrInvalidTicket, "a ticket works only once")
+
+	_, err = uc.RedeemTicket("bogus")
+	assert.ErrorIs(t, err, usecase.ErrInvalidTicket)
+}
+
 // TestChatUseCase_GetMessages тестирует получение сообщений
 func TestChatUseCase_GetMessages(t *testing.T) {
 	t.Run("Успешное получение", func(t *testing.T) {
@@ -95,43 +481,86 @@ func TestChatUseCase_GetMessages(t *testing.T) {
 			{Text: "message 2"},
 		}
 
-		mockRepo.On("DeleteOldChatMessages", mock.Anything, 30*time.Minute).Return(nil)
-		mockRepo.On("GetChatMessages", mock.Anything, 100).Return(testMessages, nil)
+		mockRepo.On("GetChatMessages", mock.Anything, entity.ChatHistoryFilter{RoomID: 1, Limit: 100}).Return(testMessages, nil)
 
-		messages, err := uc.GetMessages(context.Background(), 100)
+		messages, err := uc.GetMessages(context.Background(), entity.ChatHistoryFilter{RoomID: 1, Limit: 100})
 		assert.NoError(t, err)
 		assert.Len(t, messages, 2)
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("Ошибка очистки", func(t *testing.T) {
+	t.Run("Ошибка получения", func(t *testing.T) {
 		mockRepo := new(MockChatRepository)
 		authUC := new(mockAuthUC)
 		uc := usecase.NewChatUseCase(mockRepo, authUC)
 
-		cleanupErr := errors.New("cleanup error")
-		mockRepo.On("DeleteOldChatMessages", mock.Anything, 30*time.Minute).Return(cleanupErr)
+		getErr := errors.New("get messages error")
+		mockRepo.On("GetChatMessages", mock.Anything, entity.ChatHistoryFilter{RoomID: 1, Limit: 100}).Return([]entity.ChatMessage{}, getErr)
 
-		_, err := uc.GetMessages(context.Background(), 100)
+		_, err := uc.GetMessages(context.Background(), entity.ChatHistoryFilter{RoomID: 1, Limit: 100})
 		assert.Error(t, err)
-		assert.Equal(t, cleanupErr, err)
+		assert.Equal(t, getErr, err)
 		mockRepo.AssertExpectations(t)
 	})
+}
 
-	t.Run("Ошибка получения", func(t *testing.T) {
+func TestChatUseCase_GetMessagesCursors(t *testing.T) {
+	t.Run("Лимит по умолчанию и максимум", func(t *testing.T) {
 		mockRepo := new(MockChatRepository)
-		authUC := new(mockAuthUC)
-		uc := usecase.NewChatUseCase(mockRepo, authUC)
+		uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
 
-		getErr := errors.New("get messages error")
-		mockRepo.On("DeleteOldChatMessages", mock.Anything, 30*time.Minute).Return(nil)
-		mockRepo.On("GetChatMessages", mock.Anything, 100).Return([]entity.ChatMessage{}, getErr)
+		mockRepo.On("GetChatMessages", mock.Anything, entity.ChatHistoryFilter{RoomID: 1, AfterID: 7, Limit: usecase.DefaultChatHistoryLimit}).
+			Return([]entity.ChatMessage(nil), nil)
+		mockRepo.On("GetChatMessages", mock.Anything, entity.ChatHistoryFilter{RoomID: 1, BeforeID: 9, Limit: usecase.MaxChatHistoryLimit}).
+			Return([]entity.ChatMessage(nil), nil)
 
-		_, err := uc.GetMessages(context.Background(), 100)
-		assert.Error(t, err)
-		assert.Equal(t, getErr, err)
+		messages, err := uc.GetMessages(context.Background(), entity.ChatHistoryFilter{AfterID: 7})
+		assert.NoError(t, err)
+		assert.NotNil(t, messages)
+		_, err = uc.GetMessages(context.Background(), entity.ChatHistoryFilter{BeforeID: 9, Limit: 10000})
+		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Пустой диапазон", func(t *testing.T) {
+		uc := usecase.NewChatUseCase(new(MockChatRepository), new(mockAuthUC))
+		_, err := uc.GetMessages(context.Background(), entity.ChatHistoryFilter{BeforeID: 5, AfterID: 5})
+		assert.ErrorIs(t, err, usecase.ErrInvalidCursor)
+	})
+}
+
+// TestChatUseCase_ReconnectReplay проверяет, что пропущенные сообщения
+// приходят до живых и без повторов.
+func TestChatUseCase_ReconnectReplay(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	authUC := new(mockAuthUC)
+	uc := usecase.NewChatUseCase(mockRepo, authUC)
+
+	missed := []entity.ChatMessage{
+		{ID: 11, RoomID: 1, Text: "missed 1"},
+		{ID: 12, RoomID: 1, Text: "missed 2"},
+	}
+	mockRepo.On("GetChatMessages", mock.Anything, entity.ChatHistoryFilter{
+		RoomID: 1, AfterID: 10, Limit: usecase.MaxChatHistoryLimit,
+	}).Return(missed, nil)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.ChatMessage).ID = 13
+	}).Return(nil)
+	authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+
+	server := newChatServer(t, uc)
+	conn := dialChatURL(t, server, "alice", "?last_seen_id=10")
+
+	var got entity.ChatMessage
+	for _, want := range []int{11, 12} {
+		readEvent(t, conn, usecase.EventMessage, &got)
+		assert.Equal(t, want, got.ID)
+	}
+
+	sendEvent(t, conn, usecase.EventMessage, map[string]interface{}{"text": "live"})
+	readEvent(t, conn, usecase.EventMessage, &got)
+	assert.Equal(t, 13, got.ID)
+	assert.Equal(t, "live", got.Text)
 }
 
 // TestAuthUseCase тестирует методы аутентификации
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+// ChatBroadcaster carries chat events between running instances. Publish
+// sends an event to the subscribers of every instance, this one included;
+// each instance subscribes its WebSocket hub once at start-up.
+type ChatBroadcaster interface {
+	Publish(ctx context.Context, event entity.ChatEvent) error
+	Subscribe(handler func(entity.ChatEvent))
+}
+
+// MemoryChatBroadcaster delivers events within a single process. It is the
+// default and is enough when only one instance is running.
+type MemoryChatBroadcaster struct {
+	mu       sync.RWMutex
+	handlers []func(entity.ChatEvent)
+}
+
+func NewMemoryChatBroadcaster() *MemoryChatBroadcaster {
+	return &MemoryChatBroadcaster{}
+}
+
+// Publish calls the handlers synchronously, so by the time it returns the
+// event is already in the hub.
+func (b *MemoryChatBroadcaster) Publish(ctx context.Context, event entity.ChatEvent) error {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	for _, handler := range handlers {
+		handler(event)
+	}
+	return nil
+}
+
+func (b *MemoryChatBroadcaster) Subscribe(handler func(entity.ChatEvent)) {
+	b.mu.Lock()
+	b.handlers = append(b.handlers, handler)
+	b.mu.Unlock()
+}
+
+// dispatch hands an event from the broadcaster to the hub's goroutine.
+func (h *WebSocketHub) dispatch(event entity.ChatEvent) {
+	h.events <- event
+}
@@ -0,0 +1,66 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type failingBroadcaster struct{}
+
+func (failingBroadcaster) Publish(ctx context.Context, event entity.ChatEvent) error {
+	return errors.New("broker is down")
+}
+
+func (failingBroadcaster) Subscribe(handler func(entity.ChatEvent)) {}
+
+// TestChatUseCase_SharedBroadcaster проверяет, что сообщение, отправленное
+// через один экземпляр, доходит до клиентов другого.
+func TestChatUseCase_SharedBroadcaster(t *testing.T) {
+	broadcaster := usecase.NewMemoryChatBroadcaster()
+	mockRepo := new(MockChatRepository)
+	authUC := new(mockAuthUC)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.ChatMessage).ID = 5
+	}).Return(nil)
+	mockRepo.On("SaveDirectMessage", mock.Anything, mock.Anything).Return(nil)
+	authUC.On("ParseTokenClaims", "bob").Return(int64(2), "bob", time.Time{}, nil)
+
+	first := usecase.NewChatUseCaseWithOptions(mockRepo, authUC, usecase.ChatOptions{Broadcaster: broadcaster})
+	second := usecase.NewChatUseCaseWithOptions(mockRepo, authUC, usecase.ChatOptions{Broadcaster: broadcaster})
+	bob := dialChat(t, newChatServer(t, second), "bob")
+	// Bob в хабе второго экземпляра, когда получает ответ на пустое сообщение.
+	sendEvent(t, bob, usecase.EventMessage, map[string]interface{}{"text": ""})
+	readEvent(t, bob, usecase.EventError, nil)
+
+	require.NoError(t, first.SendMessage(context.Background(), &entity.ChatMessage{UserID: 1, Author: "alice", Text: "hi all"}))
+	var msg entity.ChatMessage
+	readEvent(t, bob, usecase.EventMessage, &msg)
+	assert.Equal(t, 5, msg.ID)
+	assert.Equal(t, "hi all", msg.Text)
+
+	require.NoError(t, first.SendDirectMessage(context.Background(), &entity.DirectMessage{
+		SenderID: 1, RecipientID: 2, Author: "alice", Text: "psst",
+	}))
+	var dm entity.DirectMessage
+	readEvent(t, bob, usecase.EventDirectMessage, &dm)
+	assert.Equal(t, "psst", dm.Text)
+}
+
+func TestChatUseCase_PublishFailure(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	uc := usecase.NewChatUseCaseWithOptions(mockRepo, new(mockAuthUC), usecase.ChatOptions{Broadcaster: failingBroadcaster{}})
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+
+	// Сообщение сохранено, поэтому клиент не должен получить ошибку.
+	err := uc.SendMessage(context.Background(), &entity.ChatMessage{UserID: 1, Text: "hello"})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
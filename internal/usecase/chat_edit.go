@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+// DefaultChatEditWindow is how long authors may edit or delete their own
+// messages unless ChatOptions says otherwise.
+const DefaultChatEditWindow = 15 * time.Minute
+
+var (
+	ErrEmptyChatMessage = errors.New("message cannot be empty")
+	ErrNotMessageAuthor = errors.New("you can only change your own messages")
+	ErrEditWindowClosed = errors.New("message can no longer be changed")
+)
+
+// EditMessage replaces the text of a room message. Only the author may edit,
+// and only within the edit window. The window is checked by the repository
+// against the database clock, which also fills created_at.
+func (uc *ChatUseCase) EditMessage(ctx context.Context, messageID, userID int, text string) (*entity.ChatMessage, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyChatMessage
+	}
+	msg, err := uc.repo.GetChatMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.UserID != userID {
+		return nil, ErrNotMessageAuthor
+	}
+	if err := uc.checkSanctions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	edited, err := uc.repo.UpdateChatMessageText(ctx, messageID, text, uc.editWindow)
+	if errors.Is(err, ErrNotFound) {
+		// Сообщение только что нашлось — значит, его не пропустило окно правки
+		return nil, ErrEditWindowClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.broadcaster.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventEdited, Message: edited}); err != nil {
+		log.Printf("Error publishing chat message edit: %v", err)
+	}
+	return edited, nil
+}
+
+// DeleteMessage removes a room message. Authors may delete their own messages
+// within the edit window; admins may delete any message at any time.
+func (uc *ChatUseCase) DeleteMessage(ctx context.Context, messageID, userID int) error {
+	msg, err := uc.repo.GetChatMessageByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	deleted := false
+	if msg.UserID == userID {
+		err := uc.repo.DeleteChatMessage(ctx, messageID, uc.editWindow)
+		switch {
+		case err == nil:
+			deleted = true
+		case !errors.Is(err, ErrNotFound):
+			return err
+		}
+	}
+	if !deleted {
+		// Чужое сообщение или окно истекло: удалить может только админ
+		admin, err := uc.isAdmin(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			if msg.UserID != userID {
+				return ErrNotMessageAuthor
+			}
+			return ErrEditWindowClosed
+		}
+		if err := uc.repo.DeleteChatMessage(ctx, messageID, 0); err != nil {
+			return err
+		}
+	}
+	event := entity.ChatEvent{
+		Type:    entity.ChatEventDeleted,
+		Deleted: &entity.DeletedChatMessage{ID: msg.ID, RoomID: msg.RoomID},
+	}
+	if err := uc.broadcaster.Publish(ctx, event); err != nil {
+		log.Printf("Error publishing chat message deletion: %v", err)
+	}
+	return nil
+}
+
+// isAdmin reports whether the user has the admin role. Without a user
+// repository nobody is an admin.
+func (uc *ChatUseCase) isAdmin(ctx context.Context, userID int) (bool, error) {
+	if uc.users == nil {
+		return false, nil
+	}
+	user, err := uc.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.Role == "admin", nil
+}
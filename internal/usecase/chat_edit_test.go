@@ -0,0 +1,140 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatUseCase_EditMessage(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	authUC := new(mockAuthUC)
+	authUC.On("ParseTokenClaims", "bob").Return(int64(2), "bob", time.Time{}, nil)
+	uc := usecase.NewChatUseCaseWithOptions(mockRepo, authUC, usecase.ChatOptions{EditWindow: time.Minute})
+	bob := dialChat(t, newChatServer(t, uc), "bob")
+	waitOnline(t, bob, 2)
+
+	fresh := &entity.ChatMessage{ID: 5, RoomID: entity.DefaultChatRoomID, UserID: 1, Text: "tpyo", CreatedAt: time.Now()}
+	old := &entity.ChatMessage{ID: 6, RoomID: entity.DefaultChatRoomID, UserID: 1, Text: "old", CreatedAt: time.Now().Add(-time.Hour)}
+	mockRepo.On("GetChatMessageByID", mock.Anything, 5).Return(fresh, nil)
+	mockRepo.On("GetChatMessageByID", mock.Anything, 6).Return(old, nil)
+	mockRepo.On("GetChatMessageByID", mock.Anything, 7).Return(nil, usecase.ErrNotFound)
+
+	t.Run("Автор в пределах окна", func(t *testing.T) {
+		editedAt := time.Now()
+		mockRepo.On("UpdateChatMessageText", mock.Anything, 5, "typo", time.Minute).
+			Return(&entity.ChatMessage{ID: 5, RoomID: entity.DefaultChatRoomID, UserID: 1, Text: "typo", EditedAt: &editedAt}, nil).Once()
+
+		msg, err := uc.EditMessage(context.Background(), 5, 1, "typo")
+		require.NoError(t, err)
+		assert.Equal(t, "typo", msg.Text)
+
+		var edited entity.ChatMessage
+		readEvent(t, bob, usecase.EventMessageEdited, &edited)
+		assert.Equal(t, 5, edited.ID)
+		assert.Equal(t, "typo", edited.Text)
+		assert.NotNil(t, edited.EditedAt)
+	})
+
+	t.Run("Чужое сообщение", func(t *testing.T) {
+		_, err := uc.EditMessage(context.Background(), 5, 2, "mine now")
+		assert.ErrorIs(t, err, usecase.ErrNotMessageAuthor)
+	})
+
+	t.Run("Окно истекло", func(t *testing.T) {
+		// Окно проверяет база: для старого сообщения UPDATE ничего не находит
+		mockRepo.On("UpdateChatMessageText", mock.Anything, 6, "too late", time.Minute).Return(nil, usecase.ErrNotFound).Once()
+		_, err := uc.EditMessage(context.Background(), 6, 1, "too late")
+		assert.ErrorIs(t, err, usecase.ErrEditWindowClosed)
+	})
+
+	t.Run("Пустой текст", func(t *testing.T) {
+		_, err := uc.EditMessage(context.Background(), 5, 1, "  ")
+		assert.ErrorIs(t, err, usecase.ErrEmptyChatMessage)
+	})
+
+	t.Run("Нет сообщения", func(t *testing.T) {
+		_, err := uc.EditMessage(context.Background(), 7, 1, "text")
+		assert.ErrorIs(t, err, usecase.ErrNotFound)
+	})
+
+	mockRepo.AssertNumberOfCalls(t, "UpdateChatMessageText", 2)
+}
+
+func TestChatUseCase_DeleteMessage(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	mockUserRepo := new(MockUserRepository)
+	authUC := new(mockAuthUC)
+	authUC.On("ParseTokenClaims", "bob").Return(int64(2), "bob", time.Time{}, nil)
+	uc := usecase.NewChatUseCaseWithOptions(mockRepo, authUC, usecase.ChatOptions{
+		EditWindow: time.Minute,
+		Users:      mockUserRepo,
+	})
+	bob := dialChat(t, newChatServer(t, uc), "bob")
+	waitOnline(t, bob, 2)
+
+	old := &entity.ChatMessage{ID: 6, RoomID: entity.DefaultChatRoomID, UserID: 1, CreatedAt: time.Now().Add(-time.Hour)}
+	mockRepo.On("GetChatMessageByID", mock.Anything, 5).
+		Return(&entity.ChatMessage{ID: 5, RoomID: entity.DefaultChatRoomID, UserID: 1, CreatedAt: time.Now()}, nil)
+	mockRepo.On("GetChatMessageByID", mock.Anything, 6).Return(old, nil)
+	mockRepo.On("DeleteChatMessage", mock.Anything, 5, time.Minute).Return(nil)
+	mockRepo.On("DeleteChatMessage", mock.Anything, 6, time.Minute).Return(usecase.ErrNotFound)
+	mockRepo.On("DeleteChatMessage", mock.Anything, 6, time.Duration(0)).Return(nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, 1).Return(&entity.User{ID: 1, Role: "user"}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, 2).Return(&entity.User{ID: 2, Role: "user"}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, 3).Return(&entity.User{ID: 3, Role: "admin"}, nil)
+
+	t.Run("Автор в пределах окна", func(t *testing.T) {
+		require.NoError(t, uc.DeleteMessage(context.Background(), 5, 1))
+		var deleted entity.DeletedChatMessage
+		readEvent(t, bob, usecase.EventMessageDeleted, &deleted)
+		assert.Equal(t, entity.DeletedChatMessage{ID: 5, RoomID: entity.DefaultChatRoomID}, deleted)
+	})
+
+	t.Run("Чужое сообщение", func(t *testing.T) {
+		assert.ErrorIs(t, uc.DeleteMessage(context.Background(), 5, 2), usecase.ErrNotMessageAuthor)
+	})
+
+	t.Run("Автор после окна", func(t *testing.T) {
+		assert.ErrorIs(t, uc.DeleteMessage(context.Background(), 6, 1), usecase.ErrEditWindowClosed)
+	})
+
+	t.Run("Администратор удаляет любое", func(t *testing.T) {
+		require.NoError(t, uc.DeleteMessage(context.Background(), 6, 3))
+		var deleted entity.DeletedChatMessage
+		readEvent(t, bob, usecase.EventMessageDeleted, &deleted)
+		assert.Equal(t, 6, deleted.ID)
+	})
+
+	// Автор после окна упирается в проверку базы, админ удаляет без окна
+	mockRepo.AssertNumberOfCalls(t, "DeleteChatMessage", 3)
+}
+
+func TestChatProtocol_EditOverWebSocket(t *testing.T) {
+	_, mockRepo, dial := newProtocolServer(t)
+	mockRepo.On("GetChatMessageByID", mock.Anything, 5).
+		Return(&entity.ChatMessage{ID: 5, RoomID: entity.DefaultChatRoomID, UserID: 2, CreatedAt: time.Now()}, nil)
+	mockRepo.On("UpdateChatMessageText", mock.Anything, 5, "fixed", usecase.DefaultChatEditWindow).
+		Return(&entity.ChatMessage{ID: 5, RoomID: entity.DefaultChatRoomID, UserID: 2, Text: "fixed"}, nil)
+
+	alice := dial("alice")
+	require.NoError(t, alice.WriteJSON(map[string]interface{}{
+		"v": 1, "type": "message_edited", "id": "e1", "data": map[string]interface{}{"message_id": 5, "text": "fixed"},
+	}))
+	var data usecase.ErrorData
+	env := readEvent(t, alice, usecase.EventError, &data)
+	assert.Equal(t, "e1", env.ID)
+	assert.Equal(t, usecase.ErrNotMessageAuthor.Error(), data.Message)
+
+	bob := dial("bob")
+	sendEvent(t, bob, usecase.EventMessageEdited, map[string]interface{}{"message_id": 5, "text": "fixed"})
+	var edited entity.ChatMessage
+	readEvent(t, alice, usecase.EventMessageEdited, &edited)
+	assert.Equal(t, "fixed", edited.Text)
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChatRetention decides how long room messages are kept. A zero duration
+// means the messages are kept forever.
+type ChatRetention struct {
+	Default time.Duration
+	Rooms   map[int]time.Duration // переопределяет Default для отдельных комнат
+}
+
+// Enabled reports whether any room messages can ever expire.
+func (r ChatRetention) Enabled() bool {
+	if r.Default > 0 {
+		return true
+	}
+	for _, keep := range r.Rooms {
+		if keep > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type ChatRetentionRepository interface {
+	DeleteChatMessagesOlderThan(ctx context.Context, age time.Duration, exceptRooms []int) (int64, error)
+	DeleteRoomChatMessagesOlderThan(ctx context.Context, roomID int, age time.Duration) (int64, error)
+}
+
+// ChatJanitorStats is a snapshot of the janitor's counters, published as
+// metrics.
+type ChatJanitorStats struct {
+	Runs           int64     `json:"runs"`
+	Failures       int64     `json:"failures"`
+	DeletedTotal   int64     `json:"deleted_total"`
+	LastDeleted    int64     `json:"last_deleted"`
+	LastRunAt      time.Time `json:"last_run_at"`
+	LastDurationMs int64     `json:"last_duration_ms"`
+	LastError      string    `json:"last_error,omitempty"`
+}
+
+// ChatJanitor deletes room messages that have outlived their retention. It
+// runs in the background so reads never pay for cleanup.
+type ChatJanitor struct {
+	repo      ChatRetentionRepository
+	retention ChatRetention
+
+	mu    sync.Mutex
+	stats ChatJanitorStats
+}
+
+func NewChatJanitor(repo ChatRetentionRepository, retention ChatRetention) *ChatJanitor {
+	return &ChatJanitor{repo: repo, retention: retention}
+}
+
+// RunOnce performs a single cleanup pass and returns the number of deleted
+// messages.
+func (j *ChatJanitor) RunOnce(ctx context.Context) (int64, error) {
+	start := time.Now()
+	deleted, err := j.cleanup(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stats.Runs++
+	j.stats.DeletedTotal += deleted
+	j.stats.LastDeleted = deleted
+	j.stats.LastRunAt = start
+	j.stats.LastDurationMs = time.Since(start).Milliseconds()
+	j.stats.LastError = ""
+	if err != nil {
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+	}
+	return deleted, err
+}
+
+// cleanup passes retention periods rather than cutoff times: created_at is
+// filled by the database clock, so the cutoff is computed there too.
+func (j *ChatJanitor) cleanup(ctx context.Context) (int64, error) {
+	var total int64
+	overrides := make([]int, 0, len(j.retention.Rooms))
+	for roomID, keep := range j.retention.Rooms {
+		overrides = append(overrides, roomID)
+		if keep <= 0 {
+			continue // эта комната хранит историю вечно
+		}
+		n, err := j.repo.DeleteRoomChatMessagesOlderThan(ctx, roomID, keep)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+
+	if j.retention.Default > 0 {
+		sort.Ints(overrides)
+		n, err := j.repo.DeleteChatMessagesOlderThan(ctx, j.retention.Default, overrides)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Stats returns a copy of the current counters.
+func (j *ChatJanitor) Stats() ChatJanitorStats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats
+}
+
+// Start runs RunOnce every interval until ctx is cancelled. Nothing is
+// started when every room keeps its messages forever.
+func (j *ChatJanitor) Start(ctx context.Context, interval time.Duration) {
+	if !j.retention.Enabled() || interval <= 0 {
+		log.Printf("Chat janitor disabled: chat messages are kept forever")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := j.RunOnce(ctx)
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					log.Printf("Error cleaning chat messages: %v", err)
+					continue
+				}
+				if deleted > 0 {
+					log.Printf("Deleted %d expired chat messages", deleted)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockChatRetentionRepository struct {
+	mock.Mock
+}
+
+func (m *MockChatRetentionRepository) DeleteChatMessagesOlderThan(ctx context.Context, age time.Duration, exceptRooms []int) (int64, error) {
+	args := m.Called(ctx, age, exceptRooms)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockChatRetentionRepository) DeleteRoomChatMessagesOlderThan(ctx context.Context, roomID int, age time.Duration) (int64, error) {
+	args := m.Called(ctx, roomID, age)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestChatJanitor_RunOnce(t *testing.T) {
+	t.Run("default with room overrides", func(t *testing.T) {
+		repo := new(MockChatRetentionRepository)
+		janitor := usecase.NewChatJanitor(repo, usecase.ChatRetention{
+			Default: time.Hour,
+			Rooms:   map[int]time.Duration{2: 24 * time.Hour, 3: 0},
+		})
+
+		repo.On("DeleteRoomChatMessagesOlderThan", mock.Anything, 2, 24*time.Hour).Return(int64(4), nil)
+		repo.On("DeleteChatMessagesOlderThan", mock.Anything, time.Hour, []int{2, 3}).Return(int64(6), nil)
+
+		deleted, err := janitor.RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), deleted)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "DeleteRoomChatMessagesOlderThan", mock.Anything, 3, mock.Anything)
+
+		stats := janitor.Stats()
+		assert.Equal(t, int64(1), stats.Runs)
+		assert.Equal(t, int64(10), stats.DeletedTotal)
+		assert.Zero(t, stats.Failures)
+	})
+
+	t.Run("keep forever by default", func(t *testing.T) {
+		repo := new(MockChatRetentionRepository)
+		janitor := usecase.NewChatJanitor(repo, usecase.ChatRetention{
+			Rooms: map[int]time.Duration{2: time.Hour},
+		})
+
+		repo.On("DeleteRoomChatMessagesOlderThan", mock.Anything, 2, time.Hour).Return(int64(1), nil)
+
+		_, err := janitor.RunOnce(context.Background())
+		assert.NoError(t, err)
+		repo.AssertNotCalled(t, "DeleteChatMessagesOlderThan", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failure is counted", func(t *testing.T) {
+		repo := new(MockChatRetentionRepository)
+		janitor := usecase.NewChatJanitor(repo, usecase.ChatRetention{Default: time.Hour})
+
+		repo.On("DeleteChatMessagesOlderThan", mock.Anything, mock.Anything, []int{}).Return(int64(0), errors.New("db down"))
+
+		_, err := janitor.RunOnce(context.Background())
+		assert.Error(t, err)
+		stats := janitor.Stats()
+		assert.Equal(t, int64(1), stats.Failures)
+		assert.Equal(t, "db down", stats.LastError)
+	})
+}
+
+func TestChatRetention_Enabled(t *testing.T) {
+	assert.False(t, usecase.ChatRetention{}.Enabled())
+	assert.False(t, usecase.ChatRetention{Rooms: map[int]time.Duration{2: 0}}.Enabled())
+	assert.True(t, usecase.ChatRetention{Rooms: map[int]time.Duration{2: time.Hour}}.Enabled())
+	assert.True(t, usecase.ChatRetention{Default: time.Minute}.Enabled())
+}
+
+func TestChatJanitor_StartStopsOnCancel(t *testing.T) {
+	repo := new(MockChatRetentionRepository)
+	janitor := usecase.NewChatJanitor(repo, usecase.ChatRetention{Default: time.Hour})
+	repo.On("DeleteChatMessagesOlderThan", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	janitor.Start(ctx, 5*time.Millisecond)
+	assert.Eventually(t, func() bool { return janitor.Stats().Runs > 0 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	runs := janitor.Stats().Runs
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, runs, janitor.Stats().Runs, "janitor must stop after cancel")
+}
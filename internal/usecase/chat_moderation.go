@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+const (
+	DefaultChatFloodMessages = 10
+	DefaultChatFloodInterval = 10 * time.Second
+
+	maxSlowMode = time.Hour
+)
+
+var (
+	ErrChatMuted       = errors.New("you are muted in chat")
+	ErrChatBanned      = errors.New("you are banned from chat")
+	ErrSlowMode        = errors.New("slow mode is on in this room")
+	ErrFloodLimit      = errors.New("too many messages, slow down")
+	ErrInvalidSanction = errors.New("invalid sanction")
+)
+
+// ChatModerationRepository stores sanctions and slow mode; see
+// repository.ChatModerationRepository.
+type ChatModerationRepository interface {
+	SetChatSanction(ctx context.Context, sanction *entity.ChatSanction, duration time.Duration) error
+	DeleteChatSanction(ctx context.Context, userID int, kind string) error
+	GetChatSanctions(ctx context.Context) ([]entity.ChatSanction, error)
+	GetUserChatSanctions(ctx context.Context, userID int) ([]entity.ChatSanction, error)
+	SetChatRoomSlowMode(ctx context.Context, roomID, seconds int) error
+	ChatSlowModeWait(ctx context.Context, roomID, userID int) (time.Duration, error)
+}
+
+// ChatModerationUseCaseInterface is what the admin endpoints need.
+type ChatModerationUseCaseInterface interface {
+	ListSanctions(ctx context.Context, adminID int) ([]entity.ChatSanction, error)
+	Sanction(ctx context.Context, adminID int, sanction *entity.ChatSanction, duration time.Duration) error
+	LiftSanction(ctx context.Context, adminID, userID int, kind string) error
+	SetSlowMode(ctx context.Context, adminID, roomID int, interval time.Duration) error
+}
+
+// FloodLimit caps how many messages, room and direct together, one user may
+// send per Interval. It is counted per instance.
+type FloodLimit struct {
+	Messages int
+	Interval time.Duration
+}
+
+func (l FloodLimit) withDefaults() FloodLimit {
+	if l.Messages <= 0 {
+		l.Messages = DefaultChatFloodMessages
+	}
+	if l.Interval <= 0 {
+		l.Interval = DefaultChatFloodInterval
+	}
+	return l
+}
+
+// floodLimiter keeps the send times of each user within the last interval.
+type floodLimiter struct {
+	limit  FloodLimit
+	mu     sync.Mutex
+	recent map[int][]time.Time
+}
+
+func newFloodLimiter(limit FloodLimit) *floodLimiter {
+	return &floodLimiter{limit: limit, recent: make(map[int][]time.Time)}
+}
+
+// allow records a message from the user unless they are over the limit.
+func (l *floodLimiter) allow(userID int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	times := l.recent[userID]
+	cutoff := now.Add(-l.limit.Interval)
+	for len(times) > 0 && !times[0].After(cutoff) {
+		times = times[1:]
+	}
+	if len(times) >= l.limit.Messages {
+		l.recent[userID] = times
+		return false
+	}
+	l.recent[userID] = append(times, now)
+	return true
+}
+
+// checkSanctions returns ErrChatBanned or ErrChatMuted if the user may not
+// post right now.
+func (uc *ChatUseCase) checkSanctions(ctx context.Context, userID int) error {
+	if uc.moderation == nil {
+		return nil
+	}
+	sanctions, err := uc.moderation.GetUserChatSanctions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var muted *entity.ChatSanction
+	for i := range sanctions {
+		switch sanctions[i].Kind {
+		case entity.ChatSanctionBan:
+			return sanctionError(ErrChatBanned, sanctions[i])
+		case entity.ChatSanctionMute:
+			muted = &sanctions[i]
+		}
+	}
+	if muted != nil {
+		return sanctionError(ErrChatMuted, *muted)
+	}
+	return nil
+}
+
+func sanctionError(err error, sanction entity.ChatSanction) error {
+	if sanction.ExpiresAt != nil {
+		return fmt.Errorf("%w until %s", err, sanction.ExpiresAt.Format(time.RFC3339))
+	}
+	return err
+}
+
+// checkCanPost runs the checks every new message goes through: sanctions
+// and the flood limit.
+func (uc *ChatUseCase) checkCanPost(ctx context.Context, userID int) error {
+	if err := uc.checkSanctions(ctx, userID); err != nil {
+		return err
+	}
+	if !uc.flood.allow(userID, time.Now()) {
+		return ErrFloodLimit
+	}
+	return nil
+}
+
+func (uc *ChatUseCase) checkSlowMode(ctx context.Context, roomID, userID int) error {
+	if uc.moderation == nil {
+		return nil
+	}
+	wait, err := uc.moderation.ChatSlowModeWait(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return fmt.Errorf("%w: wait %s", ErrSlowMode, wait.Round(time.Second))
+	}
+	return nil
+}
+
+// isBanned is checked when a socket connects. A failed lookup lets the user
+// in: every message they send is checked again anyway.
+func (uc *ChatUseCase) isBanned(userID int) bool {
+	err := uc.checkSanctions(context.Background(), userID)
+	if err != nil && !errors.Is(err, ErrChatBanned) && !errors.Is(err, ErrChatMuted) {
+		log.Printf("Error checking chat sanctions: %v", err)
+	}
+	return errors.Is(err, ErrChatBanned)
+}
+
+func (uc *ChatUseCase) requireChatAdmin(ctx context.Context, userID int) error {
+	admin, err := uc.isAdmin(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !admin || uc.moderation == nil {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// ListSanctions returns the mutes and bans that are in force.
+func (uc *ChatUseCase) ListSanctions(ctx context.Context, adminID int) ([]entity.ChatSanction, error) {
+	if err := uc.requireChatAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+	sanctions, err := uc.moderation.GetChatSanctions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if sanctions == nil {
+		sanctions = []entity.ChatSanction{}
+	}
+	return sanctions, nil
+}
+
+// Sanction mutes or bans a user for duration, or until lifted when it is
+// zero. A banned user's open sockets are closed right away.
+func (uc *ChatUseCase) Sanction(ctx context.Context, adminID int, sanction *entity.ChatSanction, duration time.Duration) error {
+	if err := uc.requireChatAdmin(ctx, adminID); err != nil {
+		return err
+	}
+	if sanction.Kind != entity.ChatSanctionMute && sanction.Kind != entity.ChatSanctionBan {
+		return fmt.Errorf("%w: kind must be %q or %q", ErrInvalidSanction, entity.ChatSanctionMute, entity.ChatSanctionBan)
+	}
+	if sanction.UserID <= 0 || sanction.UserID == adminID {
+		return fmt.Errorf("%w: user must be another user", ErrInvalidSanction)
+	}
+	if duration < 0 {
+		return fmt.Errorf("%w: duration cannot be negative", ErrInvalidSanction)
+	}
+
+	sanction.CreatedBy = adminID
+	if err := uc.moderation.SetChatSanction(ctx, sanction, duration); err != nil {
+		return err
+	}
+	if err := uc.broadcaster.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventSanction, Sanction: sanction}); err != nil {
+		log.Printf("Error publishing chat sanction: %v", err)
+	}
+	return nil
+}
+
+func (uc *ChatUseCase) LiftSanction(ctx context.Context, adminID, userID int, kind string) error {
+	if err := uc.requireChatAdmin(ctx, adminID); err != nil {
+		return err
+	}
+	if kind != entity.ChatSanctionMute && kind != entity.ChatSanctionBan {
+		return fmt.Errorf("%w: kind must be %q or %q", ErrInvalidSanction, entity.ChatSanctionMute, entity.ChatSanctionBan)
+	}
+	return uc.moderation.DeleteChatSanction(ctx, userID, kind)
+}
+
+// SetSlowMode sets the minimum interval between two messages of one user in
+// a room; zero turns slow mode off.
+func (uc *ChatUseCase) SetSlowMode(ctx context.Context, adminID, roomID int, interval time.Duration) error {
+	if err := uc.requireChatAdmin(ctx, adminID); err != nil {
+		return err
+	}
+	if roomID <= 0 {
+		return fmt.Errorf("%w: room ID is required", ErrInvalidChatRoom)
+	}
+	if interval < 0 || interval > maxSlowMode {
+		return fmt.Errorf("%w: slow mode must be between 0 and %s", ErrInvalidChatRoom, maxSlowMode)
+	}
+	return uc.moderation.SetChatRoomSlowMode(ctx, roomID, int(interval/time.Second))
+}
+
+// applySanction tells the sanctioned user's local connections about it;
+// a ban closes them.
+func (h *WebSocketHub) applySanction(sanction entity.ChatSanction) {
+	for client := range h.users[sanction.UserID] {
+		if sanction.Kind == entity.ChatSanctionBan {
+			client.close(websocket.ClosePolicyViolation, "banned")
+			h.removeClient(client)
+			continue
+		}
+		h.deliver(client, newEnvelope(EventSystem, "", SystemData{
+			Code:    "muted",
+			Message: sanctionError(ErrChatMuted, sanction).Error(),
+		}))
+	}
+}
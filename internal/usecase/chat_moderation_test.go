@@ -0,0 +1,175 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockChatModerationRepository struct {
+	mock.Mock
+}
+
+func (m *MockChatModerationRepository) SetChatSanction(ctx context.Context, sanction *entity.ChatSanction, duration time.Duration) error {
+	args := m.Called(ctx, sanction, duration)
+	return args.Error(0)
+}
+
+func (m *MockChatModerationRepository) DeleteChatSanction(ctx context.Context, userID int, kind string) error {
+	args := m.Called(ctx, userID, kind)
+	return args.Error(0)
+}
+
+func (m *MockChatModerationRepository) GetChatSanctions(ctx context.Context) ([]entity.ChatSanction, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entity.ChatSanction), args.Error(1)
+}
+
+func (m *MockChatModerationRepository) GetUserChatSanctions(ctx context.Context, userID int) ([]entity.ChatSanction, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.ChatSanction), args.Error(1)
+}
+
+func (m *MockChatModerationRepository) SetChatRoomSlowMode(ctx context.Context, roomID, seconds int) error {
+	args := m.Called(ctx, roomID, seconds)
+	return args.Error(0)
+}
+
+func (m *MockChatModerationRepository) ChatSlowModeWait(ctx context.Context, roomID, userID int) (time.Duration, error) {
+	args := m.Called(ctx, roomID, userID)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func newModeratedChat(t *testing.T) (*usecase.ChatUseCase, *MockChatRepository, *MockChatModerationRepository) {
+	mockRepo := new(MockChatRepository)
+	modRepo := new(MockChatModerationRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetUserByID", mock.Anything, 1).Return(&entity.User{ID: 1, Role: "admin"}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, 2).Return(&entity.User{ID: 2, Role: "user"}, nil)
+	authUC := new(mockAuthUC)
+	authUC.On("ParseTokenClaims", "bob").Return(int64(2), "bob", time.Time{}, nil)
+	authUC.On("ParseTokenClaims", "carol").Return(int64(3), "carol", time.Time{}, nil)
+	uc := usecase.NewChatUseCaseWithOptions(mockRepo, authUC, usecase.ChatOptions{
+		Users:      mockUserRepo,
+		Moderation: modRepo,
+		Flood:      usecase.FloodLimit{Messages: 3, Interval: time.Minute},
+	})
+	return uc, mockRepo, modRepo
+}
+
+func TestChatUseCase_Sanctions(t *testing.T) {
+	uc, mockRepo, modRepo := newModeratedChat(t)
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+	modRepo.On("GetUserChatSanctions", mock.Anything, 2).
+		Return([]entity.ChatSanction{{UserID: 2, Kind: entity.ChatSanctionMute, ExpiresAt: &expires}}, nil)
+	modRepo.On("GetUserChatSanctions", mock.Anything, 3).
+		Return([]entity.ChatSanction{{UserID: 3, Kind: entity.ChatSanctionMute}, {UserID: 3, Kind: entity.ChatSanctionBan}}, nil)
+
+	t.Run("Заглушённый не пишет ни в комнату, ни в личку", func(t *testing.T) {
+		err := uc.SendMessage(ctx, &entity.ChatMessage{UserID: 2, Text: "hi"})
+		assert.ErrorIs(t, err, usecase.ErrChatMuted)
+		err = uc.SendDirectMessage(ctx, &entity.DirectMessage{SenderID: 2, RecipientID: 1, Text: "hi"})
+		assert.ErrorIs(t, err, usecase.ErrChatMuted)
+		mockRepo.AssertNotCalled(t, "SaveChatMessage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Бан важнее мьюта", func(t *testing.T) {
+		err := uc.SendMessage(ctx, &entity.ChatMessage{UserID: 3, Text: "hi"})
+		assert.ErrorIs(t, err, usecase.ErrChatBanned)
+	})
+
+	t.Run("Забаненный не подключается", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+newChatServer(t, uc).URL[4:], map[string][]string{
+			"Authorization": {"Bearer carol"},
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+		err = readUntilClosed(conn)
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+	})
+
+	t.Run("Заглушённый по WebSocket получает ошибку", func(t *testing.T) {
+		bob := dialChat(t, newChatServer(t, uc), "bob")
+		sendEvent(t, bob, usecase.EventMessage, map[string]interface{}{"text": "hi"})
+		var data usecase.ErrorData
+		readEvent(t, bob, usecase.EventError, &data)
+		assert.Contains(t, data.Message, usecase.ErrChatMuted.Error())
+	})
+}
+
+func TestChatUseCase_BanDisconnects(t *testing.T) {
+	uc, _, modRepo := newModeratedChat(t)
+	modRepo.On("GetUserChatSanctions", mock.Anything, 2).Return([]entity.ChatSanction{}, nil).Once()
+	modRepo.On("SetChatSanction", mock.Anything, mock.Anything, time.Duration(0)).Return(nil)
+
+	bob := dialChat(t, newChatServer(t, uc), "bob")
+	waitOnline(t, bob, 2)
+
+	require.NoError(t, uc.Sanction(context.Background(), 1, &entity.ChatSanction{UserID: 2, Kind: entity.ChatSanctionBan}, 0))
+	err := readUntilClosed(bob)
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+}
+
+func TestChatUseCase_SlowModeAndFlood(t *testing.T) {
+	uc, mockRepo, modRepo := newModeratedChat(t)
+	ctx := context.Background()
+	modRepo.On("GetUserChatSanctions", mock.Anything, mock.Anything).Return([]entity.ChatSanction{}, nil)
+	modRepo.On("ChatSlowModeWait", mock.Anything, entity.DefaultChatRoomID, 2).Return(30*time.Second, nil).Once()
+	modRepo.On("ChatSlowModeWait", mock.Anything, entity.DefaultChatRoomID, mock.Anything).Return(time.Duration(0), nil)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SaveDirectMessage", mock.Anything, mock.Anything).Return(nil)
+
+	err := uc.SendMessage(ctx, &entity.ChatMessage{UserID: 2, Text: "one"})
+	assert.ErrorIs(t, err, usecase.ErrSlowMode)
+	assert.Contains(t, err.Error(), "30s")
+
+	// Лимит — три сообщения в минуту, и личные считаются вместе с общими;
+	// попытка, отбитая медленным режимом, тоже.
+	assert.NoError(t, uc.SendMessage(ctx, &entity.ChatMessage{UserID: 2, Text: "two"}))
+	assert.NoError(t, uc.SendDirectMessage(ctx, &entity.DirectMessage{SenderID: 2, RecipientID: 1, Text: "three"}))
+	assert.ErrorIs(t, uc.SendMessage(ctx, &entity.ChatMessage{UserID: 2, Text: "four"}), usecase.ErrFloodLimit)
+
+	// У другого пользователя свой счётчик.
+	assert.NoError(t, uc.SendMessage(ctx, &entity.ChatMessage{UserID: 1, Text: "admin"}))
+}
+
+func TestChatUseCase_ModerationAdmin(t *testing.T) {
+	uc, _, modRepo := newModeratedChat(t)
+	ctx := context.Background()
+
+	t.Run("Только администратор", func(t *testing.T) {
+		_, err := uc.ListSanctions(ctx, 2)
+		assert.ErrorIs(t, err, usecase.ErrForbidden)
+		assert.ErrorIs(t, uc.SetSlowMode(ctx, 2, 1, time.Minute), usecase.ErrForbidden)
+	})
+
+	t.Run("Неверная санкция", func(t *testing.T) {
+		err := uc.Sanction(ctx, 1, &entity.ChatSanction{UserID: 2, Kind: "kick"}, 0)
+		assert.ErrorIs(t, err, usecase.ErrInvalidSanction)
+		err = uc.Sanction(ctx, 1, &entity.ChatSanction{UserID: 1, Kind: entity.ChatSanctionMute}, 0)
+		assert.ErrorIs(t, err, usecase.ErrInvalidSanction)
+	})
+
+	t.Run("Мьют на час", func(t *testing.T) {
+		modRepo.On("SetChatSanction", mock.Anything, mock.MatchedBy(func(s *entity.ChatSanction) bool {
+			return s.UserID == 2 && s.CreatedBy == 1
+		}), time.Hour).Return(nil).Once()
+		assert.NoError(t, uc.Sanction(ctx, 1, &entity.ChatSanction{UserID: 2, Kind: entity.ChatSanctionMute}, time.Hour))
+	})
+
+	t.Run("Медленный режим", func(t *testing.T) {
+		modRepo.On("SetChatRoomSlowMode", mock.Anything, 4, 30).Return(nil).Once()
+		assert.NoError(t, uc.SetSlowMode(ctx, 1, 4, 30*time.Second))
+		assert.ErrorIs(t, uc.SetSlowMode(ctx, 1, 4, 2*time.Hour), usecase.ErrInvalidChatRoom)
+	})
+
+	modRepo.AssertExpectations(t)
+}
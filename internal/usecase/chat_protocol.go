@@ -0,0 +1,424 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+// ChatProtocolVersion is the version of the WebSocket envelope. Clients may
+// leave "v" out; frames with any other version are rejected.
+const ChatProtocolVersion = 1
+
+// Event types of the chat WebSocket protocol.
+const (
+	EventMessage       = "message"
+	EventDirectMessage = "direct_message"
+	EventError         = "error"
+	EventTyping        = "typing"
+	EventPresence      = "presence"
+	EventAck           = "ack"
+	EventSystem        = "system"
+	// EventMessageEdited and EventMessageDeleted carry changes to room
+	// messages; clients send them with a message_id to make the change.
+	EventMessageEdited  = "message_edited"
+	EventMessageDeleted = "message_deleted"
+	// EventPostCreated and EventCommentCreated are forum activity; they only
+	// go to event streams that follow the posts and comments feeds.
+	EventPostCreated    = "post_created"
+	EventCommentCreated = "comment_created"
+	// EventCommentEdited and EventCommentDeleted, like EventCommentCreated,
+	// also go to clients watching the post.
+	EventCommentEdited  = "comment_edited"
+	EventCommentDeleted = "comment_deleted"
+	// EventJoin and EventLeave are only sent by clients, as are
+	// EventWatchPost and EventUnwatchPost with a post_id.
+	EventJoin        = "join"
+	EventLeave       = "leave"
+	EventWatchPost   = "watch_post"
+	EventUnwatchPost = "unwatch_post"
+)
+
+// typingThrottle is how often one connection may report typing in the same
+// room or conversation; more frequent reports are acknowledged and dropped.
+const typingThrottle = 2 * time.Second
+
+var ErrInvalidTyping = errors.New("typing needs either a room or a recipient")
+
+// Envelope is a frame of the chat WebSocket protocol, in either direction.
+// ID is chosen by the client and echoed in the ack or error for that frame,
+// so the client can match them up.
+type Envelope struct {
+	V    int             `json:"v"`
+	Type string          `json:"type"`
+	ID   string          `json:"id,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+
+	// replayID — ID сообщения общей комнаты; по нему writePump отсеивает
+	// то, что уже ушло при повторе истории.
+	replayID int
+}
+
+// ErrorData is the payload of an error event.
+type ErrorData struct {
+	Message string `json:"message"`
+}
+
+// AckData is the payload of an ack; MessageID is set for sent messages.
+type AckData struct {
+	MessageID int `json:"message_id,omitempty"`
+}
+
+// SystemData is the payload of a system event, e.g. "history_truncated"
+// when a reconnecting client missed more than the server will replay.
+type SystemData struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	After   int    `json:"after,omitempty"`
+}
+
+// PresenceUser is one entry of a presence event.
+type PresenceUser struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Online   bool   `json:"online"`
+}
+
+// PresenceData is the payload of a presence event: every online user right
+// after connecting or on request, and a single user when they come or go.
+type PresenceData struct {
+	Users []PresenceUser `json:"users"`
+}
+
+// chatCommand is the data of a client frame; which fields matter depends on
+// the envelope type.
+type chatCommand struct {
+	RoomID    int    `json:"room_id"`
+	To        int    `json:"to"`
+	MessageID int    `json:"message_id"`
+	PostID    int    `json:"post_id"`
+	Text      string `json:"text"`
+}
+
+func newEnvelope(eventType, id string, data interface{}) Envelope {
+	env := Envelope{V: ChatProtocolVersion, Type: eventType, ID: id}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			log.Printf("Error encoding %s event: %v", eventType, err)
+		}
+		env.Data = raw
+	}
+	return env
+}
+
+func errorEnvelope(id, message string) Envelope {
+	return newEnvelope(EventError, id, ErrorData{Message: message})
+}
+
+// publicError passes errors the client can act on through and replaces
+// everything else with fallback, logging the original.
+func publicError(err error, fallback string) error {
+	for _, known := range []error{
+		ErrNotRoomMember, ErrInvalidDirectMessage, ErrInvalidChatRoom, ErrInvalidTyping, ErrNotFound,
+		ErrEmptyChatMessage, ErrNotMessageAuthor, ErrEditWindowClosed,
+		ErrChatMuted, ErrChatBanned, ErrSlowMode, ErrFloodLimit, ErrInvalidPostID,
+	} {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+	log.Printf("%s: %v", fallback, err)
+	return errors.New(fallback)
+}
+
+// handleFrame runs one client frame and answers with an ack, if the client
+// gave the frame an ID, or with an error.
+func (c *WebSocketClient) handleFrame(ctx context.Context, uc *ChatUseCase, env Envelope) {
+	if env.V != 0 && env.V != ChatProtocolVersion {
+		c.reply(errorEnvelope(env.ID, fmt.Sprintf("unsupported protocol version %d", env.V)))
+		return
+	}
+	var cmd chatCommand
+	if len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, &cmd); err != nil {
+			c.reply(errorEnvelope(env.ID, "invalid event data"))
+			return
+		}
+	}
+
+	ack, err := c.runCommand(ctx, uc, env.Type, cmd)
+	if err != nil {
+		c.reply(errorEnvelope(env.ID, err.Error()))
+		return
+	}
+	if env.ID != "" {
+		c.reply(newEnvelope(EventAck, env.ID, ack))
+	}
+}
+
+func (c *WebSocketClient) runCommand(ctx context.Context, uc *ChatUseCase, eventType string, cmd chatCommand) (*AckData, error) {
+	userID, username := c.identity.UserID, c.identity.Username
+	switch eventType {
+	case EventMessage:
+		if strings.TrimSpace(cmd.Text) == "" {
+			return nil, errors.New("message cannot be empty")
+		}
+		msg := entity.ChatMessage{
+			RoomID:    cmd.RoomID,
+			UserID:    userID,
+			Author:    username,
+			Text:      cmd.Text,
+			CreatedAt: time.Now(),
+		}
+		if err := uc.SendMessage(ctx, &msg); err != nil {
+			return nil, publicError(err, "failed to save message")
+		}
+		return &AckData{MessageID: msg.ID}, nil
+	case EventDirectMessage:
+		dm := entity.DirectMessage{
+			SenderID:    userID,
+			RecipientID: cmd.To,
+			Author:      username,
+			Text:        cmd.Text,
+		}
+		if err := uc.SendDirectMessage(ctx, &dm); err != nil {
+			return nil, publicError(err, "failed to save message")
+		}
+		return &AckData{MessageID: dm.ID}, nil
+	case EventMessageEdited:
+		msg, err := uc.EditMessage(ctx, cmd.MessageID, userID, cmd.Text)
+		if err != nil {
+			return nil, publicError(err, "failed to edit message")
+		}
+		return &AckData{MessageID: msg.ID}, nil
+	case EventMessageDeleted:
+		if err := uc.DeleteMessage(ctx, cmd.MessageID, userID); err != nil {
+			return nil, publicError(err, "failed to delete message")
+		}
+		return &AckData{MessageID: cmd.MessageID}, nil
+	case EventJoin:
+		if err := uc.JoinRoom(ctx, cmd.RoomID, userID); err != nil {
+			return nil, publicError(err, "failed to join room")
+		}
+		uc.hub.subscribe <- roomSubscription{client: c, roomID: cmd.RoomID, join: true}
+		return nil, nil
+	case EventLeave:
+		uc.hub.subscribe <- roomSubscription{client: c, roomID: cmd.RoomID, join: false}
+		return nil, nil
+	case EventWatchPost, EventUnwatchPost:
+		if cmd.PostID <= 0 {
+			return nil, ErrInvalidPostID
+		}
+		uc.hub.subscribe <- roomSubscription{client: c, postID: cmd.PostID, join: eventType == EventWatchPost}
+		return nil, nil
+	case EventTyping:
+		key := fmt.Sprintf("room:%d", cmd.RoomID)
+		if cmd.To != 0 {
+			key = fmt.Sprintf("user:%d", cmd.To)
+		}
+		if time.Since(c.typedAt[key]) < typingThrottle {
+			return nil, nil
+		}
+		event := entity.TypingEvent{UserID: userID, Username: username, RoomID: cmd.RoomID, To: cmd.To}
+		if err := uc.SendTyping(ctx, event); err != nil {
+			return nil, publicError(err, "failed to send typing event")
+		}
+		c.typedAt[key] = time.Now()
+		return nil, nil
+	case EventPresence:
+		uc.hub.presenceRequests <- c
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown event type %q", eventType)
+}
+
+// SendTyping tells the room, or the other user of a direct conversation,
+// that the user is typing. Only members may report typing in a room.
+func (uc *ChatUseCase) SendTyping(ctx context.Context, event entity.TypingEvent) error {
+	if (event.RoomID == 0) == (event.To == 0) || event.To == event.UserID {
+		return ErrInvalidTyping
+	}
+	if err := uc.checkSanctions(ctx, event.UserID); err != nil {
+		return err
+	}
+	if event.RoomID != 0 && event.RoomID != entity.DefaultChatRoomID {
+		member, err := uc.repo.IsChatRoomMember(ctx, event.RoomID, event.UserID)
+		if err != nil {
+			return err
+		}
+		if !member {
+			return ErrNotRoomMember
+		}
+	}
+	return uc.broadcaster.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventTyping, Typing: &event})
+}
+
+// handleEvent sends an event from the broadcaster to the local clients it
+// concerns. The envelope is encoded once for all of them.
+func (h *WebSocketHub) handleEvent(event entity.ChatEvent) {
+	switch {
+	case event.Type == entity.ChatEventEdited && event.Message != nil:
+		msg := event.Message
+		env := newEnvelope(EventMessageEdited, "", msg)
+		for client := range h.clients {
+			if client.rooms[msg.RoomID] {
+				h.deliver(client, env)
+			}
+		}
+	case event.Deleted != nil:
+		env := newEnvelope(EventMessageDeleted, "", event.Deleted)
+		for client := range h.clients {
+			if client.rooms[event.Deleted.RoomID] {
+				h.deliver(client, env)
+			}
+		}
+	case event.Message != nil:
+		msg := event.Message
+		env := newEnvelope(EventMessage, "", msg)
+		if msg.RoomID == entity.DefaultChatRoomID {
+			env.replayID = msg.ID
+		}
+		for client := range h.clients {
+			if client.rooms[msg.RoomID] {
+				h.deliver(client, env)
+			}
+		}
+	case event.Direct != nil:
+		// Личное сообщение получают только соединения собеседников,
+		// включая другие вкладки отправителя.
+		env := newEnvelope(EventDirectMessage, "", event.Direct)
+		for _, userID := range []int{event.Direct.RecipientID, event.Direct.SenderID} {
+			for client := range h.users[userID] {
+				h.deliver(client, env)
+			}
+		}
+	case event.Typing != nil:
+		typing := event.Typing
+		env := newEnvelope(EventTyping, "", typing)
+		if typing.To != 0 {
+			for client := range h.users[typing.To] {
+				h.deliver(client, env)
+			}
+			return
+		}
+		for client := range h.clients {
+			if client.rooms[typing.RoomID] && client.identity.UserID != typing.UserID {
+				h.deliver(client, env)
+			}
+		}
+	case event.Presence != nil:
+		h.updatePresence(*event.Presence)
+	case event.Sanction != nil:
+		h.applySanction(*event.Sanction)
+	case event.Post != nil:
+		h.deliverFeed(FeedPosts, newEnvelope(EventPostCreated, "", event.Post))
+	case event.Comment != nil:
+		eventType := EventCommentCreated
+		if event.Type == entity.ChatEventCommentEdited {
+			eventType = EventCommentEdited
+		}
+		h.deliverComment(event.Comment.PostID, newEnvelope(eventType, "", event.Comment))
+	case event.DeletedComment != nil:
+		h.deliverComment(event.DeletedComment.PostID, newEnvelope(EventCommentDeleted, "", event.DeletedComment))
+	}
+}
+
+// userPresence records which instances a user is connected to.
+type userPresence struct {
+	username  string
+	instances map[string]bool
+}
+
+// updatePresence applies a presence event from any instance and tells local
+// clients when the user actually goes online or offline.
+func (h *WebSocketHub) updatePresence(event entity.PresenceEvent) {
+	p := h.online[event.UserID]
+	wasOnline := p != nil
+	if event.Online {
+		if p == nil {
+			p = &userPresence{instances: make(map[string]bool)}
+			h.online[event.UserID] = p
+		}
+		p.username = event.Username
+		p.instances[event.Instance] = true
+	} else if p != nil {
+		delete(p.instances, event.Instance)
+		if len(p.instances) == 0 {
+			delete(h.online, event.UserID)
+		}
+	}
+	if isOnline := h.online[event.UserID] != nil; isOnline != wasOnline {
+		env := newEnvelope(EventPresence, "", PresenceData{Users: []PresenceUser{
+			{UserID: event.UserID, Username: event.Username, Online: isOnline},
+		}})
+		for client := range h.clients {
+			if client.identity.UserID != 0 {
+				h.deliver(client, env)
+			}
+		}
+	}
+}
+
+func (h *WebSocketHub) presenceSnapshot() Envelope {
+	users := make([]PresenceUser, 0, len(h.online))
+	for userID, p := range h.online {
+		users = append(users, PresenceUser{UserID: userID, Username: p.username, Online: true})
+	}
+	return newEnvelope(EventPresence, "", PresenceData{Users: users})
+}
+
+// announce queues a change of this instance's connections for publishing.
+// It never blocks, because the broadcaster may call back into the hub.
+func (h *WebSocketHub) announce(userID int, username string, online bool) {
+	h.presenceMu.Lock()
+	h.presenceQueue = append(h.presenceQueue, entity.PresenceEvent{
+		Instance: h.instance,
+		UserID:   userID,
+		Username: username,
+		Online:   online,
+	})
+	h.presenceMu.Unlock()
+	select {
+	case h.presenceReady <- struct{}{}:
+	default:
+	}
+}
+
+// publishPresence sends queued presence changes through the broadcaster,
+// in order.
+func (h *WebSocketHub) publishPresence(broadcaster ChatBroadcaster) {
+	for range h.presenceReady {
+		h.flushPresence(context.Background(), broadcaster)
+	}
+}
+
+func (h *WebSocketHub) flushPresence(ctx context.Context, broadcaster ChatBroadcaster) {
+	h.presenceMu.Lock()
+	queue := h.presenceQueue
+	h.presenceQueue = nil
+	h.presenceMu.Unlock()
+	for i := range queue {
+		event := queue[i]
+		if err := broadcaster.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventPresence, Presence: &event}); err != nil {
+			log.Printf("Error publishing presence: %v", err)
+		}
+	}
+}
+
+// newInstanceID names this process in presence events.
+func newInstanceID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,206 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newProtocolServer(t *testing.T) (*usecase.ChatUseCase, *MockChatRepository, func(token string) *websocket.Conn) {
+	mockRepo := new(MockChatRepository)
+	authUC := new(mockAuthUC)
+	uc := usecase.NewChatUseCase(mockRepo, authUC)
+	authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+	authUC.On("ParseTokenClaims", "bob").Return(int64(2), "bob", time.Time{}, nil)
+	server := newChatServer(t, uc)
+	return uc, mockRepo, func(token string) *websocket.Conn { return dialChat(t, server, token) }
+}
+
+// waitOnline reads presence events until userID shows up online.
+func waitOnline(t *testing.T, conn *websocket.Conn, userID int) {
+	for {
+		var presence usecase.PresenceData
+		readEvent(t, conn, usecase.EventPresence, &presence)
+		for _, u := range presence.Users {
+			if u.UserID == userID && u.Online {
+				return
+			}
+		}
+	}
+}
+
+func TestChatProtocol_AckAndErrors(t *testing.T) {
+	_, mockRepo, dial := newProtocolServer(t)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.ChatMessage).ID = 42
+	}).Return(nil)
+	alice := dial("alice")
+
+	t.Run("Подтверждение с ID клиента", func(t *testing.T) {
+		require.NoError(t, alice.WriteJSON(map[string]interface{}{
+			"v": 1, "type": "message", "id": "c1", "data": map[string]interface{}{"text": "hi"},
+		}))
+		// Порядок ack и эха не гарантирован.
+		var ack usecase.AckData
+		for got := 0; got < 2; got++ {
+			var env usecase.Envelope
+			require.NoError(t, alice.ReadJSON(&env))
+			switch env.Type {
+			case usecase.EventAck:
+				assert.Equal(t, "c1", env.ID)
+				require.NoError(t, json.Unmarshal(env.Data, &ack))
+			case usecase.EventMessage:
+			default:
+				got--
+			}
+		}
+		assert.Equal(t, 42, ack.MessageID)
+	})
+
+	t.Run("Ошибка несёт ID запроса", func(t *testing.T) {
+		require.NoError(t, alice.WriteJSON(map[string]interface{}{
+			"v": 1, "type": "message", "id": "c2", "data": map[string]interface{}{"text": " "},
+		}))
+		var data usecase.ErrorData
+		env := readEvent(t, alice, usecase.EventError, &data)
+		assert.Equal(t, "c2", env.ID)
+		assert.Equal(t, "message cannot be empty", data.Message)
+	})
+
+	t.Run("Неизвестная версия", func(t *testing.T) {
+		require.NoError(t, alice.WriteJSON(map[string]interface{}{"v": 99, "type": "message", "id": "c3"}))
+		env := readEvent(t, alice, usecase.EventError, nil)
+		assert.Equal(t, "c3", env.ID)
+	})
+
+	t.Run("Неизвестный тип", func(t *testing.T) {
+		sendEvent(t, alice, "dance", nil)
+		var data usecase.ErrorData
+		readEvent(t, alice, usecase.EventError, &data)
+		assert.Contains(t, data.Message, "dance")
+	})
+}
+
+func TestChatProtocol_Typing(t *testing.T) {
+	_, mockRepo, dial := newProtocolServer(t)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+
+	alice := dial("alice")
+	waitOnline(t, alice, 1)
+	bob := dial("bob")
+	waitOnline(t, alice, 2)
+
+	sendEvent(t, bob, usecase.EventTyping, map[string]interface{}{"room_id": entity.DefaultChatRoomID})
+	var typing entity.TypingEvent
+	readEvent(t, alice, usecase.EventTyping, &typing)
+	assert.Equal(t, 2, typing.UserID)
+	assert.Equal(t, entity.DefaultChatRoomID, typing.RoomID)
+
+	// Повтор сразу же отбрасывается: следующим Alice получает сообщение.
+	sendEvent(t, bob, usecase.EventTyping, map[string]interface{}{"room_id": entity.DefaultChatRoomID})
+	sendEvent(t, bob, usecase.EventMessage, map[string]interface{}{"text": "done typing"})
+	var msg entity.ChatMessage
+	readEvent(t, alice, usecase.EventMessage, &msg)
+	assert.Equal(t, "done typing", msg.Text)
+	readEvent(t, bob, usecase.EventMessage, &msg) // своё сообщение
+
+	sendEvent(t, alice, usecase.EventTyping, map[string]interface{}{"to": 2})
+	readEvent(t, bob, usecase.EventTyping, &typing)
+	assert.Equal(t, 1, typing.UserID)
+	assert.Equal(t, 2, typing.To)
+
+	sendEvent(t, alice, usecase.EventTyping, map[string]interface{}{})
+	var data usecase.ErrorData
+	readEvent(t, alice, usecase.EventError, &data)
+	assert.Equal(t, usecase.ErrInvalidTyping.Error(), data.Message)
+}
+
+func TestChatProtocol_Presence(t *testing.T) {
+	_, _, dial := newProtocolServer(t)
+
+	alice := dial("alice")
+	waitOnline(t, alice, 1)
+
+	bob := dial("bob")
+	var presence usecase.PresenceData
+	readEvent(t, alice, usecase.EventPresence, &presence)
+	assert.Equal(t, []usecase.PresenceUser{{UserID: 2, Username: "bob", Online: true}}, presence.Users)
+
+	// По запросу приходит полный список.
+	waitOnline(t, bob, 2)
+	sendEvent(t, bob, usecase.EventPresence, nil)
+	readEvent(t, bob, usecase.EventPresence, &presence)
+	assert.ElementsMatch(t, []usecase.PresenceUser{
+		{UserID: 1, Username: "alice", Online: true},
+		{UserID: 2, Username: "bob", Online: true},
+	}, presence.Users)
+
+	require.NoError(t, bob.Close())
+	readEvent(t, alice, usecase.EventPresence, &presence)
+	assert.Equal(t, []usecase.PresenceUser{{UserID: 2, Username: "bob", Online: false}}, presence.Users)
+}
+
+func TestChatProtocol_WatchPost(t *testing.T) {
+	broadcaster := usecase.NewMemoryChatBroadcaster()
+	mockRepo := new(MockChatRepository)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+	authUC := new(mockAuthUC)
+	authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+	uc := usecase.NewChatUseCaseWithOptions(mockRepo, authUC, usecase.ChatOptions{Broadcaster: broadcaster})
+
+	commentRepo := new(MockCommentRepository)
+	commentRepo.On("GetPostByID", mock.Anything, mock.Anything).Return(&entity.Post{}, nil)
+	commentRepo.On("CreateComment", mock.Anything, mock.Anything).Return(nil)
+	commentRepo.On("UpdateComment", mock.Anything, 7, 2, "edited").
+		Return(&entity.Comment{ID: 7, PostID: 5, UserID: 2, Content: "edited"}, nil)
+	commentRepo.On("GetCommentByID", mock.Anything, 7).Return(&entity.Comment{ID: 7, PostID: 5, UserID: 2}, nil)
+	commentRepo.On("DeleteComment", mock.Anything, 7, 2).Return(nil)
+	comments := usecase.NewCommentUseCaseWithEvents(commentRepo, broadcaster)
+
+	alice := dialChat(t, newChatServer(t, uc), "alice")
+	ctx := context.Background()
+
+	// После ack хаб уже знает о подписке.
+	require.NoError(t, alice.WriteJSON(map[string]interface{}{
+		"v": 1, "type": usecase.EventWatchPost, "id": "w1", "data": map[string]interface{}{"post_id": 5},
+	}))
+	readEvent(t, alice, usecase.EventAck, nil)
+
+	require.NoError(t, comments.CreateComment(ctx, &entity.Comment{PostID: 6, UserID: 2, Content: "elsewhere"}))
+	require.NoError(t, comments.CreateComment(ctx, &entity.Comment{PostID: 5, UserID: 2, Content: "hello"}))
+	var comment entity.Comment
+	readEvent(t, alice, usecase.EventCommentCreated, &comment)
+	assert.Equal(t, 5, comment.PostID)
+	assert.Equal(t, "hello", comment.Content)
+
+	_, err := comments.EditComment(ctx, 5, 7, 2, "edited")
+	require.NoError(t, err)
+	readEvent(t, alice, usecase.EventCommentEdited, &comment)
+	assert.Equal(t, "edited", comment.Content)
+
+	require.NoError(t, comments.DeleteComment(ctx, 7, 2))
+	var deleted entity.DeletedComment
+	readEvent(t, alice, usecase.EventCommentDeleted, &deleted)
+	assert.Equal(t, entity.DeletedComment{ID: 7, PostID: 5}, deleted)
+
+	require.NoError(t, alice.WriteJSON(map[string]interface{}{
+		"v": 1, "type": usecase.EventUnwatchPost, "id": "w2", "data": map[string]interface{}{"post_id": 5},
+	}))
+	readEvent(t, alice, usecase.EventAck, nil)
+	require.NoError(t, comments.CreateComment(ctx, &entity.Comment{PostID: 5, UserID: 2, Content: "unseen"}))
+	require.NoError(t, uc.SendMessage(ctx, &entity.ChatMessage{UserID: 2, Text: "after"}))
+	readEvent(t, alice, usecase.EventMessage, nil)
+
+	sendEvent(t, alice, usecase.EventWatchPost, map[string]interface{}{"post_id": 0})
+	var data usecase.ErrorData
+	readEvent(t, alice, usecase.EventError, &data)
+	assert.Equal(t, usecase.ErrInvalidPostID.Error(), data.Message)
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+// ChatSession is a two-way chat connection over something other than a
+// WebSocket, such as a gRPC stream. It speaks the same envelopes as the
+// socket and sits in the same hub, so both kinds of clients see one chat.
+type ChatSession interface {
+	// Handle runs one client frame as if it was read from a socket; its ack
+	// or error comes back through Serve. Frames must be handled one at a
+	// time, in the order they arrived.
+	Handle(ctx context.Context, env Envelope)
+	// Serve passes frames for the client to send until ctx is done, the hub
+	// drops the session or send fails. It releases the session when it
+	// returns and must be called exactly once.
+	Serve(ctx context.Context, send func(Envelope) error) error
+}
+
+type chatSession struct {
+	uc         *ChatUseCase
+	client     *WebSocketClient
+	lastSeenID int
+}
+
+// OpenChatSession registers a session of an authenticated user with the hub.
+// Like a fresh WebSocket it is in the default room, and messages after
+// lastSeenID are replayed first. Banned users are refused with ErrChatBanned,
+// and ErrChatUnavailable is returned while the hub is full or shutting down.
+func (uc *ChatUseCase) OpenChatSession(identity ChatIdentity, lastSeenID int) (ChatSession, error) {
+	if uc.isBanned(identity.UserID) {
+		return nil, ErrChatBanned
+	}
+	if _, text, ok := uc.hub.admit(false); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrChatUnavailable, text)
+	}
+
+	client := newHubClient(nil, identity, uc.hub.settings.SendBuffer)
+	client.rooms[entity.DefaultChatRoomID] = true
+	uc.hub.register <- client
+	return &chatSession{uc: uc, client: client, lastSeenID: lastSeenID}, nil
+}
+
+func (s *chatSession) Handle(ctx context.Context, env Envelope) {
+	s.client.handleFrame(ctx, s.uc, env)
+}
+
+func (s *chatSession) Serve(ctx context.Context, send func(Envelope) error) error {
+	// Пинги не нужны: живость соединения проверяет сам транспорт.
+	return s.uc.serveClient(ctx, s.client, s.lastSeenID, send, nil)
+}
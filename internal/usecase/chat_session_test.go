@@ -0,0 +1,138 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// serveSession runs the session's Serve in the background and returns the
+// frames it sends, plus its result once it has returned.
+func serveSession(ctx context.Context, session usecase.ChatSession) (<-chan usecase.Envelope, <-chan error) {
+	frames := make(chan usecase.Envelope, 64)
+	result := make(chan error, 1)
+	go func() {
+		result <- session.Serve(ctx, func(env usecase.Envelope) error {
+			frames <- env
+			return nil
+		})
+	}()
+	return frames, result
+}
+
+// nextFrame returns the next frame of the given type, skipping presence.
+func nextFrame(t *testing.T, frames <-chan usecase.Envelope, eventType string, v interface{}) usecase.Envelope {
+	t.Helper()
+	for {
+		select {
+		case env := <-frames:
+			if env.Type == usecase.EventPresence && eventType != usecase.EventPresence {
+				continue
+			}
+			require.Equal(t, eventType, env.Type, "data: %s", env.Data)
+			if v != nil {
+				require.NoError(t, json.Unmarshal(env.Data, v))
+			}
+			return env
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s frame", eventType)
+		}
+	}
+}
+
+func TestChatSession_SharesHubWithWebSocket(t *testing.T) {
+	uc, mockRepo, dial := newProtocolServer(t)
+	nextID := 0
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		nextID++
+		args.Get(1).(*entity.ChatMessage).ID = nextID
+	}).Return(nil)
+	alice := dial("alice")
+	waitOnline(t, alice, 1)
+
+	session, err := uc.OpenChatSession(usecase.ChatIdentity{UserID: 2, Username: "bob"}, 0)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames, result := serveSession(ctx, session)
+	nextFrame(t, frames, usecase.EventSystem, nil)
+
+	// Сессия видна в присутствии, как обычный сокет.
+	waitOnline(t, alice, 2)
+
+	t.Run("Сообщение из сессии доходит до сокета", func(t *testing.T) {
+		raw, _ := json.Marshal(map[string]string{"text": "from grpc"})
+		session.Handle(ctx, usecase.Envelope{V: usecase.ChatProtocolVersion, Type: usecase.EventMessage, ID: "s1", Data: raw})
+
+		var msg entity.ChatMessage
+		readEvent(t, alice, usecase.EventMessage, &msg)
+		assert.Equal(t, "from grpc", msg.Text)
+		assert.Equal(t, "bob", msg.Author)
+
+		// Порядок ack и эха не гарантирован.
+		var ack usecase.AckData
+		for got := 0; got < 2; {
+			env := <-frames
+			if env.Type == usecase.EventPresence {
+				continue
+			}
+			got++
+			if env.Type == usecase.EventAck {
+				assert.Equal(t, "s1", env.ID)
+				require.NoError(t, json.Unmarshal(env.Data, &ack))
+			}
+		}
+		assert.Equal(t, msg.ID, ack.MessageID)
+	})
+
+	t.Run("Сообщение из сокета доходит до сессии", func(t *testing.T) {
+		sendEvent(t, alice, usecase.EventMessage, map[string]interface{}{"text": "from ws"})
+		var msg entity.ChatMessage
+		nextFrame(t, frames, usecase.EventMessage, &msg)
+		assert.Equal(t, "from ws", msg.Text)
+		readEvent(t, alice, usecase.EventMessage, nil) // эхо отправителю
+	})
+
+	t.Run("Ошибки приходят кадром error", func(t *testing.T) {
+		session.Handle(ctx, usecase.Envelope{Type: "dance", ID: "s2"})
+		env := nextFrame(t, frames, usecase.EventError, nil)
+		assert.Equal(t, "s2", env.ID)
+	})
+
+	cancel()
+	require.NoError(t, <-result)
+	var presence usecase.PresenceData
+	for {
+		readEvent(t, alice, usecase.EventPresence, &presence)
+		if len(presence.Users) == 1 && presence.Users[0].UserID == 2 && !presence.Users[0].Online {
+			break
+		}
+	}
+}
+
+func TestChatSession_Shutdown(t *testing.T) {
+	uc, _, _ := newProtocolServer(t)
+	session, err := uc.OpenChatSession(usecase.ChatIdentity{UserID: 2, Username: "bob"}, 0)
+	require.NoError(t, err)
+	frames, result := serveSession(context.Background(), session)
+	nextFrame(t, frames, usecase.EventSystem, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, uc.Shutdown(ctx))
+
+	var data usecase.SystemData
+	nextFrame(t, frames, usecase.EventSystem, &data)
+	assert.Equal(t, "closed", data.Code)
+	require.NoError(t, <-result)
+
+	_, err = uc.OpenChatSession(usecase.ChatIdentity{UserID: 2, Username: "bob"}, 0)
+	assert.ErrorIs(t, err, usecase.ErrChatUnavailable)
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketSettings controls keepalives and limits of chat connections.
+type WebSocketSettings struct {
+	// ReadLimit is the largest frame a client may send, in bytes.
+	ReadLimit int64
+	// PongWait is how long a connection may stay silent before it is
+	// considered dead. Pings are sent every PingPeriod, which must be shorter.
+	PongWait   time.Duration
+	PingPeriod time.Duration
+	// WriteWait bounds every single write to the socket.
+	WriteWait time.Duration
+	// SendBuffer is how many outgoing frames may queue up for one client
+	// before it is disconnected as a slow consumer.
+	SendBuffer     int
+	MaxConnections int
+	// MaxAnonymousStreams caps anonymous event streams on their own, so that
+	// they can't take the slots of signed-in users.
+	MaxAnonymousStreams int
+}
+
+// DefaultWebSocketSettings returns the settings NewChatUseCase uses.
+func DefaultWebSocketSettings() WebSocketSettings {
+	return WebSocketSettings{
+		ReadLimit:           4096,
+		PongWait:            60 * time.Second,
+		PingPeriod:          54 * time.Second,
+		WriteWait:           10 * time.Second,
+		SendBuffer:          256,
+		MaxConnections:      100,
+		MaxAnonymousStreams: 100,
+	}
+}
+
+// withDefaults fills zero fields from DefaultWebSocketSettings, so a config
+// only has to set what it changes.
+func (s WebSocketSettings) withDefaults() WebSocketSettings {
+	d := DefaultWebSocketSettings()
+	if s.ReadLimit <= 0 {
+		s.ReadLimit = d.ReadLimit
+	}
+	if s.PongWait <= 0 {
+		s.PongWait = d.PongWait
+	}
+	if s.PingPeriod <= 0 || s.PingPeriod >= s.PongWait {
+		s.PingPeriod = s.PongWait * 9 / 10
+	}
+	if s.WriteWait <= 0 {
+		s.WriteWait = d.WriteWait
+	}
+	if s.SendBuffer <= 0 {
+		s.SendBuffer = d.SendBuffer
+	}
+	if s.MaxConnections <= 0 {
+		s.MaxConnections = d.MaxConnections
+	}
+	if s.MaxAnonymousStreams <= 0 {
+		s.MaxAnonymousStreams = d.MaxAnonymousStreams
+	}
+	return s
+}
+
+// close asks writePump to send a close frame with the given code and drop the
+// connection. The first call wins; it is safe from any goroutine.
+func (c *WebSocketClient) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeCode, c.closeText = code, text
+		close(c.done)
+	})
+}
+
+// reply queues a frame for this client only, e.g. an error for a bad request.
+// It never blocks readPump: a client that stopped reading is disconnected.
+func (c *WebSocketClient) reply(env Envelope) {
+	select {
+	case c.send <- env:
+	case <-c.done:
+	default:
+		c.close(websocket.CloseTryAgainLater, "slow consumer")
+	}
+}
+
+// Shutdown stops accepting chat connections, sends every connected client a
+// "going away" close frame and waits until all of them are gone or ctx ends.
+func (uc *ChatUseCase) Shutdown(ctx context.Context) error {
+	uc.hub.mutex.Lock()
+	uc.hub.draining = true
+	uc.hub.mutex.Unlock()
+	uc.hub.shutdown <- struct{}{}
+
+	done := make(chan struct{})
+	go func() {
+		uc.hub.connections.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		// Другие экземпляры должны узнать, что эти пользователи ушли.
+		uc.hub.flushPresence(ctx, uc.broadcaster)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
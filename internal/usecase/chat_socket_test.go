@@ -0,0 +1,223 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingConn is a WebSocketConnection whose writes hang until unblock is
+// closed, like a client that stopped reading. Reads wait until Close.
+type blockingConn struct {
+	unblock     chan struct{}
+	writing     chan struct{} // закрывается при первой записи
+	closed      chan struct{}
+	writingOnce sync.Once
+	closeOnce   sync.Once
+
+	mu         sync.Mutex
+	closeCodes []int
+}
+
+func newBlockingConn() *blockingConn {
+	return &blockingConn{unblock: make(chan struct{}), writing: make(chan struct{}), closed: make(chan struct{})}
+}
+
+func (c *blockingConn) WriteJSON(v interface{}) error {
+	c.writingOnce.Do(func() { close(c.writing) })
+	select {
+	case <-c.unblock:
+		return nil
+	case <-c.closed:
+		return net.ErrClosed
+	}
+}
+
+func (c *blockingConn) ReadJSON(v interface{}) error {
+	<-c.closed
+	return net.ErrClosed
+}
+
+func (c *blockingConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *blockingConn) WriteMessage(messageType int, data []byte) error { return nil }
+func (c *blockingConn) ReadMessage() (int, []byte, error)               { return 0, nil, net.ErrClosed }
+func (c *blockingConn) SetReadLimit(limit int64)                        {}
+func (c *blockingConn) SetReadDeadline(t time.Time) error               { return nil }
+func (c *blockingConn) SetWriteDeadline(t time.Time) error              { return nil }
+func (c *blockingConn) SetPongHandler(h func(string) error)             {}
+func (c *blockingConn) SetPingHandler(h func(string) error)             {}
+func (c *blockingConn) LocalAddr() net.Addr                             { return nil }
+func (c *blockingConn) RemoteAddr() net.Addr                            { return nil }
+func (c *blockingConn) Subprotocol() string                             { return "" }
+func (c *blockingConn) UnderlyingConn() net.Conn                        { return nil }
+
+func (c *blockingConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	if messageType == websocket.CloseMessage && len(data) >= 2 {
+		c.mu.Lock()
+		c.closeCodes = append(c.closeCodes, int(data[0])<<8|int(data[1]))
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+func (c *blockingConn) codes() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int(nil), c.closeCodes...)
+}
+
+func TestChatUseCase_Keepalive(t *testing.T) {
+	settings := usecase.WebSocketSettings{PongWait: 200 * time.Millisecond, PingPeriod: 50 * time.Millisecond}
+
+	t.Run("Клиент, отвечающий на ping, остаётся подключён", func(t *testing.T) {
+		mockRepo := new(MockChatRepository)
+		authUC := new(mockAuthUC)
+		uc := usecase.NewChatUseCaseWithOptions(mockRepo, authUC, usecase.ChatOptions{WebSocket: settings})
+		mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+		authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+		server := newChatServer(t, uc)
+
+		conn := dialChat(t, server, "alice")
+		received := make(chan usecase.Envelope, 8)
+		go func() {
+			// Чтение обрабатывает ping и отвечает pong.
+			for {
+				var env usecase.Envelope
+				if err := conn.ReadJSON(&env); err != nil {
+					return
+				}
+				if env.Type == usecase.EventMessage {
+					received <- env
+				}
+			}
+		}()
+
+		time.Sleep(3 * settings.PongWait)
+		sendEvent(t, conn, usecase.EventMessage, map[string]interface{}{"text": "still here"})
+		select {
+		case env := <-received:
+			assert.Contains(t, string(env.Data), "still here")
+		case <-time.After(2 * time.Second):
+			t.Fatal("connection was dropped")
+		}
+	})
+
+	t.Run("Молчащий клиент отключается", func(t *testing.T) {
+		authUC := new(mockAuthUC)
+		uc := usecase.NewChatUseCaseWithOptions(new(MockChatRepository), authUC, usecase.ChatOptions{WebSocket: settings})
+		authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+		server := newChatServer(t, uc)
+
+		conn := dialChat(t, server, "alice")
+		conn.SetPingHandler(func(string) error { return nil }) // не отвечаем pong
+		time.Sleep(2 * settings.PongWait)
+		err := readUntilClosed(conn)
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "got %v", err)
+	})
+}
+
+func TestChatUseCase_ReadLimit(t *testing.T) {
+	authUC := new(mockAuthUC)
+	uc := usecase.NewChatUseCaseWithOptions(new(MockChatRepository), authUC, usecase.ChatOptions{
+		WebSocket: usecase.WebSocketSettings{ReadLimit: 64},
+	})
+	authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+	server := newChatServer(t, uc)
+
+	conn := dialChat(t, server, "alice")
+	sendEvent(t, conn, usecase.EventMessage, map[string]interface{}{"text": strings.Repeat("x", 100)})
+	err := readUntilClosed(conn)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), "got %v", err)
+}
+
+// TestChatUseCase_SlowConsumer проверяет, что клиент с переполненным буфером
+// отключается, не блокируя хаб и не роняя readPump.
+func TestChatUseCase_SlowConsumer(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	uc := usecase.NewChatUseCaseWithOptions(mockRepo, new(mockAuthUC), usecase.ChatOptions{
+		WebSocket: usecase.WebSocketSettings{SendBuffer: 2},
+		Flood:     usecase.FloodLimit{Messages: 1000},
+	})
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+
+	conn := newBlockingConn()
+	finished := make(chan struct{})
+	go func() {
+		uc.HandleWebSocket(conn, usecase.ChatIdentity{UserID: 1, Username: "alice"}, 0)
+		close(finished)
+	}()
+
+	send := func() {
+		require.NoError(t, uc.SendMessage(context.Background(), &entity.ChatMessage{UserID: 2, Text: "spam"}))
+	}
+	// Шлём, пока writePump не повиснет на записи. Если буфер переполнился
+	// раньше, чем writePump успел что-то записать, клиент уже отключён.
+	for writing := false; !writing; {
+		send()
+		select {
+		case <-conn.writing:
+			writing = true
+		case <-finished:
+			writing = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	// Буфер на два кадра: третий уже не влезает. Четвёртый нужен, чтобы
+	// хаб точно обработал третий до того, как запись разблокируется.
+	for i := 0; i < 4; i++ {
+		send()
+	}
+	close(conn.unblock)
+
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow consumer was not disconnected")
+	}
+	assert.Equal(t, []int{websocket.CloseTryAgainLater}, conn.codes())
+}
+
+func TestChatUseCase_Shutdown(t *testing.T) {
+	authUC := new(mockAuthUC)
+	uc := usecase.NewChatUseCase(new(MockChatRepository), authUC)
+	authUC.On("ParseTokenClaims", "alice").Return(int64(1), "alice", time.Time{}, nil)
+	authUC.On("ParseTokenClaims", "bob").Return(int64(2), "bob", time.Time{}, nil)
+	server := newChatServer(t, uc)
+
+	alice := dialChat(t, server, "alice")
+	bob := dialChat(t, server, "bob")
+	// Убеждаемся, что оба соединения уже в хабе.
+	sendEvent(t, alice, usecase.EventMessage, map[string]interface{}{"text": ""})
+	readEvent(t, alice, usecase.EventError, nil)
+	sendEvent(t, bob, usecase.EventMessage, map[string]interface{}{"text": ""})
+	readEvent(t, bob, usecase.EventError, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, uc.Shutdown(ctx))
+
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		err := readUntilClosed(conn)
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+	}
+
+	late := dialChat(t, server, "alice")
+	err := readUntilClosed(late)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+	assert.False(t, errors.Is(ctx.Err(), context.DeadlineExceeded))
+}
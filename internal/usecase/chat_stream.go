@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+// Feeds an event stream can follow.
+const (
+	FeedChat     = "chat"
+	FeedPosts    = "posts"
+	FeedComments = "comments"
+)
+
+// MaxWatchedPosts is how many posts one connection may watch at a time.
+const MaxWatchedPosts = 20
+
+var (
+	ErrInvalidFeed     = errors.New("unknown feed")
+	ErrChatUnavailable = errors.New("chat is not accepting connections")
+	ErrInvalidPostID   = errors.New("invalid post ID")
+	ErrTooManyWatched  = errors.New("too many watched posts")
+)
+
+// EventStreamOptions describe what an event stream delivers.
+type EventStreamOptions struct {
+	// Identity is nil for anonymous streams, which get the public feeds only:
+	// no direct messages and no presence.
+	Identity *ChatIdentity
+	// Feeds defaults to FeedChat, or to nothing when Posts is set.
+	Feeds []string
+	// Posts are watched for created, edited and deleted comments.
+	Posts []int
+	// LastEventID replays the default room's messages after it, like
+	// lastSeenID of HandleWebSocket.
+	LastEventID int
+}
+
+// EventWriter is where an event stream writes to; gin.ResponseWriter is one.
+type EventWriter interface {
+	io.Writer
+	Flush()
+}
+
+// EventStream is a one-way subscription to the hub, for clients that can't
+// use a WebSocket. Frames are the same envelopes the socket sends, written as
+// Server-Sent Events.
+type EventStream interface {
+	// Serve writes events to w until ctx is done, the hub drops the stream
+	// or a write fails. It releases the stream when it returns and must be
+	// called exactly once.
+	Serve(ctx context.Context, w EventWriter) error
+}
+
+type eventStream struct {
+	uc          *ChatUseCase
+	client      *WebSocketClient
+	lastEventID int
+}
+
+// OpenEventStream registers a new event stream with the hub. Banned users
+// are refused with ErrChatBanned, and ErrChatUnavailable is returned while
+// the hub is full or shutting down. Anonymous streams have a cap of their
+// own, MaxAnonymousStreams.
+func (uc *ChatUseCase) OpenEventStream(opts EventStreamOptions) (EventStream, error) {
+	feeds := opts.Feeds
+	if len(feeds) == 0 && len(opts.Posts) == 0 {
+		feeds = []string{FeedChat}
+	}
+	for _, feed := range feeds {
+		if feed != FeedChat && feed != FeedPosts && feed != FeedComments {
+			return nil, fmt.Errorf("%w %q", ErrInvalidFeed, feed)
+		}
+	}
+	if len(opts.Posts) > MaxWatchedPosts {
+		return nil, fmt.Errorf("%w: at most %d", ErrTooManyWatched, MaxWatchedPosts)
+	}
+	for _, postID := range opts.Posts {
+		if postID <= 0 {
+			return nil, ErrInvalidPostID
+		}
+	}
+
+	var identity ChatIdentity
+	if opts.Identity != nil {
+		identity = *opts.Identity
+		if uc.isBanned(identity.UserID) {
+			return nil, ErrChatBanned
+		}
+	}
+	if _, text, ok := uc.hub.admit(opts.Identity == nil); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrChatUnavailable, text)
+	}
+
+	client := newHubClient(nil, identity, uc.hub.settings.SendBuffer)
+	client.anonymous = opts.Identity == nil
+	for _, feed := range feeds {
+		if feed == FeedChat {
+			client.rooms[entity.DefaultChatRoomID] = true
+		} else {
+			client.feeds[feed] = true
+		}
+	}
+	for _, postID := range opts.Posts {
+		client.posts[postID] = true
+	}
+	uc.hub.register <- client
+	return &eventStream{uc: uc, client: client, lastEventID: opts.LastEventID}, nil
+}
+
+func (s *eventStream) Serve(ctx context.Context, w EventWriter) error {
+	write := func(env Envelope) error {
+		if err := writeEventFrame(w, env); err != nil {
+			return err
+		}
+		w.Flush()
+		return nil
+	}
+	// Комментарий не виден клиенту, но не даёт прокси закрыть простаивающее
+	// соединение.
+	ping := func() error {
+		if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+			return err
+		}
+		w.Flush()
+		return nil
+	}
+	return s.uc.serveClient(ctx, s.client, s.lastEventID, write, ping)
+}
+
+// serveClient passes the frames of a hub client without a WebSocket to write
+// until ctx is done, the hub drops the client or a write fails. It starts
+// with a "connected" system frame and the replay after lastSeenID, ends with
+// a "closed" one when the hub drops the client, and calls ping, if set, when
+// the client has been idle for a ping period. The client is released on
+// return.
+func (uc *ChatUseCase) serveClient(ctx context.Context, c *WebSocketClient, lastSeenID int, write func(Envelope) error, ping func() error) error {
+	hub := uc.hub
+	defer func() {
+		hub.unregister <- c
+		hub.release(c.anonymous)
+	}()
+
+	if !c.identity.ExpiresAt.IsZero() {
+		expiry := time.AfterFunc(time.Until(c.identity.ExpiresAt), func() {
+			c.close(websocket.ClosePolicyViolation, "token expired")
+		})
+		defer expiry.Stop()
+	}
+
+	// Первый кадр сразу отправляет заголовки, чтобы клиент не ждал первого
+	// события. Как и у сокета, клиент уже в хабе и живые события ждут в send.
+	if err := write(newEnvelope(EventSystem, "", SystemData{Code: "connected", Message: "stream started"})); err != nil {
+		return err
+	}
+	if lastSeenID > 0 && c.rooms[entity.DefaultChatRoomID] {
+		if err := uc.replayMissed(c, lastSeenID, write); err != nil {
+			log.Printf("Error replaying chat history: %v", err)
+			if err := write(errorEnvelope("", "failed to replay missed messages")); err != nil {
+				return err
+			}
+		}
+	}
+
+	var pings <-chan time.Time
+	if ping != nil {
+		ticker := time.NewTicker(hub.settings.PingPeriod)
+		defer ticker.Stop()
+		pings = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.done:
+			// Закрыл хаб: сообщаем причину, дальше клиент переподключится сам.
+			return write(newEnvelope(EventSystem, "", SystemData{Code: "closed", Message: c.closeText}))
+		case env := <-c.send:
+			if env.replayID > 0 && env.replayID <= c.replayedUpTo {
+				continue // уже отправлено при повторе истории
+			}
+			if err := write(env); err != nil {
+				return err
+			}
+		case <-pings:
+			if err := ping(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeEventFrame writes env as a Server-Sent Event. Only default room
+// messages get an id, so Last-Event-ID is always something replayMissed
+// understands.
+func writeEventFrame(w io.Writer, env Envelope) error {
+	frame := make([]byte, 0, len(env.Data)+64)
+	if env.replayID > 0 {
+		frame = append(frame, "id: "+strconv.Itoa(env.replayID)+"\n"...)
+	}
+	frame = append(frame, "event: "+env.Type+"\n"...)
+	frame = append(frame, "data: "...)
+	if len(env.Data) > 0 {
+		frame = append(frame, env.Data...)
+	} else {
+		frame = append(frame, "{}"...)
+	}
+	frame = append(frame, "\n\n"...)
+	_, err := w.Write(frame)
+	return err
+}
+
+// deliverComment sends a comment event to the clients that follow the
+// comments feed or watch the post.
+func (h *WebSocketHub) deliverComment(postID int, env Envelope) {
+	for client := range h.clients {
+		if client.feeds[FeedComments] || client.posts[postID] {
+			h.deliver(client, env)
+		}
+	}
+}
+
+// watchPost applies a watch_post or unwatch_post request.
+func (h *WebSocketHub) watchPost(sub roomSubscription) {
+	client := sub.client
+	switch {
+	case !sub.join:
+		delete(client.posts, sub.postID)
+	case client.posts[sub.postID]:
+	case len(client.posts) >= MaxWatchedPosts:
+		h.deliver(client, errorEnvelope("", fmt.Sprintf("%v: at most %d", ErrTooManyWatched, MaxWatchedPosts)))
+	default:
+		client.posts[sub.postID] = true
+	}
+}
+
+// deliverFeed sends a forum event to the streams that follow the feed.
+func (h *WebSocketHub) deliverFeed(feed string, env Envelope) {
+	for client := range h.clients {
+		if client.feeds[feed] {
+			h.deliver(client, env)
+		}
+	}
+}
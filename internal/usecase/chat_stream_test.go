@@ -0,0 +1,277 @@
+package usecase_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type flushWriter struct {
+	http.ResponseWriter
+}
+
+func (w flushWriter) Flush() {
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+// newStreamServer serves event streams of uc the way the HTTP handler does:
+// the Authorization header is optional, feeds come from the query.
+func newStreamServer(t *testing.T, uc *usecase.ChatUseCase) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		opts := usecase.EventStreamOptions{}
+		if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != "" {
+			identity, err := uc.Authenticate(token)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			opts.Identity = identity
+		}
+		if feeds := r.URL.Query().Get("feeds"); feeds != "" {
+			opts.Feeds = strings.Split(feeds, ",")
+		}
+		if postIDs := r.URL.Query().Get("post_ids"); postIDs != "" {
+			for _, raw := range strings.Split(postIDs, ",") {
+				postID, _ := strconv.Atoi(raw)
+				opts.Posts = append(opts.Posts, postID)
+			}
+		}
+		opts.LastEventID, _ = strconv.Atoi(r.Header.Get("Last-Event-ID"))
+
+		stream, err := uc.OpenEventStream(opts)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		stream.Serve(r.Context(), flushWriter{w})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func openStream(t *testing.T, server *httptest.Server, query string, header http.Header) *bufio.Reader {
+	req, err := http.NewRequest("GET", server.URL+query, nil)
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	t.Cleanup(func() { resp.Body.Close() })
+	return bufio.NewReader(resp.Body)
+}
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readSSE reads the next event, skipping comments such as pings.
+func readSSE(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if ev.event != "" {
+				return ev
+			}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "id: "):
+			ev.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			ev.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// readStreamEvent returns the next event of the given type, skipping system
+// and presence events.
+func readStreamEvent(t *testing.T, r *bufio.Reader, eventType string, data interface{}) sseEvent {
+	t.Helper()
+	for {
+		ev := readSSE(t, r)
+		if ev.event != eventType && (ev.event == usecase.EventSystem || ev.event == usecase.EventPresence) {
+			continue
+		}
+		require.Equal(t, eventType, ev.event, ev.data)
+		if data != nil {
+			require.NoError(t, json.Unmarshal([]byte(ev.data), data))
+		}
+		return ev
+	}
+}
+
+func TestChatEventStream_Resume(t *testing.T) {
+	mockRepo := new(MockChatRepository)
+	uc := usecase.NewChatUseCase(mockRepo, new(mockAuthUC))
+	mockRepo.On("GetChatMessages", mock.Anything, entity.ChatHistoryFilter{
+		RoomID: 1, AfterID: 10, Limit: usecase.MaxChatHistoryLimit,
+	}).Return([]entity.ChatMessage{{ID: 11, RoomID: 1, Text: "missed 1"}, {ID: 12, RoomID: 1, Text: "missed 2"}}, nil)
+	mockRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.ChatMessage).ID = 13
+	}).Return(nil)
+
+	stream := openStream(t, newStreamServer(t, uc), "", http.Header{"Last-Event-ID": {"10"}})
+
+	var got entity.ChatMessage
+	for _, want := range []int{11, 12} {
+		ev := readStreamEvent(t, stream, usecase.EventMessage, &got)
+		assert.Equal(t, want, got.ID)
+		assert.Equal(t, strconv.Itoa(want), ev.id)
+	}
+
+	require.NoError(t, uc.SendMessage(context.Background(), &entity.ChatMessage{UserID: 1, Author: "alice", Text: "live"}))
+	ev := readStreamEvent(t, stream, usecase.EventMessage, &got)
+	assert.Equal(t, "13", ev.id)
+	assert.Equal(t, "live", got.Text)
+}
+
+func TestChatEventStream_ForumFeeds(t *testing.T) {
+	broadcaster := usecase.NewMemoryChatBroadcaster()
+	chatRepo := new(MockChatRepository)
+	chatRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+	uc := usecase.NewChatUseCaseWithOptions(chatRepo, new(mockAuthUC), usecase.ChatOptions{Broadcaster: broadcaster})
+
+	postRepo := new(MockPostRepository)
+	postRepo.On("CreatePost", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Post).ID = 5
+	}).Return(nil)
+	posts := usecase.NewPostUseCaseWithEvents(postRepo, new(MockUserRepository), broadcaster)
+
+	commentRepo := new(MockCommentRepository)
+	commentRepo.On("GetPostByID", mock.Anything, mock.Anything).Return(&entity.Post{}, nil)
+	commentRepo.On("CreateComment", mock.Anything, mock.Anything).Return(nil)
+	comments := usecase.NewCommentUseCaseWithEvents(commentRepo, broadcaster)
+
+	stream := openStream(t, newStreamServer(t, uc), "?feeds=posts,comments", nil)
+	readStreamEvent(t, stream, usecase.EventSystem, nil)
+
+	ctx := context.Background()
+	// Сообщения чата в поток без ленты chat не попадают.
+	require.NoError(t, uc.SendMessage(ctx, &entity.ChatMessage{UserID: 1, Text: "chat"}))
+	require.NoError(t, posts.CreatePost(ctx, &entity.Post{UserID: 1, Title: "Hello", Content: "World"}))
+	require.NoError(t, comments.CreateComment(ctx, &entity.Comment{PostID: 5, UserID: 2, Content: "First"}))
+
+	var post entity.Post
+	ev := readStreamEvent(t, stream, usecase.EventPostCreated, &post)
+	assert.Equal(t, 5, post.ID)
+	assert.Empty(t, ev.id)
+
+	var comment entity.Comment
+	readStreamEvent(t, stream, usecase.EventCommentCreated, &comment)
+	assert.Equal(t, "First", comment.Content)
+}
+
+func TestChatEventStream_WatchedPost(t *testing.T) {
+	broadcaster := usecase.NewMemoryChatBroadcaster()
+	chatRepo := new(MockChatRepository)
+	chatRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+	uc := usecase.NewChatUseCaseWithOptions(chatRepo, new(mockAuthUC), usecase.ChatOptions{Broadcaster: broadcaster})
+
+	commentRepo := new(MockCommentRepository)
+	commentRepo.On("GetPostByID", mock.Anything, mock.Anything).Return(&entity.Post{}, nil)
+	commentRepo.On("CreateComment", mock.Anything, mock.Anything).Return(nil)
+	comments := usecase.NewCommentUseCaseWithEvents(commentRepo, broadcaster)
+
+	// Только пост: ни чата, ни чужих комментариев.
+	stream := openStream(t, newStreamServer(t, uc), "?post_ids=5", nil)
+	readStreamEvent(t, stream, usecase.EventSystem, nil)
+
+	ctx := context.Background()
+	require.NoError(t, uc.SendMessage(ctx, &entity.ChatMessage{UserID: 1, Text: "chat"}))
+	require.NoError(t, comments.CreateComment(ctx, &entity.Comment{PostID: 6, UserID: 2, Content: "elsewhere"}))
+	require.NoError(t, comments.CreateComment(ctx, &entity.Comment{PostID: 5, UserID: 2, Content: "here"}))
+
+	var comment entity.Comment
+	readStreamEvent(t, stream, usecase.EventCommentCreated, &comment)
+	assert.Equal(t, "here", comment.Content)
+
+	_, err := uc.OpenEventStream(usecase.EventStreamOptions{Posts: []int{0}})
+	assert.ErrorIs(t, err, usecase.ErrInvalidPostID)
+	_, err = uc.OpenEventStream(usecase.EventStreamOptions{Posts: make([]int, usecase.MaxWatchedPosts+1)})
+	assert.ErrorIs(t, err, usecase.ErrTooManyWatched)
+}
+
+func TestChatEventStream_Open(t *testing.T) {
+	uc := usecase.NewChatUseCaseWithOptions(new(MockChatRepository), new(mockAuthUC), usecase.ChatOptions{
+		WebSocket: usecase.WebSocketSettings{MaxConnections: 1, MaxAnonymousStreams: 1},
+	})
+
+	_, err := uc.OpenEventStream(usecase.EventStreamOptions{Feeds: []string{"votes"}})
+	assert.ErrorIs(t, err, usecase.ErrInvalidFeed)
+
+	stream, err := uc.OpenEventStream(usecase.EventStreamOptions{})
+	require.NoError(t, err)
+	_, err = uc.OpenEventStream(usecase.EventStreamOptions{})
+	assert.ErrorIs(t, err, usecase.ErrChatUnavailable)
+
+	// Serve освобождает место, когда клиент уходит.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, stream.Serve(ctx, flushWriter{httptest.NewRecorder()}))
+	stream, err = uc.OpenEventStream(usecase.EventStreamOptions{})
+	require.NoError(t, err)
+	stream.Serve(ctx, flushWriter{httptest.NewRecorder()})
+}
+
+func TestChatEventStream_AnonymousLimit(t *testing.T) {
+	uc := usecase.NewChatUseCaseWithOptions(new(MockChatRepository), new(mockAuthUC), usecase.ChatOptions{
+		WebSocket: usecase.WebSocketSettings{MaxConnections: 1, MaxAnonymousStreams: 1},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	anonymous, err := uc.OpenEventStream(usecase.EventStreamOptions{})
+	require.NoError(t, err)
+	_, err = uc.OpenEventStream(usecase.EventStreamOptions{})
+	assert.ErrorIs(t, err, usecase.ErrChatUnavailable)
+
+	// Анонимы не занимают места пользователей, и наоборот
+	signedIn, err := uc.OpenEventStream(usecase.EventStreamOptions{Identity: &usecase.ChatIdentity{UserID: 1}})
+	require.NoError(t, err)
+	_, err = uc.OpenChatSession(usecase.ChatIdentity{UserID: 2}, 0)
+	assert.ErrorIs(t, err, usecase.ErrChatUnavailable)
+
+	require.NoError(t, anonymous.Serve(ctx, flushWriter{httptest.NewRecorder()}))
+	anonymous, err = uc.OpenEventStream(usecase.EventStreamOptions{})
+	require.NoError(t, err)
+	anonymous.Serve(ctx, flushWriter{httptest.NewRecorder()})
+	signedIn.Serve(ctx, flushWriter{httptest.NewRecorder()})
+}
+
+func TestChatEventStream_Shutdown(t *testing.T) {
+	uc := usecase.NewChatUseCase(new(MockChatRepository), new(mockAuthUC))
+	stream := openStream(t, newStreamServer(t, uc), "", nil)
+	readStreamEvent(t, stream, usecase.EventSystem, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, uc.Shutdown(ctx))
+
+	var data usecase.SystemData
+	readStreamEvent(t, stream, usecase.EventSystem, &data)
+	assert.Equal(t, "closed", data.Code)
+	assert.Equal(t, "server shutting down", data.Message)
+}
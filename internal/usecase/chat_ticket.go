@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WebSocketTicketTTL is how long a one-time WebSocket ticket stays valid.
+const WebSocketTicketTTL = 30 * time.Second
+
+var ErrInvalidTicket = errors.New("invalid or expired ticket")
+
+// ChatIdentity is the user a WebSocket connection was authenticated as.
+// ExpiresAt is when the underlying token expires; zero means never.
+type ChatIdentity struct {
+	UserID    int
+	Username  string
+	ExpiresAt time.Time
+}
+
+// Authenticate validates an access token and returns the identity to bind
+// to a WebSocket connection.
+func (uc *ChatUseCase) Authenticate(token string) (*ChatIdentity, error) {
+	if token == "" {
+		return nil, errors.New("token not provided")
+	}
+	userID, username, expiresAt, err := uc.authUC.ParseTokenClaims(token)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatIdentity{UserID: int(userID), Username: username, ExpiresAt: expiresAt}, nil
+}
+
+// IssueTicket returns a short-lived ticket that can be exchanged once for a
+// WebSocket connection. Browsers cannot set headers on the upgrade request,
+// so this keeps the access token out of URLs.
+func (uc *ChatUseCase) IssueTicket(identity ChatIdentity) (string, time.Time, error) {
+	return uc.tickets.issue(identity, time.Now().Add(WebSocketTicketTTL))
+}
+
+// RedeemTicket consumes a ticket and returns the identity it was issued for.
+func (uc *ChatUseCase) RedeemTicket(ticket string) (*ChatIdentity, error) {
+	return uc.tickets.redeem(ticket)
+}
+
+type ticketEntry struct {
+	identity  ChatIdentity
+	expiresAt time.Time
+}
+
+// ticketStore keeps tickets in memory, so a ticket only works on the
+// instance that issued it.
+type ticketStore struct {
+	mu      sync.Mutex
+	tickets map[string]ticketEntry
+}
+
+func newTicketStore() *ticketStore {
+	return &ticketStore{tickets: make(map[string]ticketEntry)}
+}
+
+func (s *ticketStore) issue(identity ChatIdentity, expiresAt time.Time) (string, time.Time, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate ticket: %w", err)
+	}
+	ticket := base64.RawURLEncoding.EncodeToString(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Невостребованные билеты чистим здесь же, отдельная горутина не нужна
+	now := time.Now()
+	for t, entry := range s.tickets {
+		if now.After(entry.expiresAt) {
+			delete(s.tickets, t)
+		}
+	}
+	s.tickets[ticket] = ticketEntry{identity: identity, expiresAt: expiresAt}
+	return ticket, expiresAt, nil
+}
+
+func (s *ticketStore) redeem(ticket string) (*ChatIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.tickets[ticket]
+	if !ok {
+		return nil, ErrInvalidTicket
+	}
+	delete(s.tickets, ticket)
+	if time.Now().After(entry.expiresAt) {
+		return nil, ErrInvalidTicket
+	}
+	return &entry.identity, nil
+}
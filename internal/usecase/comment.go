@@ -2,28 +2,58 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 
 	"github.com/perfect1337/forum-service/internal/entity"
 )
 
+const (
+	DefaultCommentTreeDepth = 5
+	MaxCommentTreeDepth     = 50
+)
+
+var (
+	ErrInvalidParent    = errors.New("invalid parent comment")
+	ErrInvalidCommentID = errors.New("invalid comment ID")
+	ErrEmptyComment     = errors.New("comment content cannot be empty")
+)
+
 type CommentUseCase struct {
-	repo CommentRepository
+	repo   CommentRepository
+	events ChatBroadcaster // nil — новые комментарии никуда не транслируются
 }
 
 type CommentRepository interface {
+	GetPostByID(ctx context.Context, id int) (*entity.Post, error)
 	CreateComment(ctx context.Context, comment *entity.Comment) error
 	GetCommentsByPostID(ctx context.Context, postID int) ([]entity.Comment, error)
+	GetCommentByID(ctx context.Context, id int) (*entity.Comment, error)
+	UpdateComment(ctx context.Context, commentID, userID int, content string) (*entity.Comment, error)
 	DeleteComment(ctx context.Context, commentID int, userID int) error
+	VoteComment(ctx context.Context, commentID, userID, value int) (*entity.VoteResult, error)
 }
 type CommentUseCaseInterface interface {
 	CreateComment(ctx context.Context, comment *entity.Comment) error
 	GetCommentsByPostID(ctx context.Context, postID int) ([]entity.Comment, error)
+	GetCommentTree(ctx context.Context, postID, depth int, sort string) ([]*entity.Comment, error)
+	EditComment(ctx context.Context, postID, commentID, userID int, content string) (*entity.Comment, error)
 	DeleteComment(ctx context.Context, commentID, userID int) error
+	VoteComment(ctx context.Context, postID, commentID, userID, value int) (*entity.VoteResult, error)
 }
 
 func NewCommentUseCase(repo CommentRepository) *CommentUseCase {
-	return &CommentUseCase{repo: repo}
+	return NewCommentUseCaseWithEvents(repo, nil)
+}
+
+// NewCommentUseCaseWithEvents is NewCommentUseCase that also publishes every
+// new, edited and deleted comment to events, for the comments feed of event
+// streams and for clients watching the post.
+func NewCommentUseCaseWithEvents(repo CommentRepository, events ChatBroadcaster) *CommentUseCase {
+	return &CommentUseCase{repo: repo, events: events}
 }
 func (uc *CommentUseCase) CreateComment(ctx context.Context, comment *entity.Comment) error {
 	if comment == nil {
@@ -38,7 +68,71 @@ func (uc *CommentUseCase) CreateComment(ctx context.Context, comment *entity.Com
 	if comment.UserID == 0 {
 		return errors.New("user ID cannot be empty")
 	}
-	return uc.repo.CreateComment(ctx, comment)
+	// GetPostByID не видит посты в корзине: комментировать их нельзя
+	if _, err := uc.repo.GetPostByID(ctx, comment.PostID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: post %d", ErrNotFound, comment.PostID)
+		}
+		return err
+	}
+	if comment.ParentID != nil {
+		parent, err := uc.repo.GetCommentByID(ctx, *comment.ParentID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidParent, err)
+		}
+		if parent.PostID != comment.PostID {
+			return fmt.Errorf("%w: parent belongs to a different post", ErrInvalidParent)
+		}
+	}
+	if err := uc.repo.CreateComment(ctx, comment); err != nil {
+		return err
+	}
+	uc.publish(ctx, entity.ChatEvent{Type: entity.ChatEventCommentCreated, Comment: comment})
+	return nil
+}
+
+// publish sends a comment event to the hub. The change is already saved, so
+// a failure is only logged.
+func (uc *CommentUseCase) publish(ctx context.Context, event entity.ChatEvent) {
+	if uc.events == nil {
+		return
+	}
+	if err := uc.events.Publish(ctx, event); err != nil {
+		log.Printf("Error publishing %s: %v", event.Type, err)
+	}
+}
+
+// EditComment replaces the text of the user's own comment. A comment that
+// belongs to another post is reported as ErrNotFound.
+func (uc *CommentUseCase) EditComment(ctx context.Context, postID, commentID, userID int, content string) (*entity.Comment, error) {
+	if postID <= 0 {
+		return nil, ErrInvalidPostID
+	}
+	if commentID <= 0 {
+		return nil, ErrInvalidCommentID
+	}
+	if userID <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, ErrEmptyComment
+	}
+	existing, err := uc.repo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: comment %d", ErrNotFound, commentID)
+		}
+		return nil, err
+	}
+	if existing.PostID != postID {
+		return nil, fmt.Errorf("%w: comment %d on post %d", ErrNotFound, commentID, postID)
+	}
+	comment, err := uc.repo.UpdateComment(ctx, commentID, userID, content)
+	if err != nil {
+		return nil, err
+	}
+	uc.publish(ctx, entity.ChatEvent{Type: entity.ChatEventCommentEdited, Comment: comment})
+	return comment, nil
 }
 
 func (uc *CommentUseCase) GetCommentsByPostID(ctx context.Context, postID int) ([]entity.Comment, error) {
@@ -47,6 +141,75 @@ func (uc *CommentUseCase) GetCommentsByPostID(ctx context.Context, postID int) (
 	}
 	return uc.repo.GetCommentsByPostID(ctx, postID)
 }
+
+// GetCommentTree returns the root comments of a post with replies nested up
+// to depth levels. Comments on the last level carry the number of hidden
+// descendants in CollapsedCount instead of their replies. Siblings are
+// ordered by sort: "old" (default), "new", "top" or "hot".
+func (uc *CommentUseCase) GetCommentTree(ctx context.Context, postID, depth int, sort string) ([]*entity.Comment, error) {
+	if postID <= 0 {
+		return nil, errors.New("invalid post ID")
+	}
+	switch sort {
+	case "", entity.SortOld, entity.SortNew, entity.SortTop, entity.SortHot:
+	default:
+		return nil, fmt.Errorf("invalid sort %q: must be old, new, top or hot", sort)
+	}
+	if depth <= 0 {
+		depth = DefaultCommentTreeDepth
+	}
+	if depth > MaxCommentTreeDepth {
+		depth = MaxCommentTreeDepth
+	}
+
+	comments, err := uc.repo.GetCommentsByPostID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentTree(comments, depth, sort), nil
+}
+
+func buildCommentTree(comments []entity.Comment, depth int, order string) []*entity.Comment {
+	nodes := make(map[int]*entity.Comment, len(comments))
+	for i := range comments {
+		nodes[comments[i].ID] = &comments[i]
+	}
+
+	roots := make([]*entity.Comment, 0)
+	for i := range comments {
+		node := &comments[i]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		// Комментарии без родителя (или с недоступным родителем) становятся корнями
+		roots = append(roots, node)
+	}
+
+	sortComments(roots, order)
+	for _, root := range roots {
+		collapseCommentTree(root, 1, depth, order)
+	}
+	return roots
+}
+
+// collapseCommentTree sorts replies, cuts the subtree below maxDepth and
+// returns the total number of descendants of node.
+func collapseCommentTree(node *entity.Comment, level, maxDepth int, order string) int {
+	sortComments(node.Replies, order)
+	total := 0
+	for _, reply := range node.Replies {
+		total += 1 + collapseCommentTree(reply, level+1, maxDepth, order)
+	}
+	if level >= maxDepth && total > 0 {
+		node.Replies = nil
+		node.CollapsedCount = total
+	}
+	return total
+}
+
 func (uc *CommentUseCase) DeleteComment(ctx context.Context, commentID int, userID int) error {
 	if commentID <= 0 {
 		return errors.New("invalid comment ID")
@@ -54,5 +217,47 @@ func (uc *CommentUseCase) DeleteComment(ctx context.Context, commentID int, user
 	if userID <= 0 {
 		return errors.New("invalid user ID")
 	}
-	return uc.repo.DeleteComment(ctx, commentID, userID)
+	if uc.events == nil {
+		return uc.repo.DeleteComment(ctx, commentID, userID)
+	}
+
+	// Пост нужен только для адресата события: смотрят за постом, а не за
+	// комментарием.
+	comment, err := uc.repo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.DeleteComment(ctx, commentID, userID); err != nil {
+		return err
+	}
+	uc.publish(ctx, entity.ChatEvent{
+		Type:           entity.ChatEventCommentDeleted,
+		DeletedComment: &entity.DeletedComment{ID: comment.ID, PostID: comment.PostID},
+	})
+	return nil
+}
+
+// VoteComment votes on a comment of the post postID; a comment of another
+// post is reported as ErrNotFound, like a missing one.
+func (uc *CommentUseCase) VoteComment(ctx context.Context, postID, commentID, userID, value int) (*entity.VoteResult, error) {
+	if postID <= 0 {
+		return nil, ErrInvalidPostID
+	}
+	if commentID <= 0 {
+		return nil, ErrInvalidCommentID
+	}
+	if err := validateVote(value); err != nil {
+		return nil, err
+	}
+	comment, err := uc.repo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: comment %d", ErrNotFound, commentID)
+		}
+		return nil, err
+	}
+	if comment.PostID != postID {
+		return nil, fmt.Errorf("%w: comment %d on post %d", ErrNotFound, commentID, postID)
+	}
+	return uc.repo.VoteComment(ctx, commentID, userID, value)
 }
@@ -2,8 +2,11 @@ package usecase_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/perfect1337/forum-service/internal/entity"
 	"github.com/perfect1337/forum-service/internal/usecase"
@@ -16,6 +19,14 @@ type MockCommentRepository struct {
 	mock.Mock
 }
 
+func (m *MockCommentRepository) GetPostByID(ctx context.Context, id int) (*entity.Post, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Post), args.Error(1)
+}
+
 func (m *MockCommentRepository) CreateComment(ctx context.Context, comment *entity.Comment) error {
 	args := m.Called(ctx, comment)
 	return args.Error(0)
@@ -29,6 +40,30 @@ func (m *MockCommentRepository) GetCommentsByPostID(ctx context.Context, postID
 	return args.Get(0).([]entity.Comment), args.Error(1)
 }
 
+func (m *MockCommentRepository) GetCommentByID(ctx context.Context, id int) (*entity.Comment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) VoteComment(ctx context.Context, commentID, userID, value int) (*entity.VoteResult, error) {
+	args := m.Called(ctx, commentID, userID, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.VoteResult), args.Error(1)
+}
+
+func (m *MockCommentRepository) UpdateComment(ctx context.Context, commentID, userID int, content string) (*entity.Comment, error) {
+	args := m.Called(ctx, commentID, userID, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Comment), args.Error(1)
+}
+
 func (m *MockCommentRepository) DeleteComment(ctx context.Context, commentID, userID int) error {
 	args := m.Called(ctx, commentID, userID)
 	return args.Error(0)
@@ -40,6 +75,7 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 		comment     *entity.Comment
 		mockSetup   func(*MockCommentRepository)
 		expectedErr string
+		errIs       error
 	}{
 		{
 			name: "Success",
@@ -49,6 +85,7 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 				UserID:  1,
 			},
 			mockSetup: func(m *MockCommentRepository) {
+				m.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1}, nil)
 				m.On("CreateComment", mock.Anything, mock.AnythingOfType("*entity.Comment")).Return(nil)
 			},
 		},
@@ -90,11 +127,68 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 				UserID:  1,
 			},
 			mockSetup: func(m *MockCommentRepository) {
+				m.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1}, nil)
 				m.On("CreateComment", mock.Anything, mock.AnythingOfType("*entity.Comment")).
 					Return(errors.New("database error"))
 			},
 			expectedErr: "database error",
 		},
+		{
+			name: "ReplySuccess",
+			comment: &entity.Comment{
+				Content:  "Reply",
+				PostID:   1,
+				ParentID: intPtr(5),
+				UserID:   1,
+			},
+			mockSetup: func(m *MockCommentRepository) {
+				m.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1}, nil)
+				m.On("GetCommentByID", mock.Anything, 5).Return(&entity.Comment{ID: 5, PostID: 1}, nil)
+				m.On("CreateComment", mock.Anything, mock.AnythingOfType("*entity.Comment")).Return(nil)
+			},
+		},
+		{
+			name: "ReplyToOtherPost",
+			comment: &entity.Comment{
+				Content:  "Reply",
+				PostID:   1,
+				ParentID: intPtr(5),
+				UserID:   1,
+			},
+			mockSetup: func(m *MockCommentRepository) {
+				m.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1}, nil)
+				m.On("GetCommentByID", mock.Anything, 5).Return(&entity.Comment{ID: 5, PostID: 2}, nil)
+			},
+			expectedErr: "parent belongs to a different post",
+		},
+		{
+			name: "ReplyParentNotFound",
+			comment: &entity.Comment{
+				Content:  "Reply",
+				PostID:   1,
+				ParentID: intPtr(5),
+				UserID:   1,
+			},
+			mockSetup: func(m *MockCommentRepository) {
+				m.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1}, nil)
+				m.On("GetCommentByID", mock.Anything, 5).Return(nil, errors.New("not found"))
+			},
+			expectedErr: "invalid parent comment",
+		},
+		{
+			name: "PostInTrash",
+			comment: &entity.Comment{
+				Content: "Test content",
+				PostID:  1,
+				UserID:  1,
+			},
+			mockSetup: func(m *MockCommentRepository) {
+				m.On("GetPostByID", mock.Anything, 1).
+					Return(nil, fmt.Errorf("failed to get post by ID: %w", sql.ErrNoRows))
+			},
+			expectedErr: "not found",
+			errIs:       usecase.ErrNotFound,
+		},
 		{
 			name:        "NilComment",
 			comment:     nil,
@@ -118,6 +212,9 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 			}
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
 
 			repo.AssertExpectations(t)
 		})
@@ -214,6 +311,117 @@ func TestCommentUseCase_GetCommentsByPostID(t *testing.T) {
 	}
 }
 
+func intPtr(v int) *int { return &v }
+
+func TestCommentUseCase_GetCommentTree(t *testing.T) {
+	// 1
+	// ├── 2
+	// │   └── 4
+	// │       └── 5
+	// └── 3
+	// 6
+	flat := func() []entity.Comment {
+		return []entity.Comment{
+			{ID: 1, PostID: 1},
+			{ID: 2, PostID: 1, ParentID: intPtr(1)},
+			{ID: 3, PostID: 1, ParentID: intPtr(1)},
+			{ID: 4, PostID: 1, ParentID: intPtr(2)},
+			{ID: 5, PostID: 1, ParentID: intPtr(4)},
+			{ID: 6, PostID: 1},
+		}
+	}
+
+	t.Run("FullTree", func(t *testing.T) {
+		repo := new(MockCommentRepository)
+		repo.On("GetCommentsByPostID", mock.Anything, 1).Return(flat(), nil)
+		uc := usecase.NewCommentUseCase(repo)
+
+		roots, err := uc.GetCommentTree(context.Background(), 1, 0, "")
+		require.NoError(t, err)
+		require.Len(t, roots, 2)
+		assert.Equal(t, 1, roots[0].ID)
+		assert.Equal(t, 6, roots[1].ID)
+		require.Len(t, roots[0].Replies, 2)
+		assert.Equal(t, 5, roots[0].Replies[0].Replies[0].Replies[0].ID)
+		assert.Zero(t, roots[0].CollapsedCount)
+	})
+
+	t.Run("DepthLimit", func(t *testing.T) {
+		repo := new(MockCommentRepository)
+		repo.On("GetCommentsByPostID", mock.Anything, 1).Return(flat(), nil)
+		uc := usecase.NewCommentUseCase(repo)
+
+		roots, err := uc.GetCommentTree(context.Background(), 1, 2, "")
+		require.NoError(t, err)
+		require.Len(t, roots[0].Replies, 2)
+
+		comment2 := roots[0].Replies[0]
+		assert.Equal(t, 2, comment2.ID)
+		assert.Nil(t, comment2.Replies)
+		assert.Equal(t, 2, comment2.CollapsedCount)
+		assert.Zero(t, roots[0].Replies[1].CollapsedCount)
+	})
+
+	t.Run("OrphanBecomesRoot", func(t *testing.T) {
+		repo := new(MockCommentRepository)
+		repo.On("GetCommentsByPostID", mock.Anything, 1).
+			Return([]entity.Comment{{ID: 7, PostID: 1, ParentID: intPtr(99)}}, nil)
+		uc := usecase.NewCommentUseCase(repo)
+
+		roots, err := uc.GetCommentTree(context.Background(), 1, 0, "")
+		require.NoError(t, err)
+		require.Len(t, roots, 1)
+		assert.Equal(t, 7, roots[0].ID)
+	})
+
+	t.Run("SortTop", func(t *testing.T) {
+		now := time.Now()
+		repo := new(MockCommentRepository)
+		repo.On("GetCommentsByPostID", mock.Anything, 1).Return([]entity.Comment{
+			{ID: 1, PostID: 1, Score: 1, CreatedAt: now.Add(-time.Hour)},
+			{ID: 2, PostID: 1, Score: 10, CreatedAt: now.Add(-2 * time.Hour)},
+			{ID: 3, PostID: 1, ParentID: intPtr(1), Score: -2, CreatedAt: now},
+			{ID: 4, PostID: 1, ParentID: intPtr(1), Score: 4, CreatedAt: now},
+		}, nil)
+		uc := usecase.NewCommentUseCase(repo)
+
+		roots, err := uc.GetCommentTree(context.Background(), 1, 0, entity.SortTop)
+		require.NoError(t, err)
+		assert.Equal(t, 2, roots[0].ID)
+		assert.Equal(t, 1, roots[1].ID)
+		assert.Equal(t, 4, roots[1].Replies[0].ID)
+		assert.Equal(t, 3, roots[1].Replies[1].ID)
+	})
+
+	t.Run("SortHotDecaysByAge", func(t *testing.T) {
+		now := time.Now()
+		repo := new(MockCommentRepository)
+		repo.On("GetCommentsByPostID", mock.Anything, 1).Return([]entity.Comment{
+			{ID: 1, PostID: 1, Score: 20, CreatedAt: now.Add(-72 * time.Hour)},
+			{ID: 2, PostID: 1, Score: 2, CreatedAt: now},
+		}, nil)
+		uc := usecase.NewCommentUseCase(repo)
+
+		roots, err := uc.GetCommentTree(context.Background(), 1, 0, entity.SortHot)
+		require.NoError(t, err)
+		assert.Equal(t, 2, roots[0].ID)
+	})
+
+	t.Run("InvalidSort", func(t *testing.T) {
+		uc := usecase.NewCommentUseCase(new(MockCommentRepository))
+
+		_, err := uc.GetCommentTree(context.Background(), 1, 0, "best")
+		assert.ErrorContains(t, err, "invalid sort")
+	})
+
+	t.Run("InvalidPostID", func(t *testing.T) {
+		uc := usecase.NewCommentUseCase(new(MockCommentRepository))
+
+		_, err := uc.GetCommentTree(context.Background(), 0, 0, "")
+		assert.EqualError(t, err, "invalid post ID")
+	})
+}
+
 func TestCommentUseCase_DeleteComment(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -284,6 +492,61 @@ func TestCommentUseCase_DeleteComment(t *testing.T) {
 	}
 }
 
+func TestCommentUseCase_EditComment(t *testing.T) {
+	repo := new(MockCommentRepository)
+	uc := usecase.NewCommentUseCase(repo)
+	repo.On("GetCommentByID", mock.Anything, 3).Return(&entity.Comment{ID: 3, PostID: 10}, nil)
+	repo.On("UpdateComment", mock.Anything, 3, 1, "Updated").Return(&entity.Comment{ID: 3, Content: "Updated"}, nil)
+	repo.On("UpdateComment", mock.Anything, 3, 2, "Hijacked").Return(nil, sql.ErrNoRows)
+
+	comment, err := uc.EditComment(context.Background(), 10, 3, 1, "Updated")
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", comment.Content)
+
+	_, err = uc.EditComment(context.Background(), 10, 3, 2, "Hijacked")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Комментарий из другого поста не редактируется через чужой URL
+	_, err = uc.EditComment(context.Background(), 11, 3, 1, "Updated")
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+	repo.AssertNumberOfCalls(t, "UpdateComment", 2)
+
+	_, err = uc.EditComment(context.Background(), 10, 3, 1, "   ")
+	assert.ErrorIs(t, err, usecase.ErrEmptyComment)
+
+	_, err = uc.EditComment(context.Background(), 10, 0, 1, "Updated")
+	assert.ErrorIs(t, err, usecase.ErrInvalidCommentID)
+	repo.AssertExpectations(t)
+}
+
+func TestCommentUseCase_VoteComment(t *testing.T) {
+	repo := new(MockCommentRepository)
+	uc := usecase.NewCommentUseCase(repo)
+	repo.On("GetCommentByID", mock.Anything, 3).Return(&entity.Comment{ID: 3, PostID: 7}, nil)
+	repo.On("GetCommentByID", mock.Anything, 4).Return(nil, fmt.Errorf("failed to get comment by ID: %w", sql.ErrNoRows))
+	repo.On("VoteComment", mock.Anything, 3, 1, 1).Return(&entity.VoteResult{Vote: 1, Score: 2}, nil)
+
+	result, err := uc.VoteComment(context.Background(), 7, 3, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Score)
+
+	_, err = uc.VoteComment(context.Background(), 7, 3, 1, -5)
+	assert.ErrorIs(t, err, usecase.ErrInvalidVote)
+	_, err = uc.VoteComment(context.Background(), 0, 3, 1, 1)
+	assert.ErrorIs(t, err, usecase.ErrInvalidPostID)
+	_, err = uc.VoteComment(context.Background(), 7, 0, 1, 1)
+	assert.ErrorIs(t, err, usecase.ErrInvalidCommentID)
+
+	// Комментарий другого поста и отсутствующий комментарий — NotFound
+	_, err = uc.VoteComment(context.Background(), 8, 3, 1, 1)
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+	_, err = uc.VoteComment(context.Background(), 7, 4, 1, 1)
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+
+	repo.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "VoteComment", 1)
+}
+
 func TestNewCommentUseCase(t *testing.T) {
 	repo := new(MockCommentRepository)
 	uc := usecase.NewCommentUseCase(repo)
@@ -291,6 +554,7 @@ func TestNewCommentUseCase(t *testing.T) {
 	assert.NotNil(t, uc)
 	// We can't test the repo field directly since it's unexported
 	// Instead we can test behavior by verifying mock calls
+	repo.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1}, nil)
 	repo.On("CreateComment", mock.Anything, mock.Anything).Return(nil)
 	err := uc.CreateComment(context.Background(), &entity.Comment{
 		Content: "test",
@@ -300,3 +564,25 @@ func TestNewCommentUseCase(t *testing.T) {
 	assert.NoError(t, err)
 	repo.AssertExpectations(t)
 }
+
+func TestCommentUseCase_DeleteCommentPublishes(t *testing.T) {
+	repo := new(MockCommentRepository)
+	broadcaster := usecase.NewMemoryChatBroadcaster()
+	events := make(chan entity.ChatEvent, 1)
+	broadcaster.Subscribe(func(event entity.ChatEvent) { events <- event })
+	uc := usecase.NewCommentUseCaseWithEvents(repo, broadcaster)
+
+	repo.On("GetCommentByID", mock.Anything, 3).Return(&entity.Comment{ID: 3, PostID: 9}, nil)
+	repo.On("DeleteComment", mock.Anything, 3, 1).Return(nil)
+	repo.On("GetCommentByID", mock.Anything, 4).Return(nil, sql.ErrNoRows)
+
+	require.NoError(t, uc.DeleteComment(context.Background(), 3, 1))
+	event := <-events
+	assert.Equal(t, entity.ChatEventCommentDeleted, event.Type)
+	assert.Equal(t, &entity.DeletedComment{ID: 3, PostID: 9}, event.DeletedComment)
+
+	// Не найден — ничего не удаляем и не публикуем.
+	assert.ErrorIs(t, uc.DeleteComment(context.Background(), 4, 1), sql.ErrNoRows)
+	assert.Empty(t, events)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+// maxDiffCells caps the LCS table size. Texts that would need a bigger table
+// are reported as a whole replacement instead.
+const maxDiffCells = 4_000_000
+
+// diffLines returns a line-based diff that turns a into b.
+func diffLines(a, b string) []entity.DiffLine {
+	from := splitLines(a)
+	to := splitLines(b)
+
+	// Общие начало и конец отрезаем заранее: обычная правка затрагивает
+	// несколько строк, и таблица LCS остаётся маленькой.
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix &&
+		from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]entity.DiffLine, 0, len(from)+len(to))
+	for _, line := range from[:prefix] {
+		diff = append(diff, entity.DiffLine{Op: entity.DiffEqual, Text: line})
+	}
+	diff = append(diff, diffMiddle(from[prefix:len(from)-suffix], to[prefix:len(to)-suffix])...)
+	for _, line := range from[len(from)-suffix:] {
+		diff = append(diff, entity.DiffLine{Op: entity.DiffEqual, Text: line})
+	}
+	return diff
+}
+
+func diffMiddle(from, to []string) []entity.DiffLine {
+	var diff []entity.DiffLine
+	if (len(from)+1)*(len(to)+1) > maxDiffCells {
+		for _, line := range from {
+			diff = append(diff, entity.DiffLine{Op: entity.DiffDelete, Text: line})
+		}
+		for _, line := range to {
+			diff = append(diff, entity.DiffLine{Op: entity.DiffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] — длина общей подпоследовательности from[i:] и to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			diff = append(diff, entity.DiffLine{Op: entity.DiffEqual, Text: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, entity.DiffLine{Op: entity.DiffDelete, Text: from[i]})
+			i++
+		default:
+			diff = append(diff, entity.DiffLine{Op: entity.DiffInsert, Text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		diff = append(diff, entity.DiffLine{Op: entity.DiffDelete, Text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		diff = append(diff, entity.DiffLine{Op: entity.DiffInsert, Text: to[j]})
+	}
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -2,8 +2,13 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/perfect1337/forum-service/internal/entity"
@@ -12,25 +17,56 @@ import (
 type PostUseCase interface {
 	CreatePost(ctx context.Context, post *entity.Post) error
 	GetPostByID(ctx context.Context, id int) (*entity.Post, error)
-	GetAllPosts(ctx context.Context) ([]*entity.Post, error)
+	GetPostsByIDs(ctx context.Context, ids []int) ([]*entity.Post, error)
+	GetAllPosts(ctx context.Context, filter entity.PostFilter) (*entity.PostPage, error)
 	DeletePost(ctx context.Context, postID, userID int) error
 	UpdatePost(ctx context.Context, postID int, userID int, title, content string) error
+	VotePost(ctx context.Context, postID, userID, value int) (*entity.VoteResult, error)
+	GetPostRevisions(ctx context.Context, postID int) ([]*entity.PostRevision, error)
+	GetPostRevision(ctx context.Context, postID, revision int) (*entity.PostRevision, error)
+	DiffPostRevisions(ctx context.Context, postID, from, to int) (*entity.RevisionDiff, error)
+	RestorePostRevision(ctx context.Context, postID, revision, userID int) error
+	UpdatePostTaxonomy(ctx context.Context, postID, userID int, categoryID *int, tags []string) error
 }
 
 type PostRepository interface {
 	CreatePost(ctx context.Context, post *entity.Post) error
 	GetPostByID(ctx context.Context, id int) (*entity.Post, error)
-	GetAllPosts(ctx context.Context) ([]*entity.Post, error)
-	DeletePost(ctx context.Context, id int) error
-	UpdatePost(ctx context.Context, postID int, title, content string) error
+	GetPostsByIDs(ctx context.Context, ids []int) ([]*entity.Post, error)
+	GetAllPosts(ctx context.Context, filter entity.PostFilter) ([]*entity.Post, error)
+	DeletePost(ctx context.Context, id, deletedBy int) error
+	UpdatePost(ctx context.Context, postID, editorID int, title, content string) error
+	VotePost(ctx context.Context, postID, userID, value int) (*entity.VoteResult, error)
+	GetPostRevisions(ctx context.Context, postID int) ([]*entity.PostRevision, error)
+	GetPostRevision(ctx context.Context, postID, revision int) (*entity.PostRevision, error)
+	GetCategoryByID(ctx context.Context, id int) (*entity.Category, error)
+	SetPostCategory(ctx context.Context, postID int, categoryID *int) error
+	ReplacePostTags(ctx context.Context, postID int, tags []string) error
 }
 
+const (
+	DefaultPostPageSize = 20
+	MaxPostPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrTooManyPostIDs = errors.New("too many post IDs")
+	// ErrInvalidPostFilter is returned for an unknown sort or order.
+	ErrInvalidPostFilter = errors.New("invalid post filter")
+	// ErrNotPostAuthor is returned when someone other than the author or an
+	// admin changes a post. Its message keeps the "unauthorized: ..." text
+	// clients already match on.
+	ErrNotPostAuthor = errors.New("unauthorized")
+)
+
 type UserRepository interface {
 	GetUserByID(ctx context.Context, id int) (*entity.User, error)
 }
 type PostService struct {
 	postRepo PostRepository
 	userRepo UserRepository
+	events   ChatBroadcaster // nil — новые посты никуда не транслируются
 }
 
 type JWTClaims struct {
@@ -54,10 +90,10 @@ func (s *PostService) DeletePost(ctx context.Context, postID, userID int) error
 	fmt.Printf("Debug: post.UserID=%d, userID=%d, user.Role=%s\n", post.UserID, userID, user.Role)
 
 	if post.UserID != userID && user.Role != "admin" {
-		return errors.New("unauthorized: you can only delete your own posts")
+		return fmt.Errorf("%w: you can only delete your own posts", ErrNotPostAuthor)
 	}
 
-	return s.postRepo.DeletePost(ctx, postID)
+	return s.postRepo.DeletePost(ctx, postID, userID)
 }
 func (s *PostService) CreatePost(ctx context.Context, post *entity.Post) error {
 	if post == nil {
@@ -72,15 +108,161 @@ func (s *PostService) CreatePost(ctx context.Context, post *entity.Post) error {
 	if post.UserID == 0 {
 		return errors.New("user ID cannot be empty")
 	}
-	return s.postRepo.CreatePost(ctx, post)
+	if post.CategoryID != nil && *post.CategoryID == 0 {
+		post.CategoryID = nil
+	}
+	tags, err := normalizeTags(post.Tags)
+	if err != nil {
+		return err
+	}
+	post.Tags = tags
+	if err := s.checkCategory(ctx, post.CategoryID); err != nil {
+		return err
+	}
+	if err := s.postRepo.CreatePost(ctx, post); err != nil {
+		return err
+	}
+	if s.events != nil {
+		if err := s.events.Publish(ctx, entity.ChatEvent{Type: entity.ChatEventPostCreated, Post: post}); err != nil {
+			log.Printf("Error publishing new post: %v", err)
+		}
+	}
+	return nil
 }
 
 func (s *PostService) GetPostByID(ctx context.Context, id int) (*entity.Post, error) {
 	return s.postRepo.GetPostByID(ctx, id)
 }
 
-func (s *PostService) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
-	return s.postRepo.GetAllPosts(ctx)
+// GetPostsByIDs returns the posts with the given IDs in the order they were
+// asked for. Missing and trashed posts are left out, duplicates are returned
+// once, and at most MaxPostPageSize IDs may be asked for at a time.
+func (s *PostService) GetPostsByIDs(ctx context.Context, ids []int) ([]*entity.Post, error) {
+	if len(ids) > MaxPostPageSize {
+		return nil, fmt.Errorf("%w: at most %d", ErrTooManyPostIDs, MaxPostPageSize)
+	}
+	unique := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return []*entity.Post{}, nil
+	}
+
+	found, err := s.postRepo.GetPostsByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*entity.Post, len(found))
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+	posts := make([]*entity.Post, 0, len(found))
+	for _, id := range unique {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+// GetAllPosts returns a page of posts and an opaque cursor for the next one.
+func (s *PostService) GetAllPosts(ctx context.Context, filter entity.PostFilter) (*entity.PostPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPostPageSize
+	}
+	if filter.Limit > MaxPostPageSize {
+		filter.Limit = MaxPostPageSize
+	}
+	if filter.Order != "" && filter.Order != "asc" && filter.Order != "desc" {
+		return nil, fmt.Errorf("%w: invalid order %q: must be asc or desc", ErrInvalidPostFilter, filter.Order)
+	}
+	switch filter.Sort {
+	case "":
+		filter.Sort = entity.SortNew
+	case entity.SortNew, entity.SortTop, entity.SortHot:
+	default:
+		return nil, fmt.Errorf("%w: invalid sort %q: must be new, top or hot", ErrInvalidPostFilter, filter.Sort)
+	}
+	// Теги хранятся в нижнем регистре, фильтр приводим к тому же виду
+	filter.Tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(filter.Tag), "#"))
+	if filter.Cursor != "" {
+		cursor, err := decodePostCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		// Курсор от другой сортировки указывает на чужую позицию в ленте
+		if cursor.Sort != filter.Sort {
+			return nil, ErrInvalidCursor
+		}
+		filter.After = cursor
+	}
+
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1 // лишняя строка показывает, есть ли следующая страница
+	posts, err := s.postRepo.GetAllPosts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.PostPage{Posts: posts}
+	if len(posts) > pageSize {
+		page.Posts = posts[:pageSize]
+		last := page.Posts[pageSize-1]
+		page.NextCursor = encodePostCursor(entity.PostCursor{
+			Sort:      filter.Sort,
+			CreatedAt: last.CreatedAt,
+			Score:     last.Score,
+			ID:        last.ID,
+		})
+	}
+	if page.Posts == nil {
+		page.Posts = []*entity.Post{}
+	}
+	return page, nil
+}
+
+func encodePostCursor(c entity.PostCursor) string {
+	raw := strings.Join([]string{
+		c.Sort,
+		strconv.FormatInt(c.CreatedAt.UnixNano(), 10),
+		strconv.Itoa(c.Score),
+		strconv.Itoa(c.ID),
+	}, ":")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePostCursor(s string) (*entity.PostCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 4 {
+		return nil, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	score, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	postID, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &entity.PostCursor{
+		Sort:      parts[0],
+		CreatedAt: time.Unix(0, nanos).UTC(),
+		Score:     score,
+		ID:        postID,
+	}, nil
 }
 
 func (s *PostService) UpdatePost(ctx context.Context, postID int, userID int, title, content string) error {
@@ -93,14 +275,95 @@ func (s *PostService) UpdatePost(ctx context.Context, postID int, userID int, ti
 		return err
 	}
 	if post.UserID != userID && user.Role != "admin" {
-		return errors.New("unauthorized: you can only update your own posts")
+		return fmt.Errorf("%w: you can only update your own posts", ErrNotPostAuthor)
+	}
+	// Пустое поле не передано клиентом: оставляем текущее значение, чтобы
+	// не затереть его и не записать ревизию с пустым полем
+	if title == "" {
+		title = post.Title
 	}
-	return s.postRepo.UpdatePost(ctx, postID, title, content)
+	if content == "" {
+		content = post.Content
+	}
+	return s.postRepo.UpdatePost(ctx, postID, userID, title, content)
+}
+
+// UpdatePostTaxonomy changes the category and tags of a post. A nil
+// categoryID or tags leaves that part as is; categoryID 0 removes the
+// category and an empty tags slice removes all tags. Only the owner or an
+// admin may change them.
+func (s *PostService) UpdatePostTaxonomy(ctx context.Context, postID, userID int, categoryID *int, tags []string) error {
+	if categoryID == nil && tags == nil {
+		return nil
+	}
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	var category *int
+	if categoryID != nil && *categoryID != 0 {
+		category = categoryID
+	}
+	if err := s.checkCategory(ctx, category); err != nil {
+		return err
+	}
+
+	post, err := s.postRepo.GetPostByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if post.UserID != userID && user.Role != "admin" {
+		return fmt.Errorf("%w: you can only update your own posts", ErrNotPostAuthor)
+	}
+
+	if categoryID != nil {
+		if err := s.postRepo.SetPostCategory(ctx, postID, category); err != nil {
+			return err
+		}
+	}
+	if tags != nil {
+		return s.postRepo.ReplacePostTags(ctx, postID, tags)
+	}
+	return nil
+}
+
+func (s *PostService) checkCategory(ctx context.Context, categoryID *int) error {
+	if categoryID == nil {
+		return nil
+	}
+	if _, err := s.postRepo.GetCategoryByID(ctx, *categoryID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: category %d does not exist", ErrInvalidCategory, *categoryID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PostService) VotePost(ctx context.Context, postID, userID, value int) (*entity.VoteResult, error) {
+	if postID <= 0 {
+		return nil, ErrInvalidPostID
+	}
+	if err := validateVote(value); err != nil {
+		return nil, err
+	}
+	return s.postRepo.VotePost(ctx, postID, userID, value)
 }
 
 func NewPostUseCase(postRepo PostRepository, userRepo UserRepository) PostUseCase {
+	return NewPostUseCaseWithEvents(postRepo, userRepo, nil)
+}
+
+// NewPostUseCaseWithEvents is NewPostUseCase that also publishes every new
+// post to events, for the posts feed of event streams.
+func NewPostUseCaseWithEvents(postRepo PostRepository, userRepo UserRepository, events ChatBroadcaster) PostUseCase {
 	return &PostService{
 		postRepo: postRepo,
 		userRepo: userRepo,
+		events:   events,
 	}
 }
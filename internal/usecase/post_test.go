@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/perfect1337/forum-service/internal/entity"
 	"github.com/perfect1337/forum-service/internal/usecase"
@@ -20,10 +21,44 @@ type MockPostRepository struct {
 	mock.Mock
 }
 
-func (m *MockPostRepository) UpdatePost(ctx context.Context, postID int, title, content string) error {
-	args := m.Called(ctx, postID, title, content)
+func (m *MockPostRepository) UpdatePost(ctx context.Context, postID, editorID int, title, content string) error {
+	args := m.Called(ctx, postID, editorID, title, content)
 	return args.Error(0)
 }
+
+func (m *MockPostRepository) GetPostRevisions(ctx context.Context, postID int) ([]*entity.PostRevision, error) {
+	args := m.Called(ctx, postID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.PostRevision), args.Error(1)
+}
+
+func (m *MockPostRepository) GetPostRevision(ctx context.Context, postID, revision int) (*entity.PostRevision, error) {
+	args := m.Called(ctx, postID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PostRevision), args.Error(1)
+}
+func (m *MockPostRepository) GetCategoryByID(ctx context.Context, id int) (*entity.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Category), args.Error(1)
+}
+
+func (m *MockPostRepository) SetPostCategory(ctx context.Context, postID int, categoryID *int) error {
+	args := m.Called(ctx, postID, categoryID)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) ReplacePostTags(ctx context.Context, postID int, tags []string) error {
+	args := m.Called(ctx, postID, tags)
+	return args.Error(0)
+}
+
 func (m *MockPostRepository) CreatePost(ctx context.Context, post *entity.Post) error {
 	args := m.Called(ctx, post)
 	return args.Error(0)
@@ -37,15 +72,31 @@ func (m *MockPostRepository) GetPostByID(ctx context.Context, id int) (*entity.P
 	return args.Get(0).(*entity.Post), args.Error(1)
 }
 
-func (m *MockPostRepository) GetAllPosts(ctx context.Context) ([]*entity.Post, error) {
-	args := m.Called(ctx)
+func (m *MockPostRepository) GetPostsByIDs(ctx context.Context, ids []int) ([]*entity.Post, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) GetAllPosts(ctx context.Context, filter entity.PostFilter) ([]*entity.Post, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return []*entity.Post{}, args.Error(1) // Возвращаем пустой срез в случае ошибки
 	}
 	return args.Get(0).([]*entity.Post), args.Error(1)
 }
-func (m *MockPostRepository) DeletePost(ctx context.Context, id int) error {
-	args := m.Called(ctx, id)
+func (m *MockPostRepository) VotePost(ctx context.Context, postID, userID, value int) (*entity.VoteResult, error) {
+	args := m.Called(ctx, postID, userID, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.VoteResult), args.Error(1)
+}
+
+func (m *MockPostRepository) DeletePost(ctx context.Context, id, deletedBy int) error {
+	args := m.Called(ctx, id, deletedBy)
 	return args.Error(0)
 }
 
@@ -161,6 +212,7 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 		userID      int
 		mockSetup   func(*MockPostRepository, *MockUserRepository)
 		expectedErr string
+		notAuthor   bool
 	}{
 		{
 			name:   "Unauthorized",
@@ -171,6 +223,7 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 				ur.On("GetUserByID", mock.Anything, 1).Return(&entity.User{ID: 1, Role: "user"}, nil)
 			},
 			expectedErr: "unauthorized: you can only delete your own posts",
+			notAuthor:   true,
 		},
 		{
 			name:   "SuccessAdmin",
@@ -179,7 +232,7 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 			mockSetup: func(pr *MockPostRepository, ur *MockUserRepository) {
 				pr.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, UserID: 2}, nil)
 				ur.On("GetUserByID", mock.Anything, 1).Return(&entity.User{ID: 1, Role: "admin"}, nil)
-				pr.On("DeletePost", mock.Anything, 1).Return(nil)
+				pr.On("DeletePost", mock.Anything, 1, 1).Return(nil)
 			},
 		},
 		{
@@ -189,7 +242,7 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 			mockSetup: func(pr *MockPostRepository, ur *MockUserRepository) {
 				pr.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, UserID: 1}, nil)
 				ur.On("GetUserByID", mock.Anything, 1).Return(&entity.User{ID: 1, Role: "user"}, nil)
-				pr.On("DeletePost", mock.Anything, 1).Return(nil)
+				pr.On("DeletePost", mock.Anything, 1, 1).Return(nil)
 			},
 		},
 		{
@@ -227,6 +280,7 @@ func TestPostUseCase_DeletePost(t *testing.T) {
 			if tt.expectedErr != "" {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Equal(t, tt.notAuthor, errors.Is(err, usecase.ErrNotPostAuthor))
 			} else {
 				require.NoError(t, err)
 			}
@@ -285,17 +339,55 @@ func TestPostUseCase_GetPostByID(t *testing.T) {
 		})
 	}
 }
+
+func TestPostUseCase_GetPostsByIDs(t *testing.T) {
+	t.Run("Порядок запроса, без дубликатов", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+		mockPostRepo.On("GetPostsByIDs", mock.Anything, []int{3, 1, 2}).
+			Return([]*entity.Post{{ID: 1}, {ID: 3}}, nil)
+
+		posts, err := uc.GetPostsByIDs(context.Background(), []int{3, 1, 3, 0, 2})
+		require.NoError(t, err)
+		require.Len(t, posts, 2)
+		assert.Equal(t, 3, posts[0].ID)
+		assert.Equal(t, 1, posts[1].ID)
+		mockPostRepo.AssertExpectations(t)
+	})
+
+	t.Run("Пустой запрос не идёт в базу", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+
+		posts, err := uc.GetPostsByIDs(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, posts)
+		mockPostRepo.AssertNotCalled(t, "GetPostsByIDs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Слишком много ID", func(t *testing.T) {
+		uc := usecase.NewPostUseCase(new(MockPostRepository), new(MockUserRepository))
+		_, err := uc.GetPostsByIDs(context.Background(), make([]int, usecase.MaxPostPageSize+1))
+		assert.ErrorIs(t, err, usecase.ErrTooManyPostIDs)
+	})
+}
+
 func TestPostUseCase_GetAllPosts(t *testing.T) {
 	tests := []struct {
 		name          string
+		filter        entity.PostFilter
 		mockSetup     func(*MockPostRepository)
 		expectedErr   string
+		errIs         error
 		expectedPosts []*entity.Post
+		hasNext       bool
 	}{
 		{
 			name: "Success",
 			mockSetup: func(pr *MockPostRepository) {
-				pr.On("GetAllPosts", mock.Anything).Return([]*entity.Post{
+				pr.On("GetAllPosts", mock.Anything, mock.MatchedBy(func(f entity.PostFilter) bool {
+					return f.Limit == usecase.DefaultPostPageSize+1 && f.After == nil
+				})).Return([]*entity.Post{
 					{ID: 1, Title: "Test Post 1", Content: "Test Content 1", UserID: 1},
 					{ID: 2, Title: "Test Post 2", Content: "Test Content 2", UserID: 2},
 				}, nil)
@@ -305,10 +397,46 @@ func TestPostUseCase_GetAllPosts(t *testing.T) {
 				{ID: 2, Title: "Test Post 2", Content: "Test Content 2", UserID: 2},
 			},
 		},
+		{
+			name:   "HasNextPage",
+			filter: entity.PostFilter{Limit: 1},
+			mockSetup: func(pr *MockPostRepository) {
+				pr.On("GetAllPosts", mock.Anything, mock.MatchedBy(func(f entity.PostFilter) bool {
+					return f.Limit == 2
+				})).Return([]*entity.Post{
+					{ID: 2, Title: "Test Post 2", UserID: 2, CreatedAt: time.Unix(200, 0)},
+					{ID: 1, Title: "Test Post 1", UserID: 1, CreatedAt: time.Unix(100, 0)},
+				}, nil)
+			},
+			expectedPosts: []*entity.Post{
+				{ID: 2, Title: "Test Post 2", UserID: 2, CreatedAt: time.Unix(200, 0)},
+			},
+			hasNext: true,
+		},
+		{
+			name:        "InvalidCursor",
+			filter:      entity.PostFilter{Cursor: "not-a-cursor"},
+			mockSetup:   func(pr *MockPostRepository) {},
+			expectedErr: "invalid cursor",
+		},
+		{
+			name:        "InvalidSort",
+			filter:      entity.PostFilter{Sort: "best"},
+			mockSetup:   func(pr *MockPostRepository) {},
+			expectedErr: "invalid sort",
+			errIs:       usecase.ErrInvalidPostFilter,
+		},
+		{
+			name:        "InvalidOrder",
+			filter:      entity.PostFilter{Order: "sideways"},
+			mockSetup:   func(pr *MockPostRepository) {},
+			expectedErr: "invalid order",
+			errIs:       usecase.ErrInvalidPostFilter,
+		},
 		{
 			name: "RepositoryError",
 			mockSetup: func(pr *MockPostRepository) {
-				pr.On("GetAllPosts", mock.Anything).Return(nil, errors.New("database error"))
+				pr.On("GetAllPosts", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
 			},
 			expectedErr: "database error",
 		},
@@ -323,17 +451,103 @@ func TestPostUseCase_GetAllPosts(t *testing.T) {
 			// Setup mocks
 			tt.mockSetup(mockPostRepo)
 
-			posts, err := uc.GetAllPosts(context.Background())
+			page, err := uc.GetAllPosts(context.Background(), tt.filter)
 
 			if tt.expectedErr != "" {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedErr)
+				if tt.errIs != nil {
+					assert.ErrorIs(t, err, tt.errIs)
+				}
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, tt.expectedPosts, posts)
+				assert.Equal(t, tt.expectedPosts, page.Posts)
+				assert.Equal(t, tt.hasNext, page.NextCursor != "")
 			}
 
 			mockPostRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestPostUseCase_GetAllPosts_CursorRoundTrip(t *testing.T) {
+	mockPostRepo := new(MockPostRepository)
+	mockUserRepo := new(MockUserRepository)
+	uc := usecase.NewPostUseCase(mockPostRepo, mockUserRepo)
+
+	createdAt := time.Date(2025, 5, 1, 12, 0, 0, 42, time.UTC)
+	mockPostRepo.On("GetAllPosts", mock.Anything, mock.MatchedBy(func(f entity.PostFilter) bool {
+		return f.After == nil
+	})).Return([]*entity.Post{
+		{ID: 7, CreatedAt: createdAt},
+		{ID: 6, CreatedAt: createdAt.Add(-time.Hour)},
+	}, nil).Once()
+
+	page, err := uc.GetAllPosts(context.Background(), entity.PostFilter{Limit: 1})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.NextCursor)
+
+	mockPostRepo.On("GetAllPosts", mock.Anything, mock.MatchedBy(func(f entity.PostFilter) bool {
+		return f.After != nil && f.After.ID == 7 && f.After.CreatedAt.Equal(createdAt)
+	})).Return([]*entity.Post{{ID: 6, CreatedAt: createdAt.Add(-time.Hour)}}, nil).Once()
+
+	page, err = uc.GetAllPosts(context.Background(), entity.PostFilter{Limit: 1, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+	assert.Empty(t, page.NextCursor)
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestPostUseCase_GetAllPosts_CursorBoundToSort(t *testing.T) {
+	mockPostRepo := new(MockPostRepository)
+	uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+
+	mockPostRepo.On("GetAllPosts", mock.Anything, mock.MatchedBy(func(f entity.PostFilter) bool {
+		return f.Sort == entity.SortTop && f.After == nil
+	})).Return([]*entity.Post{{ID: 3, Score: 10}, {ID: 4, Score: 8}}, nil).Once()
+
+	page, err := uc.GetAllPosts(context.Background(), entity.PostFilter{Limit: 1, Sort: entity.SortTop})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.NextCursor)
+
+	// Курсор от сортировки top нельзя использовать для hot
+	_, err = uc.GetAllPosts(context.Background(), entity.PostFilter{Sort: entity.SortHot, Cursor: page.NextCursor})
+	assert.ErrorIs(t, err, usecase.ErrInvalidCursor)
+
+	mockPostRepo.On("GetAllPosts", mock.Anything, mock.MatchedBy(func(f entity.PostFilter) bool {
+		return f.After != nil && f.After.Score == 10 && f.After.ID == 3
+	})).Return([]*entity.Post{}, nil).Once()
+
+	_, err = uc.GetAllPosts(context.Background(), entity.PostFilter{Limit: 1, Sort: entity.SortTop, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	mockPostRepo.AssertExpectations(t)
+}
+
+func TestPostUseCase_VotePost(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+		mockPostRepo.On("VotePost", mock.Anything, 1, 2, -1).Return(&entity.VoteResult{Vote: -1, Score: -3}, nil)
+
+		result, err := uc.VotePost(context.Background(), 1, 2, -1)
+		require.NoError(t, err)
+		assert.Equal(t, -3, result.Score)
+		mockPostRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		uc := usecase.NewPostUseCase(new(MockPostRepository), new(MockUserRepository))
+
+		_, err := uc.VotePost(context.Background(), 1, 2, 2)
+		assert.ErrorIs(t, err, usecase.ErrInvalidVote)
+	})
+
+	t.Run("InvalidPostID", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+
+		_, err := uc.VotePost(context.Background(), 0, 2, 1)
+		assert.ErrorIs(t, err, usecase.ErrInvalidPostID)
+		mockPostRepo.AssertNotCalled(t, "VotePost", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+var ErrInvalidRevision = errors.New("invalid revision")
+
+// GetPostRevisions returns the edit history of a post, oldest first.
+func (s *PostService) GetPostRevisions(ctx context.Context, postID int) ([]*entity.PostRevision, error) {
+	revisions, err := s.postRepo.GetPostRevisions(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	// У каждого поста есть хотя бы первая ревизия, так что пустая история
+	// означает, что поста нет.
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
+	}
+	return revisions, nil
+}
+
+func (s *PostService) GetPostRevision(ctx context.Context, postID, revision int) (*entity.PostRevision, error) {
+	if revision <= 0 {
+		return nil, ErrInvalidRevision
+	}
+	return s.postRepo.GetPostRevision(ctx, postID, revision)
+}
+
+// DiffPostRevisions compares two revisions of a post line by line.
+func (s *PostService) DiffPostRevisions(ctx context.Context, postID, from, to int) (*entity.RevisionDiff, error) {
+	if from <= 0 || to <= 0 {
+		return nil, ErrInvalidRevision
+	}
+	older, err := s.postRepo.GetPostRevision(ctx, postID, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.postRepo.GetPostRevision(ctx, postID, to)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.RevisionDiff{
+		PostID:  postID,
+		From:    from,
+		To:      to,
+		Title:   diffLines(older.Title, newer.Title),
+		Content: diffLines(older.Content, newer.Content),
+	}, nil
+}
+
+// RestorePostRevision brings back the text of an old revision. The restore is
+// an edit like any other: it is recorded as a new revision, so history is
+// never rewritten.
+func (s *PostService) RestorePostRevision(ctx context.Context, postID, revision, userID int) error {
+	if revision <= 0 {
+		return ErrInvalidRevision
+	}
+	old, err := s.postRepo.GetPostRevision(ctx, postID, revision)
+	if err != nil {
+		return err
+	}
+	return s.UpdatePost(ctx, postID, userID, old.Title, old.Content)
+}
@@ -0,0 +1,117 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostUseCase_GetPostRevisions(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+		mockPostRepo.On("GetPostRevisions", mock.Anything, 1).Return([]*entity.PostRevision{
+			{PostID: 1, Revision: 1}, {PostID: 1, Revision: 2},
+		}, nil)
+
+		revisions, err := uc.GetPostRevisions(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 2)
+	})
+
+	t.Run("UnknownPost", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+		mockPostRepo.On("GetPostRevisions", mock.Anything, 42).Return(nil, nil)
+
+		_, err := uc.GetPostRevisions(context.Background(), 42)
+		assert.ErrorIs(t, err, usecase.ErrNotFound)
+	})
+}
+
+func TestPostUseCase_DiffPostRevisions(t *testing.T) {
+	mockPostRepo := new(MockPostRepository)
+	uc := usecase.NewPostUseCase(mockPostRepo, new(MockUserRepository))
+	mockPostRepo.On("GetPostRevision", mock.Anything, 1, 1).Return(&entity.PostRevision{
+		Revision: 1, Title: "Title", Content: "one\ntwo\nthree",
+	}, nil)
+	mockPostRepo.On("GetPostRevision", mock.Anything, 1, 2).Return(&entity.PostRevision{
+		Revision: 2, Title: "Title", Content: "one\n2\nthree\nfour",
+	}, nil)
+
+	diff, err := uc.DiffPostRevisions(context.Background(), 1, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.DiffLine{{Op: entity.DiffEqual, Text: "Title"}}, diff.Title)
+	assert.Equal(t, []entity.DiffLine{
+		{Op: entity.DiffEqual, Text: "one"},
+		{Op: entity.DiffDelete, Text: "two"},
+		{Op: entity.DiffInsert, Text: "2"},
+		{Op: entity.DiffEqual, Text: "three"},
+		{Op: entity.DiffInsert, Text: "four"},
+	}, diff.Content)
+
+	_, err = uc.DiffPostRevisions(context.Background(), 1, 0, 2)
+	assert.ErrorIs(t, err, usecase.ErrInvalidRevision)
+}
+
+func TestPostUseCase_UpdatePostKeepsUnsetFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		title, content  string
+		expectedTitle   string
+		expectedContent string
+	}{
+		{name: "Только заголовок", title: "New", expectedTitle: "New", expectedContent: "old text"},
+		{name: "Только текст", content: "new text", expectedTitle: "Old", expectedContent: "new text"},
+		{name: "Оба поля", title: "New", content: "new text", expectedTitle: "New", expectedContent: "new text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPostRepo := new(MockPostRepository)
+			mockUserRepo := new(MockUserRepository)
+			uc := usecase.NewPostUseCase(mockPostRepo, mockUserRepo)
+			mockPostRepo.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, UserID: 5, Title: "Old", Content: "old text"}, nil)
+			mockUserRepo.On("GetUserByID", mock.Anything, 5).Return(&entity.User{ID: 5, Role: "user"}, nil)
+			mockPostRepo.On("UpdatePost", mock.Anything, 1, 5, tt.expectedTitle, tt.expectedContent).Return(nil)
+
+			require.NoError(t, uc.UpdatePost(context.Background(), 1, 5, tt.title, tt.content))
+			mockPostRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostUseCase_RestorePostRevision(t *testing.T) {
+	t.Run("Owner", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, mockUserRepo)
+		mockPostRepo.On("GetPostRevision", mock.Anything, 1, 1).Return(&entity.PostRevision{
+			Revision: 1, Title: "Old", Content: "old text",
+		}, nil)
+		mockPostRepo.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, UserID: 5}, nil)
+		mockUserRepo.On("GetUserByID", mock.Anything, 5).Return(&entity.User{ID: 5, Role: "user"}, nil)
+		mockPostRepo.On("UpdatePost", mock.Anything, 1, 5, "Old", "old text").Return(nil)
+
+		err := uc.RestorePostRevision(context.Background(), 1, 1, 5)
+		require.NoError(t, err)
+		mockPostRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotOwner", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := usecase.NewPostUseCase(mockPostRepo, mockUserRepo)
+		mockPostRepo.On("GetPostRevision", mock.Anything, 1, 1).Return(&entity.PostRevision{Revision: 1}, nil)
+		mockPostRepo.On("GetPostByID", mock.Anything, 1).Return(&entity.Post{ID: 1, UserID: 5}, nil)
+		mockUserRepo.On("GetUserByID", mock.Anything, 6).Return(&entity.User{ID: 6, Role: "user"}, nil)
+
+		err := uc.RestorePostRevision(context.Background(), 1, 1, 6)
+		assert.Error(t, err)
+		mockPostRepo.AssertNotCalled(t, "UpdatePost", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
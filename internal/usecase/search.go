@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+const (
+	DefaultSearchPageSize = 20
+	MaxSearchPageSize     = 50
+	maxSearchQueryLength  = 200
+)
+
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+type SearchRepository interface {
+	Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error)
+}
+
+type SearchUseCaseInterface interface {
+	Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error)
+}
+
+type SearchUseCase struct {
+	repo SearchRepository
+}
+
+func NewSearchUseCase(repo SearchRepository) *SearchUseCase {
+	return &SearchUseCase{repo: repo}
+}
+
+// Search validates the query, applies paging defaults and returns one page
+// of ranked results. NextOffset is set only when more results exist.
+func (uc *SearchUseCase) Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error) {
+	query.Query = strings.TrimSpace(query.Query)
+	if query.Query == "" {
+		return nil, fmt.Errorf("%w: query cannot be empty", ErrInvalidSearchQuery)
+	}
+	if utf8.RuneCountInString(query.Query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%w: query is too long", ErrInvalidSearchQuery)
+	}
+	switch query.Type {
+	case "", entity.SearchTypePost, entity.SearchTypeComment:
+	default:
+		return nil, fmt.Errorf("%w: type must be post or comment", ErrInvalidSearchQuery)
+	}
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset cannot be negative", ErrInvalidSearchQuery)
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultSearchPageSize
+	}
+	if query.Limit > MaxSearchPageSize {
+		query.Limit = MaxSearchPageSize
+	}
+
+	pageSize := query.Limit
+	query.Limit = pageSize + 1
+	results, err := uc.repo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.SearchPage{Results: results}
+	if len(results) > pageSize {
+		page.Results = results[:pageSize]
+		page.NextOffset = query.Offset + pageSize
+	}
+	if page.Results == nil {
+		page.Results = []entity.SearchResult{}
+	}
+	return page, nil
+}
@@ -0,0 +1,111 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSearchRepository struct {
+	mock.Mock
+}
+
+func (m *MockSearchRepository) Search(ctx context.Context, query entity.SearchQuery) ([]entity.SearchResult, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.SearchResult), args.Error(1)
+}
+
+func TestSearchUseCase_Search(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          entity.SearchQuery
+		mockSetup      func(*MockSearchRepository)
+		expectedErr    string
+		expectedCount  int
+		expectedOffset int
+	}{
+		{
+			name:  "Success",
+			query: entity.SearchQuery{Query: "  golang  "},
+			mockSetup: func(r *MockSearchRepository) {
+				r.On("Search", mock.Anything, entity.SearchQuery{Query: "golang", Limit: usecase.DefaultSearchPageSize + 1}).
+					Return([]entity.SearchResult{{Type: entity.SearchTypePost, ID: 1}}, nil)
+			},
+			expectedCount: 1,
+		},
+		{
+			name:  "HasNextPage",
+			query: entity.SearchQuery{Query: "golang", Limit: 2, Offset: 4},
+			mockSetup: func(r *MockSearchRepository) {
+				r.On("Search", mock.Anything, entity.SearchQuery{Query: "golang", Limit: 3, Offset: 4}).
+					Return([]entity.SearchResult{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+			},
+			expectedCount:  2,
+			expectedOffset: 6,
+		},
+		{
+			name:  "LimitCapped",
+			query: entity.SearchQuery{Query: "golang", Type: entity.SearchTypeComment, Limit: 1000},
+			mockSetup: func(r *MockSearchRepository) {
+				r.On("Search", mock.Anything, entity.SearchQuery{Query: "golang", Type: entity.SearchTypeComment, Limit: usecase.MaxSearchPageSize + 1}).
+					Return(nil, nil)
+			},
+		},
+		{
+			name:        "EmptyQuery",
+			query:       entity.SearchQuery{Query: "   "},
+			mockSetup:   func(r *MockSearchRepository) {},
+			expectedErr: "query cannot be empty",
+		},
+		{
+			name:        "QueryTooLong",
+			query:       entity.SearchQuery{Query: strings.Repeat("a", 201)},
+			mockSetup:   func(r *MockSearchRepository) {},
+			expectedErr: "query is too long",
+		},
+		{
+			name:        "InvalidType",
+			query:       entity.SearchQuery{Query: "golang", Type: "user"},
+			mockSetup:   func(r *MockSearchRepository) {},
+			expectedErr: "type must be post or comment",
+		},
+		{
+			name:  "RepositoryError",
+			query: entity.SearchQuery{Query: "golang"},
+			mockSetup: func(r *MockSearchRepository) {
+				r.On("Search", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+			},
+			expectedErr: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockSearchRepository)
+			tt.mockSetup(repo)
+			uc := usecase.NewSearchUseCase(repo)
+
+			page, err := uc.Search(context.Background(), tt.query)
+
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Len(t, page.Results, tt.expectedCount)
+				assert.Equal(t, tt.expectedOffset, page.NextOffset)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
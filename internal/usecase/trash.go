@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+)
+
+const (
+	DefaultTrashPageSize = 50
+	MaxTrashPageSize     = 200
+)
+
+var (
+	ErrForbidden        = errors.New("forbidden: admin role required")
+	ErrInvalidTrashItem = errors.New("invalid trash item")
+)
+
+type TrashRepository interface {
+	GetDeletedItems(ctx context.Context, itemType string, limit, offset int) ([]entity.TrashItem, error)
+	RestorePost(ctx context.Context, id int) error
+	RestoreComment(ctx context.Context, id int) error
+	PurgePost(ctx context.Context, id int) error
+	PurgeComment(ctx context.Context, id int) error
+	PurgeDeletedOlderThan(ctx context.Context, age time.Duration) (int64, error)
+}
+
+type TrashUseCaseInterface interface {
+	ListTrash(ctx context.Context, adminID int, itemType string, limit, offset int) (*entity.TrashPage, error)
+	RestoreItem(ctx context.Context, adminID int, itemType string, id int) error
+	PurgeItem(ctx context.Context, adminID int, itemType string, id int) error
+}
+
+// TrashUseCase manages soft-deleted posts and comments. Every method except
+// the background purge is restricted to admins.
+type TrashUseCase struct {
+	repo      TrashRepository
+	userRepo  UserRepository
+	retention time.Duration
+}
+
+// NewTrashUseCase creates the use case. Items stay in the trash for retention;
+// a zero retention keeps them until an admin purges them by hand.
+func NewTrashUseCase(repo TrashRepository, userRepo UserRepository, retention time.Duration) *TrashUseCase {
+	return &TrashUseCase{repo: repo, userRepo: userRepo, retention: retention}
+}
+
+func (uc *TrashUseCase) ListTrash(ctx context.Context, adminID int, itemType string, limit, offset int) (*entity.TrashPage, error) {
+	if err := requireAdmin(ctx, uc.userRepo, adminID); err != nil {
+		return nil, err
+	}
+	switch itemType {
+	case "", entity.TrashTypePost, entity.TrashTypeComment:
+	default:
+		return nil, fmt.Errorf("%w: type must be post or comment", ErrInvalidTrashItem)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: offset cannot be negative", ErrInvalidTrashItem)
+	}
+	if limit <= 0 {
+		limit = DefaultTrashPageSize
+	}
+	if limit > MaxTrashPageSize {
+		limit = MaxTrashPageSize
+	}
+
+	items, err := uc.repo.GetDeletedItems(ctx, itemType, limit+1, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.TrashPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextOffset = offset + limit
+	}
+	if page.Items == nil {
+		page.Items = []entity.TrashItem{}
+	}
+	return page, nil
+}
+
+func (uc *TrashUseCase) RestoreItem(ctx context.Context, adminID int, itemType string, id int) error {
+	if err := requireAdmin(ctx, uc.userRepo, adminID); err != nil {
+		return err
+	}
+	switch itemType {
+	case entity.TrashTypePost:
+		return uc.repo.RestorePost(ctx, id)
+	case entity.TrashTypeComment:
+		return uc.repo.RestoreComment(ctx, id)
+	default:
+		return fmt.Errorf("%w: type must be post or comment", ErrInvalidTrashItem)
+	}
+}
+
+func (uc *TrashUseCase) PurgeItem(ctx context.Context, adminID int, itemType string, id int) error {
+	if err := requireAdmin(ctx, uc.userRepo, adminID); err != nil {
+		return err
+	}
+	switch itemType {
+	case entity.TrashTypePost:
+		return uc.repo.PurgePost(ctx, id)
+	case entity.TrashTypeComment:
+		return uc.repo.PurgeComment(ctx, id)
+	default:
+		return fmt.Errorf("%w: type must be post or comment", ErrInvalidTrashItem)
+	}
+}
+
+// PurgeExpired permanently removes items that have been in the trash longer
+// than the retention period. The repository compares the period with the
+// database clock, since deleted_at is set by it as well.
+func (uc *TrashUseCase) PurgeExpired(ctx context.Context) (int64, error) {
+	if uc.retention <= 0 {
+		return 0, nil
+	}
+	return uc.repo.PurgeDeletedOlderThan(ctx, uc.retention)
+}
+
+// StartPurgeRoutine runs PurgeExpired every interval until ctx is cancelled.
+func (uc *TrashUseCase) StartPurgeRoutine(ctx context.Context, interval time.Duration) {
+	if uc.retention <= 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purged, err := uc.PurgeExpired(ctx)
+				if err != nil {
+					log.Printf("Error purging trash: %v", err)
+					continue
+				}
+				if purged > 0 {
+					log.Printf("Purged %d items from trash", purged)
+				}
+			}
+		}
+	}()
+}
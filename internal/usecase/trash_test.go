@@ -0,0 +1,108 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTrashRepository struct {
+	mock.Mock
+}
+
+func (m *MockTrashRepository) GetDeletedItems(ctx context.Context, itemType string, limit, offset int) ([]entity.TrashItem, error) {
+	args := m.Called(ctx, itemType, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.TrashItem), args.Error(1)
+}
+
+func (m *MockTrashRepository) RestorePost(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockTrashRepository) RestoreComment(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockTrashRepository) PurgePost(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockTrashRepository) PurgeComment(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockTrashRepository) PurgeDeletedOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	args := m.Called(ctx, age)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestTrashUseCase_ListTrash(t *testing.T) {
+	t.Run("Admin", func(t *testing.T) {
+		mockRepo := new(MockTrashRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := usecase.NewTrashUseCase(mockRepo, mockUserRepo, time.Hour)
+		mockUserRepo.On("GetUserByID", mock.Anything, 1).Return(&entity.User{ID: 1, Role: "admin"}, nil)
+		mockRepo.On("GetDeletedItems", mock.Anything, "post", 3, 0).Return([]entity.TrashItem{
+			{Type: "post", ID: 3}, {Type: "post", ID: 2}, {Type: "post", ID: 1},
+		}, nil)
+
+		page, err := uc.ListTrash(context.Background(), 1, "post", 2, 0)
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, 2, page.NextOffset)
+	})
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		mockRepo := new(MockTrashRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := usecase.NewTrashUseCase(mockRepo, mockUserRepo, time.Hour)
+		mockUserRepo.On("GetUserByID", mock.Anything, 2).Return(&entity.User{ID: 2, Role: "user"}, nil)
+
+		_, err := uc.ListTrash(context.Background(), 2, "", 0, 0)
+		assert.ErrorIs(t, err, usecase.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "GetDeletedItems", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTrashUseCase_RestoreItem(t *testing.T) {
+	mockRepo := new(MockTrashRepository)
+	mockUserRepo := new(MockUserRepository)
+	uc := usecase.NewTrashUseCase(mockRepo, mockUserRepo, time.Hour)
+	mockUserRepo.On("GetUserByID", mock.Anything, 1).Return(&entity.User{ID: 1, Role: "admin"}, nil)
+	mockRepo.On("RestoreComment", mock.Anything, 5).Return(nil)
+
+	require.NoError(t, uc.RestoreItem(context.Background(), 1, "comment", 5))
+	assert.ErrorIs(t, uc.RestoreItem(context.Background(), 1, "user", 5), usecase.ErrInvalidTrashItem)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTrashUseCase_PurgeExpired(t *testing.T) {
+	t.Run("UsesRetention", func(t *testing.T) {
+		mockRepo := new(MockTrashRepository)
+		uc := usecase.NewTrashUseCase(mockRepo, new(MockUserRepository), 24*time.Hour)
+		mockRepo.On("PurgeDeletedOlderThan", mock.Anything, 24*time.Hour).Return(int64(4), nil)
+
+		purged, err := uc.PurgeExpired(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), purged)
+	})
+
+	t.Run("KeepForever", func(t *testing.T) {
+		mockRepo := new(MockTrashRepository)
+		uc := usecase.NewTrashUseCase(mockRepo, new(MockUserRepository), 0)
+
+		purged, err := uc.PurgeExpired(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+		mockRepo.AssertNotCalled(t, "PurgeDeletedOlderThan", mock.Anything, mock.Anything)
+	})
+}
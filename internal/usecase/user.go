@@ -7,6 +7,14 @@ import (
 	"github.com/perfect1337/forum-service/internal/repository"
 )
 
+// ErrUsersUnavailable means the user directory couldn't be reached and had
+// no cached copy of the user.
+var ErrUsersUnavailable = repository.ErrUserDirectoryUnavailable
+
+// PlaceholderAuthorName stands in for an author's name while the user
+// directory can't be reached and there is no local copy of the name.
+const PlaceholderAuthorName = "[unavailable]"
+
 type UserUseCase struct {
 	userRepo repository.UserRepository
 }
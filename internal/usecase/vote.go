@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/perfect1337/forum-service/internal/entity"
+	"github.com/perfect1337/forum-service/internal/repository"
+)
+
+var (
+	ErrInvalidVote = errors.New("vote value must be -1, 0 or 1")
+	ErrNotFound    = repository.ErrNotFound
+)
+
+func validateVote(value int) error {
+	if value < -1 || value > 1 {
+		return ErrInvalidVote
+	}
+	return nil
+}
+
+// hotRank mirrors the SQL ranking used for posts: log10 of the score plus
+// creation time, so that newer items outrank older ones with the same score.
+func hotRank(score int, createdAt time.Time) float64 {
+	order := math.Log10(math.Max(math.Abs(float64(score)), 1))
+	sign := 0.0
+	switch {
+	case score > 0:
+		sign = 1
+	case score < 0:
+		sign = -1
+	}
+	return sign*order + float64(createdAt.Unix())/45000
+}
+
+// sortComments orders sibling comments in place. The repository returns them
+// oldest first, which is also the default order.
+func sortComments(comments []*entity.Comment, order string) {
+	var less func(a, b *entity.Comment) bool
+	switch order {
+	case entity.SortNew:
+		less = func(a, b *entity.Comment) bool { return a.CreatedAt.After(b.CreatedAt) }
+	case entity.SortTop:
+		less = func(a, b *entity.Comment) bool { return a.Score > b.Score }
+	case entity.SortHot:
+		less = func(a, b *entity.Comment) bool {
+			return hotRank(a.Score, a.CreatedAt) > hotRank(b.Score, b.CreatedAt)
+		}
+	default:
+		return
+	}
+	sort.SliceStable(comments, func(i, j int) bool { return less(comments[i], comments[j]) })
+}
@@ -0,0 +1,3 @@
+-- Keyset pagination over the post feed: (created_at, id) in both directions.
+CREATE INDEX IF NOT EXISTS posts_created_at_id_idx ON posts (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS posts_user_id_created_at_idx ON posts (user_id, created_at DESC, id DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS comments_post_id_parent_id_idx ON comments (post_id, parent_id);
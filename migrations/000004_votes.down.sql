@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS posts_score_id_idx;
+ALTER TABLE comments DROP COLUMN IF EXISTS score;
+ALTER TABLE posts DROP COLUMN IF EXISTS score;
+DROP TABLE IF EXISTS comment_votes;
+DROP TABLE IF EXISTS post_votes;
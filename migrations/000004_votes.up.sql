@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS post_votes (
+    user_id    INTEGER  NOT NULL,
+    post_id    INTEGER  NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    value      SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE TABLE IF NOT EXISTS comment_votes (
+    user_id    INTEGER  NOT NULL,
+    comment_id INTEGER  NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    value      SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, comment_id)
+);
+
+-- Aggregated scores are kept on the rows themselves so listings can sort by them.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS posts_score_id_idx ON posts (score DESC, id DESC);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id         SERIAL PRIMARY KEY,
+    post_id    INTEGER   NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    revision   INTEGER   NOT NULL,
+    title      TEXT      NOT NULL,
+    content    TEXT      NOT NULL,
+    editor_id  INTEGER   NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (post_id, revision)
+);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+
+-- Existing posts get their current text as the first revision.
+INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at)
+SELECT id, 1, title, content, user_id, created_at FROM posts
+ON CONFLICT (post_id, revision) DO NOTHING;
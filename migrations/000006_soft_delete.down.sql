@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS comments_deleted_at_idx;
+DROP INDEX IF EXISTS posts_deleted_at_idx;
+
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_parent_id_fkey;
+ALTER TABLE comments ADD CONSTRAINT comments_parent_id_fkey
+    FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE;
+
+-- Soft-deleted rows would reappear without the columns, so drop them for real.
+DELETE FROM comments WHERE deleted_at IS NOT NULL
+    OR post_id IN (SELECT id FROM posts WHERE deleted_at IS NOT NULL);
+DELETE FROM posts WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE posts DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,14 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS deleted_by INTEGER;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_by INTEGER;
+
+-- Purging a deleted comment must not take its live replies with it; they are
+-- shown as top-level comments instead.
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_parent_id_fkey;
+ALTER TABLE comments ADD CONSTRAINT comments_parent_id_fkey
+    FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE SET NULL;
+
+-- The trash listing and the background purge only look at deleted rows.
+CREATE INDEX IF NOT EXISTS posts_deleted_at_idx ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS comments_deleted_at_idx ON comments (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS tags;
+DROP INDEX IF EXISTS posts_category_id_created_at_idx;
+ALTER TABLE posts DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS chat_messages_room_id_id_idx;
+ALTER TABLE chat_messages DROP COLUMN IF EXISTS room_id;
+DROP TABLE IF EXISTS chat_room_members;
+DROP TABLE IF EXISTS chat_rooms;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS chat_rooms (
+    id         SERIAL PRIMARY KEY,
+    name       TEXT      NOT NULL UNIQUE,
+    created_by INTEGER   NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Комната 1 — общий чат, в который попадают все старые сообщения.
+INSERT INTO chat_rooms (id, name) VALUES (1, 'general') ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('chat_rooms', 'id'), GREATEST((SELECT MAX(id) FROM chat_rooms), 1));
+
+CREATE TABLE IF NOT EXISTS chat_room_members (
+    room_id   INTEGER   NOT NULL REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    user_id   INTEGER   NOT NULL,
+    joined_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (room_id, user_id)
+);
+
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS room_id INTEGER NOT NULL DEFAULT 1
+    REFERENCES chat_rooms(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS chat_messages_room_id_id_idx ON chat_messages (room_id, id DESC);
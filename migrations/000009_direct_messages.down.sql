@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS direct_messages;
+DROP TABLE IF EXISTS dm_conversations;
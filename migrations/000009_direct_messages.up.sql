@@ -0,0 +1,28 @@
+-- Диалог между двумя пользователями хранится один раз: user_a < user_b.
+CREATE TABLE IF NOT EXISTS dm_conversations (
+    id              SERIAL PRIMARY KEY,
+    user_a          INTEGER   NOT NULL,
+    user_b          INTEGER   NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_message_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (user_a, user_b),
+    CHECK (user_a < user_b)
+);
+
+CREATE INDEX IF NOT EXISTS dm_conversations_user_a_idx ON dm_conversations (user_a, last_message_at DESC);
+CREATE INDEX IF NOT EXISTS dm_conversations_user_b_idx ON dm_conversations (user_b, last_message_at DESC);
+
+CREATE TABLE IF NOT EXISTS direct_messages (
+    id              SERIAL PRIMARY KEY,
+    conversation_id INTEGER   NOT NULL REFERENCES dm_conversations(id) ON DELETE CASCADE,
+    sender_id       INTEGER   NOT NULL,
+    recipient_id    INTEGER   NOT NULL,
+    author          TEXT      NOT NULL,
+    text            TEXT      NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at         TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS direct_messages_conversation_id_idx ON direct_messages (conversation_id, id DESC);
+CREATE INDEX IF NOT EXISTS direct_messages_unread_idx ON direct_messages (recipient_id, conversation_id)
+    WHERE read_at IS NULL;
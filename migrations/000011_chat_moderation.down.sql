@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS chat_messages_room_id_user_id_idx;
+ALTER TABLE chat_rooms DROP COLUMN IF EXISTS slow_mode_seconds;
+DROP TABLE IF EXISTS chat_sanctions;
@@ -0,0 +1,15 @@
+-- Одна санкция каждого вида на пользователя; повторная выдача её заменяет.
+CREATE TABLE IF NOT EXISTS chat_sanctions (
+    user_id    INTEGER   NOT NULL,
+    kind       TEXT      NOT NULL CHECK (kind IN ('mute', 'ban')),
+    reason     TEXT      NOT NULL DEFAULT '',
+    created_by INTEGER   NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP,
+    PRIMARY KEY (user_id, kind)
+);
+
+ALTER TABLE chat_rooms ADD COLUMN IF NOT EXISTS slow_mode_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- Медленный режим ищет последнее сообщение пользователя в комнате.
+CREATE INDEX IF NOT EXISTS chat_messages_room_id_user_id_idx ON chat_messages (room_id, user_id, created_at DESC);